@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"delivery/cmd"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"strconv"
+)
+
+const dlqUsage = "usage: delivery dlq replay [limit]"
+
+// runDlq выполняет подкоманду "delivery dlq replay [limit]": возвращает сообщения из DLQ в топик basket.confirmed
+func runDlq(configs cmd.Config, args []string) {
+	if len(args) < 1 || len(args) > 2 || args[0] != "replay" {
+		log.Fatal(dlqUsage)
+	}
+
+	limit := 0
+	if len(args) == 2 {
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value < 1 {
+			log.Fatal(dlqUsage)
+		}
+		limit = value
+	}
+
+	// Для переотправки БД не нужна
+	compositionRoot := cmd.NewCompositionRoot(configs, nil)
+	replayed, err := compositionRoot.NewBasketConfirmedDeadLetterReplayer().Replay(context.Background(), limit)
+	compositionRoot.CloseAll()
+	if err != nil {
+		log.Fatalf("Ошибка переотправки сообщений из DLQ (переотправлено %d): %v", replayed, err)
+	}
+	fmt.Printf("Переотправлено сообщений из DLQ: %d\n", replayed)
+}
@@ -1,25 +1,31 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"delivery/cmd"
 	httpin "delivery/internal/adapters/in/http"
-	"delivery/internal/adapters/out/postgres/courierrepo"
-	"delivery/internal/adapters/out/postgres/orderrepo"
+	"delivery/internal/adapters/out/postgres/migrations"
 	"delivery/internal/generated/servers"
 	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
+	"errors"
 	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	oam "github.com/oapi-codegen/echo-middleware"
 	"github.com/robfig/cron/v3"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -36,24 +42,76 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	crateDbIfNotExists(configs.DbHost,
-		configs.DbPort,
-		configs.DbUser,
-		configs.DbPassword,
-		configs.DbName,
-		configs.DbSslMode)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(configs, connectionString, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "dlq" {
+		runDlq(configs, os.Args[2:])
+		return
+	}
+
 	gormDb := mustGormOpen(connectionString)
-	mustAutoMigrate(gormDb)
+	mustCheckSchemaVersion(gormDb)
+
+	shutdownTimeout, err := configs.ShutdownDeadline()
+	if err != nil {
+		log.Fatalf("Некорректный таймаут остановки: %v", err)
+	}
 
 	compositionRoot := cmd.NewCompositionRoot(
 		configs,
 		gormDb,
 	)
-	defer compositionRoot.CloseAll()
+	registerDbPool(compositionRoot, gormDb)
+
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	// Ошибки, после которых сервис работать не может: сервис останавливается так же, как по сигналу
+	failures := make(chan error, 2)
 
 	startCron(compositionRoot)
-	startKafkaConsumer(compositionRoot)
-	startWebServer(compositionRoot, configs.HttpPort)
+	startKafkaConsumer(compositionRoot, failures)
+	startWebServer(compositionRoot, configs.HttpPort, failures)
+
+	var failure error
+	select {
+	case <-signals.Done():
+		log.Info("Получен сигнал остановки")
+	case failure = <-failures:
+		log.Errorf("Сервис останавливается из-за ошибки: %v", failure)
+	}
+
+	shutdown(compositionRoot, shutdownTimeout)
+	if failure != nil {
+		os.Exit(1)
+	}
+}
+
+// readinessGracePeriod - сколько отвечать "не готов" до остановки HTTP, чтобы балансировщик успел убрать под
+const readinessGracePeriod = 5 * time.Second
+
+// shutdown переводит сервис в неготовые и останавливает его: сначала HTTP, задания и консьюмеры Kafka,
+// затем клиенты внешних сервисов, пул БД и отправка трасс. Всё должно уложиться в timeout
+func shutdown(compositionRoot *cmd.CompositionRoot, timeout time.Duration) {
+	compositionRoot.MarkNotReady()
+	time.Sleep(readinessGracePeriod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := compositionRoot.Shutdown(ctx); err != nil {
+		log.Errorf("Сервис остановлен с ошибками: %v", err)
+		return
+	}
+	log.Info("Сервис остановлен")
+}
+
+func registerDbPool(compositionRoot *cmd.CompositionRoot, db *gorm.DB) {
+	sqlDb, err := db.DB()
+	if err != nil {
+		log.Fatalf("Ошибка получения соединения с БД: %v", err)
+	}
+	compositionRoot.RegisterCloser(cmd.ShutdownStorage, "postgres", sqlDb)
 }
 
 func getConfigs() cmd.Config {
@@ -66,10 +124,24 @@ func getConfigs() cmd.Config {
 		DbName:                    goDotEnvVariable("DB_NAME"),
 		DbSslMode:                 goDotEnvVariable("DB_SSLMODE"),
 		GeoServiceGrpcHost:        goDotEnvVariable("GEO_SERVICE_GRPC_HOST"),
+		GeoServiceTimeout:         goDotEnvVariable("GEO_SERVICE_TIMEOUT"),
+		GeoProvider:               goDotEnvVariable("GEO_PROVIDER"),
+		GeoGazetteerFile:          goDotEnvVariable("GEO_GAZETTEER_FILE"),
 		KafkaHost:                 goDotEnvVariable("KAFKA_HOST"),
 		KafkaConsumerGroup:        goDotEnvVariable("KAFKA_CONSUMER_GROUP"),
 		KafkaBasketConfirmedTopic: goDotEnvVariable("KAFKA_BASKET_CONFIRMED_TOPIC"),
 		KafkaOrderChangedTopic:    goDotEnvVariable("KAFKA_ORDER_CHANGED_TOPIC"),
+		KafkaBasketConfirmedDlq:   goDotEnvVariable("KAFKA_BASKET_CONFIRMED_DLQ_TOPIC"),
+		KafkaConsumerMaxAttempts:  goDotEnvVariable("KAFKA_CONSUMER_MAX_ATTEMPTS"),
+		KafkaConsumerRetryBackoff: goDotEnvVariable("KAFKA_CONSUMER_RETRY_BACKOFF"),
+		KafkaSchemaRegistryUrl:    goDotEnvVariable("KAFKA_SCHEMA_REGISTRY_URL"),
+		DeliveryTimezone:          goDotEnvVariable("DELIVERY_TIMEZONE"),
+		DispatchStrategy:          goDotEnvVariable("DISPATCH_STRATEGY"),
+		GridWidth:                 goDotEnvVariable("GRID_WIDTH"),
+		GridHeight:                goDotEnvVariable("GRID_HEIGHT"),
+		CityMapFile:               goDotEnvVariable("CITY_MAP_FILE"),
+		OtlpEndpoint:              goDotEnvVariable("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		ShutdownTimeout:           goDotEnvVariable("SHUTDOWN_TIMEOUT"),
 	}
 	return config
 }
@@ -95,9 +167,19 @@ func crateDbIfNotExists(host string, port string, user string,
 	defer db.Close()
 
 	// Создаём базу данных, если её нет
-	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbName))
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", dbName).Scan(&exists)
+	if err != nil {
+		log.Fatalf("Ошибка проверки наличия БД: %v", err)
+	}
+	if exists {
+		return
+	}
+
+	// Имя БД нельзя передать параметром, поэтому экранируем его как идентификатор
+	_, err = db.Exec("CREATE DATABASE " + pq.QuoteIdentifier(dbName))
 	if err != nil {
-		log.Printf("Ошибка создания БД (возможно, уже существует): %v", err)
+		log.Fatalf("Ошибка создания БД: %v", err)
 	}
 }
 
@@ -140,32 +222,42 @@ func mustGormOpen(connectionString string) *gorm.DB {
 	if err != nil {
 		log.Fatalf("connection to postgres through gorm\n: %s", err)
 	}
+	err = pgGorm.Use(tracing.NewGormPlugin())
+	if err != nil {
+		log.Fatalf("Ошибка подключения трассировки GORM: %v", err)
+	}
 	return pgGorm
 }
 
-func mustAutoMigrate(db *gorm.DB) {
-	err := db.AutoMigrate(&courierrepo.CourierDTO{})
+func mustCheckSchemaVersion(db *gorm.DB) {
+	sqlDb, err := db.DB()
 	if err != nil {
-		log.Fatalf("Ошибка миграции: %v", err)
+		log.Fatalf("Ошибка получения соединения с БД: %v", err)
 	}
-
-	err = db.AutoMigrate(&courierrepo.StoragePlaceDTO{})
+	migrator, err := migrations.NewMigrator(sqlDb)
 	if err != nil {
-		log.Fatalf("Ошибка миграции: %v", err)
+		log.Fatalf("Ошибка инициализации миграций: %v", err)
 	}
-
-	err = db.AutoMigrate(&orderrepo.OrderDTO{})
+	err = migrator.CheckVersion(context.Background())
 	if err != nil {
-		log.Fatalf("Ошибка миграции: %v", err)
+		log.Fatalf("Схема БД не соответствует приложению, выполните \"delivery migrate up\": %v", err)
 	}
 }
 
-func startWebServer(compositionRoot cmd.CompositionRoot, port string) {
+func startWebServer(compositionRoot *cmd.CompositionRoot, port string, failures chan<- error) {
 	handlers, err := httpin.NewServer(
 		compositionRoot.NewCreateOrderCommandHandler(),
+		compositionRoot.NewCancelOrderCommandHandler(),
 		compositionRoot.NewCreateCourierCommandHandler(),
+		compositionRoot.NewChangeCourierShiftCommandHandler(),
+		compositionRoot.NewAddStoragePlaceCommandHandler(),
+		compositionRoot.NewChangeStoragePlaceCommandHandler(),
+		compositionRoot.NewRemoveStoragePlaceCommandHandler(),
 		compositionRoot.NewGetAllCouriersQueryHandler(),
 		compositionRoot.NewGetNotCompletedOrdersQueryHandler(),
+		compositionRoot.NewGetOrderQueryHandler(),
+		compositionRoot.NewGetCourierQueryHandler(),
+		compositionRoot.NewGetAllTransportsQueryHandler(),
 	)
 	if err != nil {
 		log.Fatalf("Ошибка инициализации HTTP Server: %v", err)
@@ -177,16 +269,37 @@ func startWebServer(compositionRoot cmd.CompositionRoot, port string) {
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
 	}))
 
+	// Трассируются только запросы к API
+	e.Use(tracing.Middleware(skipNotApi))
+
 	spec, err := servers.GetSwagger()
 	if err != nil {
 		log.Fatalf("Error reading OpenAPI spec: %v", err)
 	}
-	e.Use(oam.OapiRequestValidator(spec))
+	// Проверяются только запросы к API: служебные страницы в спецификацию не входят
+	e.Use(oam.OapiRequestValidatorWithOptions(spec, &oam.Options{
+		Skipper: skipNotApi,
+	}))
 	e.Pre(middleware.RemoveTrailingSlash())
 	registerSwaggerOpenApi(e)
 	registerSwaggerUi(e)
+	e.GET("/metrics", echo.WrapHandler(compositionRoot.NewMetricsHandler()))
+	e.GET("/health/live", echo.WrapHandler(compositionRoot.NewLivenessHandler()))
+	e.GET("/health/ready", echo.WrapHandler(compositionRoot.NewReadinessHandler()))
 	servers.RegisterHandlers(e, handlers)
-	e.Logger.Fatal(e.Start(fmt.Sprintf("0.0.0.0:%s", port)))
+
+	compositionRoot.RegisterShutdowner(cmd.ShutdownInbound, "http server", e)
+	go func() {
+		// Shutdown дожидается обработки начатых запросов
+		err := e.Start(fmt.Sprintf("0.0.0.0:%s", port))
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			failures <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+}
+
+func skipNotApi(c echo.Context) bool {
+	return !strings.HasPrefix(c.Request().URL.Path, "/api/")
 }
 
 func registerSwaggerOpenApi(e *echo.Echo) {
@@ -232,8 +345,10 @@ func registerSwaggerUi(e *echo.Echo) {
 	})
 }
 
-func startCron(compositionRoot cmd.CompositionRoot) {
-	c := cron.New()
+func startCron(compositionRoot *cmd.CompositionRoot) {
+	// Запуск пропускается, пока не закончился предыдущий: иначе медленная отправка в Kafka позволила бы
+	// двум запускам outbox опубликовать одни и те же сообщения
+	c := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
 	_, err := c.AddJob("@every 1s", compositionRoot.NewAssignOrderJob())
 	if err != nil {
 		log.Fatalf("ошибка при добавлении задачи: %v", err)
@@ -242,13 +357,29 @@ func startCron(compositionRoot cmd.CompositionRoot) {
 	if err != nil {
 		log.Fatalf("ошибка при добавлении задачи: %v", err)
 	}
+	_, err = c.AddJob("@every 1s", compositionRoot.NewOutboxJob())
+	if err != nil {
+		log.Fatalf("ошибка при добавлении задачи: %v", err)
+	}
+	compositionRoot.StartLeaderElection()
 	c.Start()
+
+	// Stop отменяет будущие запуски, контекст завершается, когда закончатся уже начатые задания
+	compositionRoot.RegisterShutdowner(cmd.ShutdownInbound, "cron", cmd.ShutdownFunc(func(ctx context.Context) error {
+		select {
+		case <-c.Stop().Done():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
 }
 
-func startKafkaConsumer(compositionRoot cmd.CompositionRoot) {
+func startKafkaConsumer(compositionRoot *cmd.CompositionRoot, failures chan<- error) {
+	consumer := compositionRoot.NewBasketConfirmedConsumer()
 	go func() {
-		if err := compositionRoot.NewBasketConfirmedConsumer().Consume(); err != nil {
-			log.Fatalf("Kafka consumer error: %v", err)
+		if err := consumer.Consume(); err != nil {
+			failures <- fmt.Errorf("kafka consumer: %w", err)
 		}
 	}()
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"delivery/cmd"
+	"delivery/internal/adapters/out/postgres/migrations"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: delivery migrate up|down|status"
+
+// runMigrate выполняет подкоманду "delivery migrate up|down|status"
+func runMigrate(configs cmd.Config, connectionString string, args []string) {
+	if len(args) != 1 {
+		log.Fatal(migrateUsage)
+	}
+
+	if args[0] == "up" {
+		crateDbIfNotExists(configs.DbHost,
+			configs.DbPort,
+			configs.DbUser,
+			configs.DbPassword,
+			configs.DbName,
+			configs.DbSslMode)
+	}
+
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации миграций: %v", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "status":
+		err = printMigrationStatus(ctx, migrator)
+	default:
+		log.Fatal(migrateUsage)
+	}
+	if err != nil {
+		log.Fatalf("Ошибка миграции: %v", err)
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return w.Flush()
+}
@@ -1,19 +1,121 @@
 package cmd
 
-import "log"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
 
 type Closer interface {
 	Close() error
 }
 
-func (cr *CompositionRoot) RegisterCloser(c Closer) {
-	cr.closers = append(cr.closers, c)
+// Shutdowner - ресурс, который при остановке дожидается незавершённой работы, но не дольше дедлайна ctx
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
 }
 
-func (cr *CompositionRoot) CloseAll() {
-	for _, closer := range cr.closers {
-		if err := closer.Close(); err != nil {
-			log.Printf("error closing resource: %v", err)
+// ShutdownFunc позволяет зарегистрировать функцию остановки как Shutdowner
+type ShutdownFunc func(ctx context.Context) error
+
+func (f ShutdownFunc) Shutdown(ctx context.Context) error {
+	return f(ctx)
+}
+
+// ShutdownPhase - этап остановки. Этапы идут по порядку, ресурсы одного этапа останавливаются параллельно
+type ShutdownPhase int
+
+const (
+	// ShutdownInbound - всё, что приносит работу: HTTP сервер, планировщик заданий, консьюмеры Kafka
+	ShutdownInbound ShutdownPhase = iota
+	// ShutdownOutbound - клиенты внешних сервисов: продюсеры Kafka, соединение с Geo
+	ShutdownOutbound
+	// ShutdownStorage - пул соединений с БД
+	ShutdownStorage
+	// ShutdownTelemetry - отправка последних спанов, в том числе спанов самой остановки
+	ShutdownTelemetry
+)
+
+var shutdownPhases = []ShutdownPhase{ShutdownInbound, ShutdownOutbound, ShutdownStorage, ShutdownTelemetry}
+
+func (p ShutdownPhase) String() string {
+	switch p {
+	case ShutdownInbound:
+		return "inbound"
+	case ShutdownOutbound:
+		return "outbound"
+	case ShutdownStorage:
+		return "storage"
+	case ShutdownTelemetry:
+		return "telemetry"
+	default:
+		return fmt.Sprintf("phase %d", int(p))
+	}
+}
+
+type shutdowner struct {
+	phase ShutdownPhase
+	name  string
+	stop  Shutdowner
+}
+
+// RegisterCloser - Close не принимает контекст, поэтому по истечении дедлайна остановка его больше не ждёт
+func (cr *CompositionRoot) RegisterCloser(phase ShutdownPhase, name string, c Closer) {
+	cr.RegisterShutdowner(phase, name, ShutdownFunc(func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() { done <- c.Close() }()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+}
+
+func (cr *CompositionRoot) RegisterShutdowner(phase ShutdownPhase, name string, s Shutdowner) {
+	cr.closersMu.Lock()
+	defer cr.closersMu.Unlock()
+	cr.closers = append(cr.closers, shutdowner{phase: phase, name: name, stop: s})
+}
+
+// Shutdown останавливает зарегистрированные ресурсы по этапам. Ошибка одного ресурса не мешает
+// остановить остальные. После дедлайна ctx оставшиеся этапы всё равно запускаются, но их уже никто не ждёт
+func (cr *CompositionRoot) Shutdown(ctx context.Context) error {
+	cr.closersMu.Lock()
+	closers := cr.closers
+	cr.closers = nil
+	cr.closersMu.Unlock()
+
+	var errs []error
+	for _, phase := range shutdownPhases {
+		var (
+			wg sync.WaitGroup
+			mu sync.Mutex
+		)
+		for _, c := range closers {
+			if c.phase != phase {
+				continue
+			}
+			wg.Add(1)
+			go func(c shutdowner) {
+				defer wg.Done()
+				if err := c.stop.Shutdown(ctx); err != nil {
+					log.Printf("error shutting down %s (%s): %v", c.name, c.phase, err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+					mu.Unlock()
+				}
+			}(c)
 		}
+		wg.Wait()
 	}
+	return errors.Join(errs...)
+}
+
+// CloseAll останавливает ресурсы без дедлайна, например, после разовой подкоманды
+func (cr *CompositionRoot) CloseAll() {
+	_ = cr.Shutdown(context.Background())
 }
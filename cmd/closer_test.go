@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositionRoot_Shutdown(t *testing.T) {
+	t.Run("phases are stopped in order", func(t *testing.T) {
+		cr := &CompositionRoot{}
+		var (
+			mu      sync.Mutex
+			stopped []string
+		)
+		stop := func(name string) ShutdownFunc {
+			return func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				stopped = append(stopped, name)
+				return nil
+			}
+		}
+		cr.RegisterShutdowner(ShutdownTelemetry, "tracing", stop("tracing"))
+		cr.RegisterShutdowner(ShutdownStorage, "postgres", stop("postgres"))
+		cr.RegisterShutdowner(ShutdownOutbound, "producer", stop("producer"))
+		cr.RegisterShutdowner(ShutdownInbound, "http", stop("http"))
+
+		err := cr.Shutdown(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"http", "producer", "postgres", "tracing"}, stopped)
+	})
+
+	t.Run("error does not stop other resources", func(t *testing.T) {
+		cr := &CompositionRoot{}
+		closed := false
+		cr.RegisterShutdowner(ShutdownInbound, "http", ShutdownFunc(func(context.Context) error {
+			return errors.New("boom")
+		}))
+		cr.RegisterCloser(ShutdownStorage, "postgres", closerFunc(func() error {
+			closed = true
+			return nil
+		}))
+
+		err := cr.Shutdown(context.Background())
+
+		assert.ErrorContains(t, err, "http: boom")
+		assert.True(t, closed)
+	})
+
+	t.Run("slow closer is abandoned after deadline", func(t *testing.T) {
+		cr := &CompositionRoot{}
+		release := make(chan struct{})
+		defer close(release)
+		cr.RegisterCloser(ShutdownOutbound, "geo", closerFunc(func() error {
+			<-release
+			return nil
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := cr.Shutdown(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("resources are stopped once", func(t *testing.T) {
+		cr := &CompositionRoot{}
+		calls := 0
+		cr.RegisterCloser(ShutdownStorage, "postgres", closerFunc(func() error {
+			calls++
+			return nil
+		}))
+
+		_ = cr.Shutdown(context.Background())
+		cr.CloseAll()
+
+		assert.Equal(t, 1, calls)
+	})
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
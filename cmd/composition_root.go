@@ -1,42 +1,106 @@
 package cmd
 
 import (
+	"context"
 	"delivery/internal/adapters/in/jobs"
 	kafkain "delivery/internal/adapters/in/kafka"
+	"delivery/internal/adapters/out/citymap"
+	"delivery/internal/adapters/out/gazetteer"
 	"delivery/internal/adapters/out/grpc/geo"
+	kafkaout "delivery/internal/adapters/out/kafka"
+	"delivery/internal/adapters/out/metrics"
 	"delivery/internal/adapters/out/postgres/courierrepo"
+	"delivery/internal/adapters/out/postgres/leader"
 	"delivery/internal/adapters/out/postgres/orderrepo"
+	"delivery/internal/adapters/out/postgres/outbox"
 	"delivery/internal/adapters/out/postgres/shared"
+	"delivery/internal/adapters/out/postgres/transportrepo"
 	"delivery/internal/core/application/usecases/commands"
 	"delivery/internal/core/application/usecases/queries"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/domain/model/order"
 	"delivery/internal/core/domain/services"
 	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/health"
+	"delivery/internal/pkg/tracing"
+	"fmt"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
 )
 
 type CompositionRoot struct {
-	configs Config
-	gormDb  *gorm.DB
+	configs   Config
+	gormDb    *gorm.DB
+	grid      kernel.Grid
+	cityMap   kernel.CityMap
+	gazetteer ports.GeoLocationGateway
+	metrics   *metrics.Metrics
+	health    *health.Health
+	heartbeat *jobs.Heartbeat
+	leader    *leader.Elector
 
-	closers []Closer
+	closersMu sync.Mutex
+	closers   []shutdowner
 }
 
-func NewCompositionRoot(c Config, gormDb *gorm.DB) CompositionRoot {
-	app := CompositionRoot{
-		configs: c,
-		gormDb:  gormDb,
+// NewCompositionRoot - корень создаётся один раз и передаётся по указателю: в нём копится список ресурсов для остановки
+func NewCompositionRoot(c Config, gormDb *gorm.DB) *CompositionRoot {
+	grid := mustLoadGrid(c)
+	app := &CompositionRoot{
+		configs:   c,
+		gormDb:    gormDb,
+		grid:      grid,
+		cityMap:   mustLoadCityMap(c, grid),
+		gazetteer: mustLoadGazetteer(c, grid),
+		metrics:   mustCreateMetrics(gormDb),
+		health:    mustCreateHealth(gormDb),
+		heartbeat: mustCreateHeartbeat(),
+		leader:    mustCreateElector(gormDb),
 	}
+	app.health.Register("cron", app.heartbeat.CheckHealth)
+	if app.leader != nil {
+		app.health.RegisterInfo("role", app.role)
+		// Блокировка отпускается после того, как остановлены задания, но до закрытия пула БД
+		app.RegisterShutdowner(ShutdownOutbound, "leader election", app.leader)
+	}
+	app.useTracing()
 	return app
 }
 
+// useTracing отправляет трассы в коллектор из OTEL_EXPORTER_OTLP_ENDPOINT. Без адреса спаны не записываются,
+// но контекст трассы из HTTP заголовков и сообщений Kafka всё равно передаётся дальше
+func (cr *CompositionRoot) useTracing() {
+	if cr.configs.OtlpEndpoint == "" {
+		tracing.UsePropagator()
+		return
+	}
+
+	exporter, err := tracing.NewOtlpExporter(context.Background(), cr.configs.OtlpEndpoint)
+	if err != nil {
+		panic(err)
+	}
+	provider, err := tracing.NewTracerProvider(exporter)
+	if err != nil {
+		panic(err)
+	}
+	tracing.Use(provider)
+	cr.RegisterShutdowner(ShutdownTelemetry, "tracing", provider)
+}
+
 func (cr *CompositionRoot) NewAssignOrderJob() cron.Job {
 	handler := cr.NewAssignOrderCommandHandler()
 	job, err := jobs.NewAssignOrderJob(handler)
 	if err != nil {
 		panic(err)
 	}
-	return job
+	return cr.instrumentJob("assign_orders", job)
 }
 
 func (cr *CompositionRoot) NewMoveCouriersJob() cron.Job {
@@ -45,7 +109,68 @@ func (cr *CompositionRoot) NewMoveCouriersJob() cron.Job {
 	if err != nil {
 		panic(err)
 	}
-	return job
+	return cr.instrumentJob("move_couriers", job)
+}
+
+func (cr *CompositionRoot) NewOutboxJob() cron.Job {
+	job, err := jobs.NewOutboxJob(cr.newOutboxRepository(), cr.newEventRegistry(), cr.NewOrderProducer())
+	if err != nil {
+		panic(err)
+	}
+	return cr.instrumentJob("outbox", job)
+}
+
+// instrumentJob - задание выполняется только на лидере, а heartbeat отмечает запуски на каждой реплике
+func (cr *CompositionRoot) instrumentJob(name string, job cron.Job) cron.Job {
+	res, err := metrics.NewJob(name, job, cr.metrics)
+	if err != nil {
+		panic(err)
+	}
+	res, err = jobs.NewLeaderOnlyJob(res, cr.mustLeader())
+	if err != nil {
+		panic(err)
+	}
+	return cr.heartbeat.Wrap(res)
+}
+
+// StartLeaderElection - пока реплика не стала лидером, задания планировщика пропускаются
+func (cr *CompositionRoot) StartLeaderElection() {
+	cr.mustLeader().Start()
+}
+
+// mustLeader - выбор лидера создаётся только вместе с БД, без неё запускать задания нельзя
+func (cr *CompositionRoot) mustLeader() *leader.Elector {
+	if cr.leader == nil {
+		panic(errs.NewValueIsRequiredError("leader"))
+	}
+	return cr.leader
+}
+
+func (cr *CompositionRoot) role() string {
+	if cr.leader != nil && cr.leader.IsLeader() {
+		return "leader"
+	}
+	return "follower"
+}
+
+// NewLivenessHandler отвечает пробе живости
+func (cr *CompositionRoot) NewLivenessHandler() http.Handler {
+	return cr.health.LiveHandler()
+}
+
+// NewReadinessHandler проверяет БД, Kafka, Geo и планировщик заданий
+func (cr *CompositionRoot) NewReadinessHandler() http.Handler {
+	return cr.health.ReadyHandler()
+}
+
+// MarkNotReady переводит сервис в неготовые перед остановкой
+func (cr *CompositionRoot) MarkNotReady() {
+	cr.health.ShutDown()
+}
+
+// NewMetricsHandler отдаёт метрики для Prometheus
+func (cr *CompositionRoot) NewMetricsHandler() http.Handler {
+	return cr.metrics.Handler()
 }
 
 func (cr *CompositionRoot) NewAssignOrderCommandHandler() commands.AssignOrderCommandHandler {
@@ -55,7 +180,8 @@ func (cr *CompositionRoot) NewAssignOrderCommandHandler() commands.AssignOrderCo
 
 	handler, err := commands.NewAssignOrderCommandHandler(
 		txManager,
-		cr.newOrderDispatcher(),
+		cr.newBatchDispatcher(),
+		services.NewRoutePlanner(),
 		orderRepository,
 		courierRepository,
 	)
@@ -68,8 +194,45 @@ func (cr *CompositionRoot) NewAssignOrderCommandHandler() commands.AssignOrderCo
 func (cr *CompositionRoot) NewCreateCourierCommandHandler() commands.CreateCourierCommandHandler {
 	txManager := cr.newTxManager()
 	courierRepository := cr.newCourierRepository(txManager)
+	transportRepository := cr.newTransportRepository(txManager)
+
+	handler, err := commands.NewCreateCourierCommandHandler(txManager, courierRepository, transportRepository, cr.grid)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+func (cr *CompositionRoot) NewChangeCourierShiftCommandHandler() commands.ChangeCourierShiftCommandHandler {
+	txManager := cr.newTxManager()
+	handler, err := commands.NewChangeCourierShiftCommandHandler(txManager, cr.newCourierRepository(txManager))
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+func (cr *CompositionRoot) NewAddStoragePlaceCommandHandler() commands.AddStoragePlaceCommandHandler {
+	txManager := cr.newTxManager()
+	handler, err := commands.NewAddStoragePlaceCommandHandler(txManager, cr.newCourierRepository(txManager))
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+func (cr *CompositionRoot) NewChangeStoragePlaceCommandHandler() commands.ChangeStoragePlaceCommandHandler {
+	txManager := cr.newTxManager()
+	handler, err := commands.NewChangeStoragePlaceCommandHandler(txManager, cr.newCourierRepository(txManager))
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
 
-	handler, err := commands.NewCreateCourierCommandHandler(txManager, courierRepository)
+func (cr *CompositionRoot) NewRemoveStoragePlaceCommandHandler() commands.RemoveStoragePlaceCommandHandler {
+	txManager := cr.newTxManager()
+	handler, err := commands.NewRemoveStoragePlaceCommandHandler(txManager, cr.newCourierRepository(txManager))
 	if err != nil {
 		panic(err)
 	}
@@ -88,12 +251,24 @@ func (cr *CompositionRoot) NewCreateOrderCommandHandler() commands.CreateOrderCo
 	return handler
 }
 
+func (cr *CompositionRoot) NewCancelOrderCommandHandler() commands.CancelOrderCommandHandler {
+	txManager := cr.newTxManager()
+	orderRepository := cr.newOrderRepository(txManager)
+	courierRepository := cr.newCourierRepository(txManager)
+
+	handler, err := commands.NewCancelOrderCommandHandler(txManager, orderRepository, courierRepository)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
 func (cr *CompositionRoot) NewMoveCouriersCommandHandler() commands.MoveCouriersCommandHandler {
 	txManager := cr.newTxManager()
 	orderRepository := cr.newOrderRepository(txManager)
 	courierRepository := cr.newCourierRepository(txManager)
 
-	handler, err := commands.NewMoveCouriersCommandHandler(txManager, orderRepository, courierRepository)
+	handler, err := commands.NewMoveCouriersCommandHandler(txManager, orderRepository, courierRepository, cr.newPathfinder())
 	if err != nil {
 		panic(err)
 	}
@@ -116,6 +291,30 @@ func (cr *CompositionRoot) NewGetNotCompletedOrdersQueryHandler() queries.GetNot
 	return handler
 }
 
+func (cr *CompositionRoot) NewGetOrderQueryHandler() queries.GetOrderQueryHandler {
+	handler, err := queries.NewGetOrderQueryHandler(cr.gormDb)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+func (cr *CompositionRoot) NewGetCourierQueryHandler() queries.GetCourierQueryHandler {
+	handler, err := queries.NewGetCourierQueryHandler(cr.gormDb)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+func (cr *CompositionRoot) NewGetAllTransportsQueryHandler() queries.GetAllTransportsQueryHandler {
+	handler, err := queries.NewGetAllTransportsQueryHandler(cr.gormDb)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
 func (cr *CompositionRoot) newTxManager() shared.TxManager {
 	tx, err := shared.NewTxManager(cr.gormDb)
 	if err != nil {
@@ -124,12 +323,143 @@ func (cr *CompositionRoot) newTxManager() shared.TxManager {
 	return tx
 }
 
-func (cr *CompositionRoot) newOrderDispatcher() services.OrderDispatcher {
-	return services.NewOrderDispatcher()
+func (cr *CompositionRoot) newBatchDispatcher() services.BatchDispatcher {
+	switch cr.configs.DispatchStrategy {
+	case "", DispatchStrategyBatch:
+		dispatcher, err := services.NewBatchDispatcherWithNavigator(cr.newPathfinder())
+		if err != nil {
+			panic(err)
+		}
+		return dispatcher
+	case DispatchStrategyGreedy:
+		orderDispatcher, err := services.NewOrderDispatcherWithNavigator(cr.newPathfinder())
+		if err != nil {
+			panic(err)
+		}
+		dispatcher, err := services.NewGreedyBatchDispatcher(orderDispatcher)
+		if err != nil {
+			panic(err)
+		}
+		return dispatcher
+	default:
+		panic(fmt.Sprintf("unknown dispatch strategy %q", cr.configs.DispatchStrategy))
+	}
+}
+
+func (cr *CompositionRoot) newPathfinder() services.Pathfinder {
+	pathfinder, err := services.NewPathfinder(cr.cityMap)
+	if err != nil {
+		panic(err)
+	}
+	return pathfinder
+}
+
+// mustLoadGrid - границы карты города из GRID_WIDTH и GRID_HEIGHT, без них доска 10x10
+func mustLoadGrid(c Config) kernel.Grid {
+	grid, err := c.Grid()
+	if err != nil {
+		panic(err)
+	}
+	return grid
 }
 
+// mustLoadCityMap читает непроходимые клетки из CITY_MAP_FILE, без файла город считается открытым
+func mustLoadCityMap(c Config, grid kernel.Grid) kernel.CityMap {
+	if c.CityMapFile == "" {
+		cityMap, err := kernel.NewCityMap(grid, nil)
+		if err != nil {
+			panic(err)
+		}
+		return cityMap
+	}
+
+	cityMap, err := citymap.Load(c.CityMapFile, grid)
+	if err != nil {
+		panic(err)
+	}
+	return cityMap
+}
+
+// mustLoadGazetteer читает справочник адресов из GEO_GAZETTEER_FILE, без файла возвращает nil
+func mustLoadGazetteer(c Config, grid kernel.Grid) ports.GeoLocationGateway {
+	if c.GeoGazetteerFile == "" {
+		return nil
+	}
+	res, err := gazetteer.Load(c.GeoGazetteerFile, grid)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// heartbeatMaxAge - задания запускаются каждую секунду, так что пауза дольше означает остановку планировщика
+const heartbeatMaxAge = 10 * time.Second
+
+func mustCreateHeartbeat() *jobs.Heartbeat {
+	res, err := jobs.NewHeartbeat(heartbeatMaxAge)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// leaderElectionInterval - как часто реплики пытаются стать лидером, а лидер проверяет своё соединение
+const leaderElectionInterval = 2 * time.Second
+
+// mustCreateElector - подкомандам без БД (dlq) выбор лидера не нужен
+func mustCreateElector(gormDb *gorm.DB) *leader.Elector {
+	if gormDb == nil {
+		return nil
+	}
+	sqlDb, err := gormDb.DB()
+	if err != nil {
+		panic(err)
+	}
+	res, err := leader.NewElector(sqlDb, leader.DefaultLockKey, leaderElectionInterval)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func mustCreateHealth(gormDb *gorm.DB) *health.Health {
+	res, err := health.NewHealth(health.DefaultCheckTimeout)
+	if err != nil {
+		panic(err)
+	}
+	res.Register("postgres", func(ctx context.Context) error {
+		sqlDb, err := gormDb.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDb.PingContext(ctx)
+	})
+	return res
+}
+
+// mustCreateMetrics - подкомандам без БД (dlq) метрики не нужны
+func mustCreateMetrics(gormDb *gorm.DB) *metrics.Metrics {
+	if gormDb == nil {
+		return nil
+	}
+	res, err := metrics.NewMetrics(gormDb)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// newOrderRepository - время назначения и доставки попадает в метрики, как только переход зафиксирован
 func (cr *CompositionRoot) newOrderRepository(txManager shared.TxManager) ports.OrderRepository {
-	res, err := orderrepo.NewOrderRepository(txManager)
+	if cr.metrics == nil {
+		res, err := orderrepo.NewOrderRepository(txManager)
+		if err != nil {
+			panic(err)
+		}
+		return res
+	}
+
+	res, err := orderrepo.NewOrderRepositoryWithObserver(txManager, cr.metrics)
 	if err != nil {
 		panic(err)
 	}
@@ -144,25 +474,167 @@ func (cr *CompositionRoot) newCourierRepository(txManager shared.TxManager) port
 	return res
 }
 
+func (cr *CompositionRoot) newTransportRepository(txManager shared.TxManager) ports.TransportRepository {
+	res, err := transportrepo.NewTransportRepository(txManager)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (cr *CompositionRoot) newOutboxRepository() outbox.Repository {
+	res, err := outbox.NewRepository(cr.gormDb)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (cr *CompositionRoot) newEventRegistry() outbox.EventRegistry {
+	registry := outbox.NewEventRegistry()
+	err := registry.RegisterDomainEvent(order.StatusChangedDomainEventName,
+		reflect.TypeOf(order.StatusChangedDomainEvent{}))
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// NewGeoClient выбирает источник координат по GEO_PROVIDER. Если задан справочник адресов,
+// он подменяет сервис Geo, пока размыкатель открыт
 func (cr *CompositionRoot) NewGeoClient() ports.GeoLocationGateway {
-	client, err := geo.NewGeoLocationService(cr.configs.GeoServiceGrpcHost)
+	switch cr.configs.GeoProvider {
+	case "", GeoProviderGrpc:
+		client := cr.newGrpcGeoClient()
+		if cr.gazetteer == nil {
+			return client
+		}
+		gateway, err := geo.NewFallbackGateway(client, cr.instrumentGeo(GeoProviderLocal, cr.gazetteer))
+		if err != nil {
+			panic(err)
+		}
+		return gateway
+	case GeoProviderLocal:
+		if cr.gazetteer == nil {
+			panic("GEO_GAZETTEER_FILE is required for local geo provider")
+		}
+		return cr.instrumentGeo(GeoProviderLocal, cr.gazetteer)
+	default:
+		panic(fmt.Sprintf("unknown geo provider %q", cr.configs.GeoProvider))
+	}
+}
+
+func (cr *CompositionRoot) newGrpcGeoClient() ports.GeoLocationGateway {
+	timeout, err := cr.configs.GeoTimeout()
+	if err != nil {
+		panic(err)
+	}
+	client, err := geo.NewGeoLocationService(cr.configs.GeoServiceGrpcHost, timeout, cr.grid,
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	if err != nil {
+		panic(err)
+	}
+	cr.RegisterCloser(ShutdownOutbound, "geo client", client)
+	// Пока есть справочник адресов, заказы создаются и без Geo
+	if cr.gazetteer == nil {
+		cr.health.Register("geo", client.CheckHealth)
+	} else {
+		cr.health.RegisterOptional("geo", client.CheckHealth)
+	}
+
+	// Замеряется каждая попытка, включая повторы
+	gateway, err := geo.NewResilientGateway(cr.instrumentGeo(GeoProviderGrpc, client), geo.DefaultResilienceConfig())
 	if err != nil {
 		panic(err)
 	}
-	cr.RegisterCloser(client)
-	return client
+	return gateway
+}
+
+func (cr *CompositionRoot) instrumentGeo(provider string, gateway ports.GeoLocationGateway) ports.GeoLocationGateway {
+	res, err := metrics.NewGeoGateway(gateway, provider, cr.metrics)
+	if err != nil {
+		panic(err)
+	}
+	return res
 }
 
 func (cr *CompositionRoot) NewBasketConfirmedConsumer() kafkain.BasketConfirmedConsumer {
+	retryPolicy, err := cr.configs.ConsumerRetryPolicy()
+	if err != nil {
+		panic(err)
+	}
+	deliveryZone, err := cr.configs.DeliveryZone()
+	if err != nil {
+		panic(err)
+	}
+
 	consumer, err := kafkain.NewBasketConfirmedConsumer(
 		[]string{cr.configs.KafkaHost},
 		cr.configs.KafkaConsumerGroup,
 		cr.configs.KafkaBasketConfirmedTopic,
 		cr.NewCreateOrderCommandHandler(),
+		cr.newBasketConfirmedDeadLetterProducer(),
+		retryPolicy,
+		cr.newSchemaRegistry(),
+		cr.metrics,
+		deliveryZone,
 	)
 	if err != nil {
 		panic(err)
 	}
-	cr.RegisterCloser(consumer)
+	// Close фиксирует смещения и выходит из группы
+	cr.RegisterCloser(ShutdownInbound, "basket confirmed consumer", consumer)
+	cr.health.Register("kafka", consumer.CheckHealth)
 	return consumer
 }
+
+// newSchemaRegistry возвращает nil, если KAFKA_SCHEMA_REGISTRY_URL не задан
+func (cr *CompositionRoot) newSchemaRegistry() kafkain.SchemaRegistry {
+	if cr.configs.KafkaSchemaRegistryUrl == "" {
+		return nil
+	}
+	registry, err := kafkain.NewSchemaRegistryClient(cr.configs.KafkaSchemaRegistryUrl)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+func (cr *CompositionRoot) newBasketConfirmedDeadLetterProducer() kafkain.DeadLetterProducer {
+	producer, err := kafkain.NewDeadLetterProducer(
+		[]string{cr.configs.KafkaHost},
+		cr.configs.KafkaBasketConfirmedDlq,
+	)
+	if err != nil {
+		panic(err)
+	}
+	cr.RegisterCloser(ShutdownOutbound, "dead letter producer", producer)
+	return producer
+}
+
+// NewBasketConfirmedDeadLetterReplayer - позиция в DLQ хранится в группе "<KAFKA_CONSUMER_GROUP>-dlq-replay"
+func (cr *CompositionRoot) NewBasketConfirmedDeadLetterReplayer() kafkain.DeadLetterReplayer {
+	replayer, err := kafkain.NewDeadLetterReplayer(
+		[]string{cr.configs.KafkaHost},
+		cr.configs.KafkaConsumerGroup+"-dlq-replay",
+		cr.configs.KafkaBasketConfirmedDlq,
+		cr.configs.KafkaBasketConfirmedTopic,
+	)
+	if err != nil {
+		panic(err)
+	}
+	cr.RegisterCloser(ShutdownInbound, "dead letter replayer", replayer)
+	return replayer
+}
+
+func (cr *CompositionRoot) NewOrderProducer() ports.OrderProducer {
+	producer, err := kafkaout.NewOrderProducer(
+		[]string{cr.configs.KafkaHost},
+		cr.configs.KafkaOrderChangedTopic,
+	)
+	if err != nil {
+		panic(err)
+	}
+	cr.RegisterCloser(ShutdownOutbound, "order producer", producer)
+	return producer
+}
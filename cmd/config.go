@@ -1,5 +1,30 @@
 package cmd
 
+import (
+	kafkain "delivery/internal/adapters/in/kafka"
+	"delivery/internal/adapters/out/grpc/geo"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"strconv"
+	"time"
+)
+
+// Стратегии назначения заказов курьерам
+const (
+	DispatchStrategyBatch  = "batch"
+	DispatchStrategyGreedy = "greedy"
+)
+
+// DefaultShutdownTimeout укладывается в стандартные 30 секунд terminationGracePeriodSeconds вместе с паузой
+// на снятие пода с балансировки
+const DefaultShutdownTimeout = 20 * time.Second
+
+// Источники координат для заказов
+const (
+	GeoProviderGrpc  = "grpc"
+	GeoProviderLocal = "local"
+)
+
 type Config struct {
 	HttpPort                  string
 	DbHost                    string
@@ -9,8 +34,103 @@ type Config struct {
 	DbName                    string
 	DbSslMode                 string
 	GeoServiceGrpcHost        string
+	GeoServiceTimeout         string
+	GeoProvider               string
+	GeoGazetteerFile          string
 	KafkaHost                 string
 	KafkaConsumerGroup        string
 	KafkaBasketConfirmedTopic string
 	KafkaOrderChangedTopic    string
+	KafkaBasketConfirmedDlq   string
+	KafkaConsumerMaxAttempts  string
+	KafkaConsumerRetryBackoff string
+	KafkaSchemaRegistryUrl    string
+	DeliveryTimezone          string
+	DispatchStrategy          string
+	GridWidth                 string
+	GridHeight                string
+	CityMapFile               string
+	OtlpEndpoint              string
+	ShutdownTimeout           string
+}
+
+// Grid возвращает карту города из GRID_WIDTH и GRID_HEIGHT. Если размеры не заданы, используется доска 10x10
+func (c Config) Grid() (kernel.Grid, error) {
+	if c.GridWidth == "" && c.GridHeight == "" {
+		return kernel.DefaultGrid(), nil
+	}
+
+	width, err := strconv.Atoi(c.GridWidth)
+	if err != nil {
+		return kernel.Grid{}, errs.NewValueIsInvalidErrorWithCause("GridWidth", err)
+	}
+	height, err := strconv.Atoi(c.GridHeight)
+	if err != nil {
+		return kernel.Grid{}, errs.NewValueIsInvalidErrorWithCause("GridHeight", err)
+	}
+	return kernel.NewGrid(width, height)
+}
+
+// ConsumerRetryPolicy возвращает политику повторов консьюмера из KAFKA_CONSUMER_MAX_ATTEMPTS
+// и KAFKA_CONSUMER_RETRY_BACKOFF. Незаданные значения берутся по умолчанию
+func (c Config) ConsumerRetryPolicy() (kafkain.RetryPolicy, error) {
+	maxAttempts := kafkain.DefaultMaxAttempts
+	if c.KafkaConsumerMaxAttempts != "" {
+		value, err := strconv.Atoi(c.KafkaConsumerMaxAttempts)
+		if err != nil {
+			return kafkain.RetryPolicy{}, errs.NewValueIsInvalidErrorWithCause("KafkaConsumerMaxAttempts", err)
+		}
+		maxAttempts = value
+	}
+
+	backoff := kafkain.DefaultInitialBackoff
+	if c.KafkaConsumerRetryBackoff != "" {
+		value, err := time.ParseDuration(c.KafkaConsumerRetryBackoff)
+		if err != nil {
+			return kafkain.RetryPolicy{}, errs.NewValueIsInvalidErrorWithCause("KafkaConsumerRetryBackoff", err)
+		}
+		backoff = value
+	}
+
+	return kafkain.NewRetryPolicy(maxAttempts, backoff, max(backoff, kafkain.DefaultMaxBackoff))
+}
+
+// DeliveryZone возвращает из DELIVERY_TIMEZONE часовой пояс магазина, в котором заданы часы окна доставки корзины.
+// Если пояс не задан, часы считаются в UTC
+func (c Config) DeliveryZone() (*time.Location, error) {
+	if c.DeliveryTimezone == "" {
+		return time.UTC, nil
+	}
+	zone, err := time.LoadLocation(c.DeliveryTimezone)
+	if err != nil {
+		return nil, errs.NewValueIsInvalidErrorWithCause("DeliveryTimezone", err)
+	}
+	return zone, nil
+}
+
+// GeoTimeout возвращает таймаут одного вызова сервиса Geo из GEO_SERVICE_TIMEOUT
+func (c Config) GeoTimeout() (time.Duration, error) {
+	if c.GeoServiceTimeout == "" {
+		return geo.DefaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.GeoServiceTimeout)
+	if err != nil {
+		return 0, errs.NewValueIsInvalidErrorWithCause("GeoServiceTimeout", err)
+	}
+	return timeout, nil
+}
+
+// ShutdownDeadline возвращает из SHUTDOWN_TIMEOUT, сколько ждать остановки сервиса после сигнала
+func (c Config) ShutdownDeadline() (time.Duration, error) {
+	if c.ShutdownTimeout == "" {
+		return DefaultShutdownTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.ShutdownTimeout)
+	if err != nil {
+		return 0, errs.NewValueIsInvalidErrorWithCause("ShutdownTimeout", err)
+	}
+	if timeout <= 0 {
+		return 0, errs.NewValueIsOutOfRangeError("ShutdownTimeout", timeout, "1ns", nil)
+	}
+	return timeout, nil
 }
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"delivery/internal/adapters/in/http/problems"
+	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/generated/servers"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"net/http"
+)
+
+func (s *Server) CancelOrder(c echo.Context, orderId openapi_types.UUID) error {
+	traceOrder(c, orderId)
+	var request servers.CancelOrderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest("invalid JSON body: "+err.Error()))
+	}
+
+	cancelOrderCommand, err := commands.NewCancelOrderCmd(orderId, request.Reason)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	err = s.cancelOrderCommandHandler.Handle(c.Request().Context(), cancelOrderCommand)
+	if err != nil {
+		if errors.Is(err, errs.ErrObjectNotFound) {
+			return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
+		}
+		if errors.Is(err, order.ErrOrderHasAlreadyBeenCompleted) ||
+			errors.Is(err, order.ErrOrderHasAlreadyBeenCancelled) {
+			return c.JSON(http.StatusConflict, problems.NewConflict("order-status-conflict", err.Error()))
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
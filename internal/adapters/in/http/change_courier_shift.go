@@ -0,0 +1,52 @@
+package http
+
+import (
+	"delivery/internal/adapters/in/http/problems"
+	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"net/http"
+)
+
+func (s *Server) StartCourierShift(c echo.Context, courierId openapi_types.UUID) error {
+	return s.changeCourierShift(c, courierId, commands.ShiftActionStartShift)
+}
+
+func (s *Server) EndCourierShift(c echo.Context, courierId openapi_types.UUID) error {
+	return s.changeCourierShift(c, courierId, commands.ShiftActionEndShift)
+}
+
+func (s *Server) StartCourierBreak(c echo.Context, courierId openapi_types.UUID) error {
+	return s.changeCourierShift(c, courierId, commands.ShiftActionStartBreak)
+}
+
+func (s *Server) EndCourierBreak(c echo.Context, courierId openapi_types.UUID) error {
+	return s.changeCourierShift(c, courierId, commands.ShiftActionEndBreak)
+}
+
+func (s *Server) changeCourierShift(c echo.Context, courierId openapi_types.UUID, action commands.ShiftAction) error {
+	changeShiftCommand, err := commands.NewChangeCourierShiftCmd(courierId, action)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	err = s.changeCourierShiftCommandHandler.Handle(c.Request().Context(), changeShiftCommand)
+	if err != nil {
+		if errors.Is(err, errs.ErrObjectNotFound) {
+			return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
+		}
+		if errors.Is(err, courier.ErrShiftAlreadyStarted) ||
+			errors.Is(err, courier.ErrShiftIsNotStarted) ||
+			errors.Is(err, courier.ErrCourierIsNotOnDuty) ||
+			errors.Is(err, courier.ErrCourierIsNotOnBreak) ||
+			errors.Is(err, courier.ErrCourierHoldsOrders) {
+			return c.JSON(http.StatusConflict, problems.NewConflict("courier-status-conflict", err.Error()))
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
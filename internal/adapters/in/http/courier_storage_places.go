@@ -0,0 +1,80 @@
+package http
+
+import (
+	"delivery/internal/adapters/in/http/problems"
+	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/generated/servers"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"net/http"
+)
+
+func (s *Server) AddCourierStoragePlace(c echo.Context, courierId openapi_types.UUID) error {
+	var storagePlace servers.NewStoragePlace
+	if err := c.Bind(&storagePlace); err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest("invalid JSON body: "+err.Error()))
+	}
+
+	cmd, err := commands.NewAddStoragePlaceCmd(courierId, storagePlace.Name, storagePlace.TotalVolume)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	err = s.addStoragePlaceCommandHandler.Handle(c.Request().Context(), cmd)
+	if err != nil {
+		return storagePlaceErrorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+func (s *Server) ChangeCourierStoragePlace(c echo.Context, courierId openapi_types.UUID,
+	storagePlaceId openapi_types.UUID) error {
+	var changes servers.StoragePlaceChanges
+	if err := c.Bind(&changes); err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest("invalid JSON body: "+err.Error()))
+	}
+
+	cmd, err := commands.NewChangeStoragePlaceCmd(courierId, storagePlaceId, changes.Name, changes.TotalVolume)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	err = s.changeStoragePlaceCommandHandler.Handle(c.Request().Context(), cmd)
+	if err != nil {
+		return storagePlaceErrorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (s *Server) RemoveCourierStoragePlace(c echo.Context, courierId openapi_types.UUID,
+	storagePlaceId openapi_types.UUID) error {
+	cmd, err := commands.NewRemoveStoragePlaceCmd(courierId, storagePlaceId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	err = s.removeStoragePlaceCommandHandler.Handle(c.Request().Context(), cmd)
+	if err != nil {
+		return storagePlaceErrorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func storagePlaceErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errs.ErrObjectNotFound) || errors.Is(err, courier.ErrStoragePlaceNotFound) {
+		return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
+	}
+	if errors.Is(err, courier.ErrStoragePlaceIsOccupied) {
+		return c.JSON(http.StatusConflict, problems.NewConflict("storage-place-occupied", err.Error()))
+	}
+	if errors.Is(err, errs.ErrValueIsRequired) {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+	return err
+}
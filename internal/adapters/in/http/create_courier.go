@@ -3,6 +3,7 @@ package http
 import (
 	"delivery/internal/adapters/in/http/problems"
 	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/core/domain/model/courier"
 	"delivery/internal/generated/servers"
 	"delivery/internal/pkg/errs"
 	"errors"
@@ -11,23 +12,30 @@ import (
 )
 
 func (s *Server) CreateCourier(c echo.Context) error {
-	var courier servers.NewCourier
-	if err := c.Bind(&courier); err != nil {
-		return problems.NewBadRequest("invalid JSON body: " + err.Error())
+	var newCourier servers.NewCourier
+	if err := c.Bind(&newCourier); err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest("invalid JSON body: "+err.Error()))
 	}
 
-	createCourierCommand, err := commands.NewCreateCourierCmd(courier.Name, courier.Speed)
+	createCourierCommand, err := commands.NewCreateCourierCmd(newCourier.Name, newCourier.TransportId)
 	if err != nil {
-		return problems.NewBadRequest(err.Error())
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+	// Без статуса курьер создаётся на смене, как и раньше
+	if newCourier.Status != nil {
+		createCourierCommand, err = createCourierCommand.WithStatus(courier.Status(*newCourier.Status))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+		}
 	}
 
 	err = s.createCourierCommandHandler.Handle(c.Request().Context(), createCourierCommand)
 	if err != nil {
 		if errors.Is(err, errs.ErrObjectNotFound) {
-			return problems.NewNotFound(err.Error())
+			return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
 		}
-		return problems.NewConflict(err.Error(), "/")
+		return c.JSON(http.StatusConflict, problems.NewConflict(err.Error(), "/"))
 	}
 
-	return c.JSON(http.StatusOK, nil)
+	return c.NoContent(http.StatusCreated)
 }
@@ -3,26 +3,59 @@ package http
 import (
 	"delivery/internal/adapters/in/http/problems"
 	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/generated/servers"
 	"delivery/internal/pkg/errs"
 	"errors"
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
 
 func (s *Server) CreateOrder(c echo.Context) error {
-	createOrderCommand, err := commands.NewCreateOrderCmd(uuid.New(), "Street", 5)
+	var order servers.NewOrder
+	if err := c.Bind(&order); err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest("invalid JSON body: "+err.Error()))
+	}
+
+	address, err := mapToAddress(order)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	createOrderCommand, err := commands.NewCreateOrderCmd(order.OrderId, address, order.Volume)
 	if err != nil {
-		return problems.NewBadRequest(err.Error())
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
 	}
 
+	traceOrder(c, createOrderCommand.OrderID())
 	err = s.createOrderCommandHandler.Handle(c.Request().Context(), createOrderCommand)
 	if err != nil {
+		if errors.Is(err, errs.ErrValueIsRequired) || errors.Is(err, errs.ErrValueIsOutOfRange) ||
+			errors.Is(err, errs.ErrValueIsInvalid) {
+			return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+		}
 		if errors.Is(err, errs.ErrObjectNotFound) {
-			return problems.NewNotFound(err.Error())
+			return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
 		}
-		return problems.NewConflict(err.Error(), "/")
+		return err
 	}
 
-	return c.JSON(http.StatusOK, nil)
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/orders/"+createOrderCommand.OrderID().String())
+	return c.NoContent(http.StatusCreated)
+}
+
+// mapToAddress берёт полный адрес, а для старых клиентов - только улицу из устаревшего поля street
+func mapToAddress(order servers.NewOrder) (kernel.Address, error) {
+	if order.Address != nil {
+		return kernel.NewAddress(valueOrEmpty(order.Address.Country), valueOrEmpty(order.Address.City),
+			order.Address.Street, valueOrEmpty(order.Address.House), valueOrEmpty(order.Address.Apartment))
+	}
+	return kernel.NewAddress("", "", valueOrEmpty(order.Street), "", "")
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
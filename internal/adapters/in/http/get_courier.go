@@ -0,0 +1,60 @@
+package http
+
+import (
+	"delivery/internal/adapters/in/http/problems"
+	"delivery/internal/core/application/usecases/queries"
+	"delivery/internal/generated/servers"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"net/http"
+)
+
+func (s *Server) GetCourier(c echo.Context, courierId openapi_types.UUID) error {
+	query, err := queries.NewGetCourierQuery(courierId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	response, err := s.getCourierQueryHandler.Handle(query)
+	if err != nil {
+		if errors.Is(err, errs.ErrObjectNotFound) {
+			return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, mapToCourierDetailsDto(response))
+}
+
+func mapToCourierDetailsDto(response queries.GetCourierResponse) servers.CourierDetails {
+	storagePlaces := make([]servers.StoragePlace, 0, len(response.StoragePlaces))
+	for _, storagePlace := range response.StoragePlaces {
+		storagePlaces = append(storagePlaces, servers.StoragePlace{
+			Id:          storagePlace.ID,
+			Name:        storagePlace.Name,
+			TotalVolume: storagePlace.TotalVolume,
+			IsOccupied:  storagePlace.IsOccupied(),
+			OrderId:     storagePlace.OrderID,
+		})
+	}
+
+	return servers.CourierDetails{
+		Id:    response.ID,
+		Name:  response.Name,
+		Speed: response.Speed,
+		Transport: servers.CourierTransport{
+			Id:   response.TransportID,
+			Name: response.TransportName,
+		},
+		Location: servers.Location{
+			X: response.Location.X,
+			Y: response.Location.Y,
+		},
+		Status:         servers.CourierStatus(response.Status),
+		StoragePlaces:  storagePlaces,
+		OrderIds:       response.OrderIDs,
+		CurrentOrderId: response.CurrentOrderID,
+	}
+}
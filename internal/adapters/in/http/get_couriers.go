@@ -39,6 +39,7 @@ func mapToCouriersDto(response queries.GetAllCouriersResponse) []servers.Courier
 			Id:       courier.ID,
 			Name:     courier.Name,
 			Location: location,
+			Status:   servers.CourierStatus(courier.Status),
 		}
 		couriers = append(couriers, courier)
 	}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"delivery/internal/adapters/in/http/problems"
+	"delivery/internal/core/application/usecases/queries"
+	"delivery/internal/generated/servers"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"net/http"
+)
+
+func (s *Server) GetOrder(c echo.Context, orderId openapi_types.UUID) error {
+	query, err := queries.NewGetOrderQuery(orderId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	response, err := s.getOrderQueryHandler.Handle(query)
+	if err != nil {
+		if errors.Is(err, errs.ErrObjectNotFound) {
+			return c.JSON(http.StatusNotFound, problems.NewNotFound(err.Error()))
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, mapToOrderDetailsDto(response))
+}
+
+func mapToOrderDetailsDto(response queries.GetOrderResponse) servers.OrderDetails {
+	order := servers.OrderDetails{
+		Id:     response.ID,
+		Status: response.Status,
+		Volume: response.Volume,
+		Location: servers.Location{
+			X: response.Location.X,
+			Y: response.Location.Y,
+		},
+		Address:   mapToAddressDto(response.Address),
+		CourierId: response.CourierID,
+		IsLate:    response.IsLate,
+	}
+	if response.CancelReason != "" {
+		order.CancelReason = &response.CancelReason
+	}
+	return order
+}
+
+// mapToAddressDto - у заказов, созданных до появления адреса, его нет
+func mapToAddressDto(response queries.AddressResponse) *servers.Address {
+	if response.Street == "" {
+		return nil
+	}
+	return &servers.Address{
+		Country:   emptyToNil(response.Country),
+		City:      emptyToNil(response.City),
+		Street:    response.Street,
+		House:     emptyToNil(response.House),
+		Apartment: emptyToNil(response.Apartment),
+	}
+}
+
+func emptyToNil(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -38,6 +38,8 @@ func mapToOrdersDto(response queries.GetNotCompletedOrdersResponse) []servers.Or
 		var courier = servers.Order{
 			Id:       courier.ID,
 			Location: location,
+			Address:  mapToAddressDto(courier.Address),
+			IsLate:   courier.IsLate,
 		}
 		orders = append(orders, courier)
 	}
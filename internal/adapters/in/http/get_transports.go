@@ -0,0 +1,44 @@
+package http
+
+import (
+	"delivery/internal/adapters/in/http/problems"
+	"delivery/internal/core/application/usecases/queries"
+	"delivery/internal/generated/servers"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+func (s *Server) GetTransports(c echo.Context) error {
+	query, err := queries.NewGetAllTransportsQuery()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, problems.NewBadRequest(err.Error()))
+	}
+
+	response, err := s.getAllTransportsQueryHandler.Handle(query)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, mapToTransportsDto(response))
+}
+
+func mapToTransportsDto(response queries.GetAllTransportsResponse) []servers.Transport {
+	transports := make([]servers.Transport, 0, len(response.Transports))
+	for _, transport := range response.Transports {
+		storageLayout := make([]servers.TransportStoragePlace, 0, len(transport.StorageLayout))
+		for _, storagePlace := range transport.StorageLayout {
+			storageLayout = append(storageLayout, servers.TransportStoragePlace{
+				Name:        storagePlace.Name,
+				TotalVolume: storagePlace.TotalVolume,
+			})
+		}
+
+		transports = append(transports, servers.Transport{
+			Id:            transport.ID,
+			Name:          transport.Name,
+			Speed:         transport.Speed,
+			StorageLayout: storageLayout,
+		})
+	}
+	return transports
+}
@@ -10,36 +10,84 @@ import (
 var _ servers.ServerInterface = &Server{}
 
 type Server struct {
-	createOrderCommandHandler   commands.CreateOrderCommandHandler
-	createCourierCommandHandler commands.CreateCourierCommandHandler
+	createOrderCommandHandler        commands.CreateOrderCommandHandler
+	cancelOrderCommandHandler        commands.CancelOrderCommandHandler
+	createCourierCommandHandler      commands.CreateCourierCommandHandler
+	changeCourierShiftCommandHandler commands.ChangeCourierShiftCommandHandler
+	addStoragePlaceCommandHandler    commands.AddStoragePlaceCommandHandler
+	changeStoragePlaceCommandHandler commands.ChangeStoragePlaceCommandHandler
+	removeStoragePlaceCommandHandler commands.RemoveStoragePlaceCommandHandler
 
 	getAllCouriersQueryHandler        queries.GetAllCouriersQueryHandler
 	getNotCompletedOrdersQueryHandler queries.GetNotCompletedOrdersQueryHandler
+	getOrderQueryHandler              queries.GetOrderQueryHandler
+	getCourierQueryHandler            queries.GetCourierQueryHandler
+	getAllTransportsQueryHandler      queries.GetAllTransportsQueryHandler
 }
 
 func NewServer(
 	createOrderCommandHandler commands.CreateOrderCommandHandler,
+	cancelOrderCommandHandler commands.CancelOrderCommandHandler,
 	createCourierCommandHandler commands.CreateCourierCommandHandler,
+	changeCourierShiftCommandHandler commands.ChangeCourierShiftCommandHandler,
+	addStoragePlaceCommandHandler commands.AddStoragePlaceCommandHandler,
+	changeStoragePlaceCommandHandler commands.ChangeStoragePlaceCommandHandler,
+	removeStoragePlaceCommandHandler commands.RemoveStoragePlaceCommandHandler,
 
 	getAllCouriersQueryHandler queries.GetAllCouriersQueryHandler,
 	getNotCompletedOrdersQueryHandler queries.GetNotCompletedOrdersQueryHandler,
+	getOrderQueryHandler queries.GetOrderQueryHandler,
+	getCourierQueryHandler queries.GetCourierQueryHandler,
+	getAllTransportsQueryHandler queries.GetAllTransportsQueryHandler,
 ) (*Server, error) {
 	if createOrderCommandHandler == nil {
 		return nil, errs.NewValueIsRequiredError("createOrderCommandHandler")
 	}
+	if cancelOrderCommandHandler == nil {
+		return nil, errs.NewValueIsRequiredError("cancelOrderCommandHandler")
+	}
 	if createCourierCommandHandler == nil {
 		return nil, errs.NewValueIsRequiredError("createCourierCommandHandler")
 	}
+	if changeCourierShiftCommandHandler == nil {
+		return nil, errs.NewValueIsRequiredError("changeCourierShiftCommandHandler")
+	}
+	if addStoragePlaceCommandHandler == nil {
+		return nil, errs.NewValueIsRequiredError("addStoragePlaceCommandHandler")
+	}
+	if changeStoragePlaceCommandHandler == nil {
+		return nil, errs.NewValueIsRequiredError("changeStoragePlaceCommandHandler")
+	}
+	if removeStoragePlaceCommandHandler == nil {
+		return nil, errs.NewValueIsRequiredError("removeStoragePlaceCommandHandler")
+	}
 	if getAllCouriersQueryHandler == nil {
 		return nil, errs.NewValueIsRequiredError("getAllCouriersQueryHandler")
 	}
 	if getNotCompletedOrdersQueryHandler == nil {
 		return nil, errs.NewValueIsRequiredError("getNotCompletedOrdersQueryHandler")
 	}
+	if getOrderQueryHandler == nil {
+		return nil, errs.NewValueIsRequiredError("getOrderQueryHandler")
+	}
+	if getCourierQueryHandler == nil {
+		return nil, errs.NewValueIsRequiredError("getCourierQueryHandler")
+	}
+	if getAllTransportsQueryHandler == nil {
+		return nil, errs.NewValueIsRequiredError("getAllTransportsQueryHandler")
+	}
 	return &Server{
 		createOrderCommandHandler:         createOrderCommandHandler,
+		cancelOrderCommandHandler:         cancelOrderCommandHandler,
 		createCourierCommandHandler:       createCourierCommandHandler,
+		changeCourierShiftCommandHandler:  changeCourierShiftCommandHandler,
+		addStoragePlaceCommandHandler:     addStoragePlaceCommandHandler,
+		changeStoragePlaceCommandHandler:  changeStoragePlaceCommandHandler,
+		removeStoragePlaceCommandHandler:  removeStoragePlaceCommandHandler,
 		getAllCouriersQueryHandler:        getAllCouriersQueryHandler,
 		getNotCompletedOrdersQueryHandler: getNotCompletedOrdersQueryHandler,
+		getOrderQueryHandler:              getOrderQueryHandler,
+		getCourierQueryHandler:            getCourierQueryHandler,
+		getAllTransportsQueryHandler:      getAllTransportsQueryHandler,
 	}, nil
 }
@@ -0,0 +1,13 @@
+package http
+
+import (
+	"delivery/internal/pkg/tracing"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceOrder отмечает спан запроса идентификатором заказа
+func traceOrder(c echo.Context, orderID uuid.UUID) {
+	trace.SpanFromContext(c.Request().Context()).SetAttributes(tracing.OrderID(orderID))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"delivery/internal/core/application/usecases/commands"
 	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
 	"github.com/labstack/gommon/log"
 	"github.com/robfig/cron/v3"
 )
@@ -26,9 +27,10 @@ func NewAssignOrderJob(
 
 func (j *AssignOrderJob) Run() {
 	log.Info("Assign order")
-	ctx := context.Background()
+	ctx, span := tracing.Tracer().Start(context.Background(), "AssignOrderJob")
 	command := commands.NewAssignOrdersCommand()
 	err := j.assignOrdersCommandHandler.Handle(ctx, command)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		log.Error(err)
 	}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Heartbeat замечает каждый запуск заданий. Если планировщик остановился или завис,
+// запусков нет дольше maxAge и проверка не проходит
+type Heartbeat struct {
+	maxAge  time.Duration
+	lastRun atomic.Int64
+	now     func() time.Time
+}
+
+func NewHeartbeat(maxAge time.Duration) (*Heartbeat, error) {
+	if maxAge <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("maxAge", maxAge, "1ns", nil)
+	}
+	h := &Heartbeat{maxAge: maxAge, now: time.Now}
+	// До первого запуска даём планировщику maxAge на старт
+	h.lastRun.Store(h.now().UnixNano())
+	return h, nil
+}
+
+// Wrap отмечает запуск задания до его выполнения, поэтому долгое задание не считается зависшим планировщиком
+func (h *Heartbeat) Wrap(job cron.Job) cron.Job {
+	return cron.FuncJob(func() {
+		h.lastRun.Store(h.now().UnixNano())
+		job.Run()
+	})
+}
+
+func (h *Heartbeat) CheckHealth(_ context.Context) error {
+	lastRun := time.Unix(0, h.lastRun.Load())
+	if idle := h.now().Sub(lastRun); idle > h.maxAge {
+		return fmt.Errorf("no cron jobs started for %s", idle.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeat_CheckHealth(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	heartbeat, err := NewHeartbeat(10 * time.Second)
+	assert.NoError(t, err)
+	heartbeat.now = func() time.Time { return now }
+	heartbeat.lastRun.Store(now.UnixNano())
+	runs := 0
+	job := heartbeat.Wrap(cron.FuncJob(func() { runs++ }))
+
+	// Планировщику даётся время на первый запуск
+	now = now.Add(5 * time.Second)
+	assert.NoError(t, heartbeat.CheckHealth(context.Background()))
+
+	now = now.Add(10 * time.Second)
+	assert.Error(t, heartbeat.CheckHealth(context.Background()))
+
+	job.Run()
+	assert.Equal(t, 1, runs)
+	assert.NoError(t, heartbeat.CheckHealth(context.Background()))
+
+	_, err = NewHeartbeat(0)
+	assert.Error(t, err)
+}
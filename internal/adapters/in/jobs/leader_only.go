@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"delivery/internal/pkg/errs"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Leadership сообщает, является ли реплика лидером
+type Leadership interface {
+	IsLeader() bool
+}
+
+// NewLeaderOnlyJob запускает задание только на лидере. Планировщик при этом работает на всех репликах,
+// поэтому Heartbeat, обёрнутый снаружи, видит запуски и у остальных реплик
+func NewLeaderOnlyJob(job cron.Job, leadership Leadership) (cron.Job, error) {
+	if job == nil {
+		return nil, errs.NewValueIsRequiredError("job")
+	}
+	if leadership == nil {
+		return nil, errs.NewValueIsRequiredError("leadership")
+	}
+	return cron.FuncJob(func() {
+		if leadership.IsLeader() {
+			job.Run()
+		}
+	}), nil
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLeadership bool
+
+func (l *fakeLeadership) IsLeader() bool {
+	return bool(*l)
+}
+
+func TestNewLeaderOnlyJob(t *testing.T) {
+	leader := fakeLeadership(false)
+	runs := 0
+	job, err := NewLeaderOnlyJob(cron.FuncJob(func() { runs++ }), &leader)
+	assert.NoError(t, err)
+
+	job.Run()
+	assert.Equal(t, 0, runs)
+
+	leader = true
+	job.Run()
+	assert.Equal(t, 1, runs)
+
+	_, err = NewLeaderOnlyJob(nil, &leader)
+	assert.Error(t, err)
+}
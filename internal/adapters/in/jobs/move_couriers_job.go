@@ -4,6 +4,7 @@ import (
 	"context"
 	"delivery/internal/core/application/usecases/commands"
 	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
 	"github.com/labstack/gommon/log"
 	"github.com/robfig/cron/v3"
 )
@@ -25,12 +26,13 @@ func NewMoveCouriersJob(commandHandler commands.MoveCouriersCommandHandler) (*Mo
 
 func (j *MovingCouriersJob) Run() {
 	log.Info("Moving couriers")
-	ctx := context.Background()
+	ctx, span := tracing.Tracer().Start(context.Background(), "MovingCouriersJob")
 	command, err := commands.NewMoveCouriersCmd()
 	if err != nil {
 		log.Error(err)
 	}
 	err = j.moveCourierCommandHandler.Handle(ctx, command)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		log.Error(err)
 	}
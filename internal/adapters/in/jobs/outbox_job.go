@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"delivery/internal/adapters/out/postgres/outbox"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
+	"github.com/labstack/gommon/log"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ cron.Job = &OutboxJob{}
+
+type OutboxJob struct {
+	outboxRepository outbox.Repository
+	eventRegistry    outbox.EventRegistry
+	orderProducer    ports.OrderProducer
+}
+
+func NewOutboxJob(
+	outboxRepository outbox.Repository,
+	eventRegistry outbox.EventRegistry,
+	orderProducer ports.OrderProducer) (*OutboxJob, error) {
+	if outboxRepository == nil {
+		return nil, errs.NewValueIsRequiredError("outboxRepository")
+	}
+	if eventRegistry == nil {
+		return nil, errs.NewValueIsRequiredError("eventRegistry")
+	}
+	if orderProducer == nil {
+		return nil, errs.NewValueIsRequiredError("orderProducer")
+	}
+
+	return &OutboxJob{
+		outboxRepository: outboxRepository,
+		eventRegistry:    eventRegistry,
+		orderProducer:    orderProducer}, nil
+}
+
+func (j *OutboxJob) Run() {
+	ctx, span := tracing.Tracer().Start(context.Background(), "OutboxJob")
+	err := j.publishMessages(ctx)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (j *OutboxJob) publishMessages(ctx context.Context) error {
+	messages, err := j.outboxRepository.GetNotPublishedMessages(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		// Сохраняем порядок событий: при ошибке следующая попытка начнётся с этого же сообщения
+		err = j.publishMessage(ctx, message)
+		if err != nil {
+			return err
+		}
+
+		err = j.outboxRepository.MarkAsPublished(ctx, message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishMessage продолжает трассу, в которой возникло событие, и связывает её с запуском задания
+func (j *OutboxJob) publishMessage(ctx context.Context, message *outbox.MessageDTO) (err error) {
+	messageCtx := outbox.DecodeTraceContext(ctx, message)
+	messageCtx, span := tracing.Tracer().Start(messageCtx, "OutboxJob.Publish "+message.Name,
+		trace.WithLinks(trace.LinkFromContext(ctx)))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	domainEvent, err := j.eventRegistry.DecodeDomainEvent(message)
+	if err != nil {
+		return err
+	}
+
+	switch event := domainEvent.(type) {
+	case order.StatusChangedDomainEvent:
+		span.SetAttributes(tracing.OrderID(event.OrderID))
+		return j.orderProducer.Publish(messageCtx, event)
+	default:
+		log.Warnf("Outbox message %s has no publisher for event %s", message.ID, message.Name)
+		return nil
+	}
+}
@@ -3,32 +3,73 @@ package kafka
 import (
 	"context"
 	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/generated/queues/basketconfirmedpb"
 	"delivery/internal/pkg/errs"
-	"encoding/json"
+	"delivery/internal/pkg/tracing"
 	"fmt"
 	"github.com/IBM/sarama"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"log"
+	"sync/atomic"
+	"time"
 )
 
+// Итоги обработки сообщения для ConsumerObserver
+const (
+	MessageProcessed    = "processed"
+	MessageRetried      = "retried"
+	MessageDeadLettered = "dead_lettered"
+	MessageFailed       = "failed"
+)
+
+// ConsumerObserver узнаёт о каждом прочитанном сообщении, например, чтобы вести метрики
+type ConsumerObserver interface {
+	// MessageReceived - lag: сколько сообщений партиции осталось прочитать после этого
+	MessageReceived(topic string, partition int32, lag int64)
+	// MessageHandled - result: MessageProcessed, MessageRetried, MessageDeadLettered или MessageFailed
+	MessageHandled(topic string, result string)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) MessageReceived(string, int32, int64) {}
+func (noopObserver) MessageHandled(string, string)        {}
+
 type BasketConfirmedConsumer interface {
 	Consume() error
 	Close() error
+	// CheckHealth проходит, пока консьюмер состоит в группе и получил партиции
+	CheckHealth(ctx context.Context) error
 }
 
 var _ BasketConfirmedConsumer = &basketConfirmedConsumer{}
 
 type basketConfirmedConsumer struct {
+	group                     string
 	topic                     string
 	consumerGroup             sarama.ConsumerGroup
 	createOrderCommandHandler commands.CreateOrderCommandHandler
+	deadLetterProducer        DeadLetterProducer
+	retryPolicy               RetryPolicy
+	decoder                   *basketConfirmedDecoder
+	observer                  ConsumerObserver
+	deliveryZone              *time.Location
+	member                    atomic.Bool
 	ctx                       context.Context
 	cancel                    context.CancelFunc
 }
 
+// NewBasketConfirmedConsumer - временные ошибки повторяются по retryPolicy,
+// сообщения, которые так и не удалось обработать, уходят в deadLetterProducer.
+// schemaRegistry необязателен и нужен только для сообщений в формате Confluent Schema Registry,
+// observer тоже необязателен. Часы окна доставки корзины отсчитываются в часовом поясе магазина deliveryZone
 func NewBasketConfirmedConsumer(brokers []string, group string, topic string,
-	createOrderCommandHandler commands.CreateOrderCommandHandler) (BasketConfirmedConsumer, error) {
+	createOrderCommandHandler commands.CreateOrderCommandHandler,
+	deadLetterProducer DeadLetterProducer, retryPolicy RetryPolicy,
+	schemaRegistry SchemaRegistry, observer ConsumerObserver,
+	deliveryZone *time.Location) (BasketConfirmedConsumer, error) {
 	if brokers == nil || len(brokers) == 0 {
 		return nil, errs.NewValueIsRequiredError("brokers")
 	}
@@ -41,6 +82,15 @@ func NewBasketConfirmedConsumer(brokers []string, group string, topic string,
 	if createOrderCommandHandler == nil {
 		return nil, errs.NewValueIsRequiredError("createOrderCommandHandler")
 	}
+	if deadLetterProducer == nil {
+		return nil, errs.NewValueIsRequiredError("deadLetterProducer")
+	}
+	if retryPolicy.MaxAttempts() < 1 {
+		return nil, errs.NewValueIsRequiredError("retryPolicy")
+	}
+	if deliveryZone == nil {
+		return nil, errs.NewValueIsRequiredError("deliveryZone")
+	}
 
 	saramaCfg := sarama.NewConfig()
 	saramaCfg.Version = sarama.V3_4_0_0
@@ -52,75 +102,213 @@ func NewBasketConfirmedConsumer(brokers []string, group string, topic string,
 		return nil, fmt.Errorf("failed to create consumer group: %w", err)
 	}
 
+	if observer == nil {
+		observer = noopObserver{}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &basketConfirmedConsumer{
+		group:                     group,
 		topic:                     topic,
 		consumerGroup:             consumerGroup,
 		createOrderCommandHandler: createOrderCommandHandler,
+		deadLetterProducer:        deadLetterProducer,
+		retryPolicy:               retryPolicy,
+		decoder:                   newBasketConfirmedDecoder(schemaRegistry),
+		observer:                  observer,
+		deliveryZone:              deliveryZone,
 		ctx:                       ctx,
 		cancel:                    cancel,
 	}, nil
 }
 
+// Close дожидается обработки текущего сообщения, фиксирует смещения и выходит из группы
 func (c *basketConfirmedConsumer) Close() error {
 	c.cancel()
 	return c.consumerGroup.Close()
 }
 
+func (c *basketConfirmedConsumer) CheckHealth(_ context.Context) error {
+	if c.ctx.Err() != nil {
+		return fmt.Errorf("consumer is closed")
+	}
+	if !c.member.Load() {
+		return fmt.Errorf("consumer is not a member of group %s", c.group)
+	}
+	return nil
+}
+
 func (c *basketConfirmedConsumer) Consume() error {
 	handler := &consumerGroupHandler{
+		member:                    &c.member,
 		createOrderCommandHandler: c.createOrderCommandHandler,
+		deadLetterProducer:        c.deadLetterProducer,
+		retryPolicy:               c.retryPolicy,
+		decoder:                   c.decoder,
+		observer:                  c.observer,
+		deliveryZone:              c.deliveryZone,
+		now:                       time.Now,
 	}
 
 	for {
 		err := c.consumerGroup.Consume(c.ctx, []string{c.topic}, handler)
+		// После Close группа возвращает ErrClosedConsumerGroup - это штатная остановка
+		if c.ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			log.Printf("Error from consumer: %v", err)
 			return err
 		}
-		if c.ctx.Err() != nil {
-			return nil
-		}
 	}
 }
 
 type consumerGroupHandler struct {
+	// member отмечает, что консьюмер вошёл в группу: между Setup и Cleanup сессии
+	member                    *atomic.Bool
 	createOrderCommandHandler commands.CreateOrderCommandHandler
+	deadLetterProducer        DeadLetterProducer
+	retryPolicy               RetryPolicy
+	decoder                   *basketConfirmedDecoder
+	observer                  ConsumerObserver
+	deliveryZone              *time.Location
+	now                       func() time.Time
+}
+
+func (h *consumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
+	if h.member != nil {
+		h.member.Store(true)
+	}
+	return nil
+}
+
+func (h *consumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
+	if h.member != nil {
+		h.member.Store(false)
+	}
+	return nil
 }
 
-func (h *consumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
-func (h *consumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
 func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for message := range claim.Messages() {
-		ctx := context.Background()
 		fmt.Printf("Received: topic = %s, partition = %d, offset = %d, key = %s, value = %s\n",
 			message.Topic, message.Partition, message.Offset, string(message.Key), string(message.Value))
+		h.observer.MessageReceived(message.Topic, message.Partition, claim.HighWaterMarkOffset()-message.Offset-1)
 
-		var event basketconfirmedpb.BasketConfirmedIntegrationEvent
-		err := json.Unmarshal(message.Value, &event)
+		// Спан охватывает все попытки и продолжает трассу отправителя из заголовков сообщения
+		ctx, span := tracing.StartConsumerSpan(session.Context(), message)
+		err := h.process(ctx, message)
+		tracing.EndSpan(span, err)
 		if err != nil {
-			log.Printf("Failed to unmarshal message: %v", err)
-			session.MarkMessage(message, "") // Всё равно отметить как прочитанное
-			continue
+			// Сообщение не отмечаем: после перебалансировки оно будет прочитано снова
+			return err
 		}
 
-		createOrderCommand, err := commands.NewCreateOrderCmd(
-			uuid.MustParse(event.BasketId), event.Address.Street, int(event.Volume))
-		if err != nil {
-			log.Printf("Failed to create createOrder command: %v", err)
-			session.MarkMessage(message, "")
-			continue
+		// Сообщение обработано или отложено в DLQ — отметить его
+		session.MarkMessage(message, "")
+	}
+
+	return nil
+}
+
+// process обрабатывает сообщение с повторами. Ошибка возвращается, только если сообщение
+// не удалось ни обработать, ни отложить в DLQ
+func (h *consumerGroupHandler) process(ctx context.Context, message *sarama.ConsumerMessage) error {
+	attempt := 1
+	for {
+		err := h.handle(ctx, message)
+		if err == nil {
+			h.observer.MessageHandled(message.Topic, MessageProcessed)
+			return nil
+		}
+
+		if isPermanent(err) || attempt >= h.retryPolicy.MaxAttempts() {
+			log.Printf("Failed to handle message (offset %d) after %d attempt(s), sending to DLQ: %v",
+				message.Offset, attempt, err)
+			err = h.deadLetterProducer.Publish(ctx, message, err, attempt)
+			if err != nil {
+				h.observer.MessageHandled(message.Topic, MessageFailed)
+				return err
+			}
+			h.observer.MessageHandled(message.Topic, MessageDeadLettered)
+			return nil
+		}
+
+		h.observer.MessageHandled(message.Topic, MessageRetried)
+
+		log.Printf("Failed to handle message (offset %d), attempt %d of %d: %v",
+			message.Offset, attempt, h.retryPolicy.MaxAttempts(), err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(h.retryPolicy.Backoff(attempt)):
 		}
+		attempt++
+	}
+}
+
+func (h *consumerGroupHandler) handle(ctx context.Context, message *sarama.ConsumerMessage) error {
+	event, err := h.decoder.Decode(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	basketID, err := uuid.Parse(event.BasketId)
+	if err != nil {
+		return newPermanentError(fmt.Errorf("invalid basket id %q: %w", event.BasketId, err))
+	}
+	trace.SpanFromContext(ctx).SetAttributes(tracing.OrderID(basketID))
+	if event.Address == nil {
+		return errs.NewValueIsRequiredError("address")
+	}
 
-		err = h.createOrderCommandHandler.Handle(ctx, createOrderCommand)
+	address, err := kernel.NewAddress(event.Address.Country, event.Address.City, event.Address.Street,
+		event.Address.House, event.Address.Apartment)
+	if err != nil {
+		return err
+	}
+
+	createOrderCommand, err := commands.NewCreateOrderCmd(basketID, address, int(event.Volume))
+	if err != nil {
+		return err
+	}
+
+	// Некорректное окно - постоянная ошибка: сообщение уходит в DLQ, а не теряется
+	if event.DeliveryPeriod != nil {
+		from, to, err := deliveryPeriodToTime(event.DeliveryPeriod, h.now(), h.deliveryZone)
+		if err != nil {
+			return err
+		}
+		createOrderCommand, err = createOrderCommand.WithDeliveryPeriod(from, to)
 		if err != nil {
-			log.Printf("Failed to handle createOrder command: %v", err)
+			return err
 		}
+	}
 
-		// После успешной обработки сообщения — отметить его
-		session.MarkMessage(message, "")
+	return h.createOrderCommandHandler.Handle(ctx, createOrderCommand)
+}
+
+// deliveryPeriodToTime переводит окно доставки корзины (часы суток from..to по местному времени магазина zone)
+// в ближайший ещё не закрывшийся интервал. Полночь и часы считаются по календарю zone, поэтому
+// в дни перехода на летнее время окно сдвигается вместе с часами
+func deliveryPeriodToTime(period *basketconfirmedpb.DeliveryPeriod, now time.Time, zone *time.Location) (time.Time, time.Time, error) {
+	if period.From < 0 || period.From > 24 {
+		return time.Time{}, time.Time{}, errs.NewValueIsOutOfRangeError("deliveryPeriod.from", period.From, 0, 24)
+	}
+	if period.To <= period.From || period.To > 24 {
+		return time.Time{}, time.Time{}, errs.NewValueIsOutOfRangeError("deliveryPeriod.to", period.To, period.From+1, 24)
 	}
 
-	return nil
+	now = now.In(zone)
+	day := func(offset int) (time.Time, time.Time) {
+		year, month, date := now.Date()
+		return time.Date(year, month, date+offset, int(period.From), 0, 0, 0, zone),
+			time.Date(year, month, date+offset, int(period.To), 0, 0, 0, zone)
+	}
+	from, to := day(0)
+	if !to.After(now) {
+		from, to = day(1)
+	}
+	return from.UTC(), to.UTC(), nil
 }
@@ -0,0 +1,314 @@
+package kafka
+
+import (
+	"context"
+	"delivery/internal/core/application/usecases/commands"
+	"delivery/internal/generated/queues/basketconfirmedpb"
+	"delivery/internal/pkg/tracing"
+	"delivery/internal/pkg/tracing/tracingtest"
+	"errors"
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestConsumerGroupHandler_Process(t *testing.T) {
+	validMessage := func() *sarama.ConsumerMessage {
+		return &sarama.ConsumerMessage{
+			Topic: "basket.confirmed",
+			Value: []byte(`{"basketId":"` + uuid.NewString() + `","address":{"street":"Тестовая"},"Volume":1}`),
+		}
+	}
+
+	t.Run("handled message is not sent to DLQ", func(t *testing.T) {
+		createOrder := &fakeCreateOrderHandler{}
+		dlq := &fakeDeadLetterProducer{}
+		handler := newTestHandler(createOrder, dlq, 3)
+
+		err := handler.process(context.Background(), validMessage())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, createOrder.calls)
+		assert.Empty(t, dlq.published)
+	})
+
+	t.Run("transient error is retried until success", func(t *testing.T) {
+		createOrder := &fakeCreateOrderHandler{errs: []error{errors.New("geo is down"), errors.New("geo is down")}}
+		dlq := &fakeDeadLetterProducer{}
+		handler := newTestHandler(createOrder, dlq, 3)
+
+		err := handler.process(context.Background(), validMessage())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, createOrder.calls)
+		assert.Empty(t, dlq.published)
+	})
+
+	t.Run("transient error goes to DLQ when attempts are exhausted", func(t *testing.T) {
+		geoErr := errors.New("geo is down")
+		createOrder := &fakeCreateOrderHandler{errs: []error{geoErr, geoErr, geoErr}}
+		dlq := &fakeDeadLetterProducer{}
+		handler := newTestHandler(createOrder, dlq, 3)
+
+		err := handler.process(context.Background(), validMessage())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, createOrder.calls)
+		assert.Len(t, dlq.published, 1)
+		assert.ErrorIs(t, dlq.published[0].cause, geoErr)
+		assert.Equal(t, 3, dlq.published[0].attempts)
+	})
+
+	t.Run("poison messages go to DLQ without retries", func(t *testing.T) {
+		tests := map[string][]byte{
+			"malformed_json":   []byte(`{not json`),
+			"bad_basket_id":    []byte(`{"basketId":"not-a-uuid","address":{"street":"Тестовая"},"Volume":1}`),
+			"missing_address":  []byte(`{"basketId":"` + uuid.NewString() + `","Volume":1}`),
+			"invalid_period":   []byte(`{"basketId":"` + uuid.NewString() + `","address":{"street":"Тестовая"},"Volume":1,"deliveryPeriod":{"from":10,"to":5}}`),
+			"zero_volume":      []byte(`{"basketId":"` + uuid.NewString() + `","address":{"street":"Тестовая"},"Volume":0}`),
+			"empty_street_cmd": []byte(`{"basketId":"` + uuid.NewString() + `","address":{"street":""},"Volume":1}`),
+		}
+
+		for name, value := range tests {
+			t.Run(name, func(t *testing.T) {
+				createOrder := &fakeCreateOrderHandler{}
+				dlq := &fakeDeadLetterProducer{}
+				handler := newTestHandler(createOrder, dlq, 3)
+
+				err := handler.process(context.Background(), &sarama.ConsumerMessage{Value: value})
+
+				assert.NoError(t, err)
+				assert.Equal(t, 0, createOrder.calls)
+				assert.Len(t, dlq.published, 1)
+				assert.Equal(t, 1, dlq.published[0].attempts)
+			})
+		}
+	})
+
+	t.Run("message is not marked if DLQ is unavailable", func(t *testing.T) {
+		dlqErr := errors.New("dlq is down")
+		handler := newTestHandler(&fakeCreateOrderHandler{}, &fakeDeadLetterProducer{err: dlqErr}, 3)
+
+		err := handler.process(context.Background(), &sarama.ConsumerMessage{Value: []byte(`{not json`)})
+
+		assert.ErrorIs(t, err, dlqErr)
+	})
+
+	t.Run("observer sees result of every attempt", func(t *testing.T) {
+		geoErr := errors.New("geo is down")
+		observer := &recordingObserver{}
+		handler := newTestHandler(&fakeCreateOrderHandler{errs: []error{geoErr, geoErr}}, &fakeDeadLetterProducer{}, 2)
+		handler.observer = observer
+
+		_ = handler.process(context.Background(), validMessage())
+		_ = handler.process(context.Background(), validMessage())
+
+		assert.Equal(t, []string{MessageRetried, MessageDeadLettered, MessageProcessed}, observer.results)
+	})
+
+	t.Run("stop retrying when context is cancelled", func(t *testing.T) {
+		createOrder := &fakeCreateOrderHandler{errs: []error{errors.New("geo is down")}}
+		dlq := &fakeDeadLetterProducer{}
+		handler := newTestHandler(createOrder, dlq, 3)
+		handler.retryPolicy, _ = NewRetryPolicy(3, time.Hour, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := handler.process(ctx, validMessage())
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, dlq.published)
+	})
+}
+
+func TestConsumerGroupHandler_ConsumeClaim_Tracing(t *testing.T) {
+	exporter := tracingtest.Use(t)
+	basketID := uuid.New()
+	message := &sarama.ConsumerMessage{
+		Topic:   "basket.confirmed",
+		Value:   []byte(`{"basketId":"` + basketID.String() + `","address":{"street":"Тестовая"},"Volume":1}`),
+		Headers: []*sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte(testTraceParent)}},
+	}
+	createOrder := &fakeCreateOrderHandler{}
+	handler := newTestHandler(createOrder, &fakeDeadLetterProducer{}, 3)
+
+	err := handler.ConsumeClaim(newFakeSession(), newFakeClaim(message))
+
+	assert.NoError(t, err)
+	span, ok := tracingtest.SpanByName(exporter, "basket.confirmed process")
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, basketID.String(), tracingtest.Attribute(span, string(tracing.OrderIDKey)))
+	// Сценарий создания заказа выполняется внутри спана сообщения
+	assert.Equal(t, span.SpanContext.SpanID(), createOrder.spanContext.SpanID())
+}
+
+func TestBasketConfirmedConsumer_CheckHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := &basketConfirmedConsumer{group: "delivery", ctx: ctx, cancel: cancel}
+	handler := &consumerGroupHandler{member: &consumer.member}
+
+	assert.Error(t, consumer.CheckHealth(context.Background()), "not joined yet")
+
+	_ = handler.Setup(newFakeSession())
+	assert.NoError(t, consumer.CheckHealth(context.Background()))
+
+	// Перебалансировка: до следующего Setup партиций у консьюмера нет
+	_ = handler.Cleanup(newFakeSession())
+	assert.Error(t, consumer.CheckHealth(context.Background()))
+
+	_ = handler.Setup(newFakeSession())
+	cancel()
+	assert.Error(t, consumer.CheckHealth(context.Background()), "closed consumer")
+}
+
+func TestDeliveryPeriodToTime(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	period := &basketconfirmedpb.DeliveryPeriod{From: 9, To: 12}
+
+	t.Run("hours are local to the shop zone", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 5, 0, 0, 0, time.UTC) // 08:00 MSK
+
+		from, to, err := deliveryPeriodToTime(period, now, moscow)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), to)
+	})
+
+	t.Run("closed window moves to the next local day", func(t *testing.T) {
+		now := time.Date(2024, 12, 31, 22, 0, 0, 0, time.UTC) // 01:00 MSK 1 января
+
+		from, _, err := deliveryPeriodToTime(period, now, moscow)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC), from)
+
+		now = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC) // 13:00 MSK
+		from, _, err = deliveryPeriodToTime(period, now, moscow)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 2, 6, 0, 0, 0, time.UTC), from)
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy, err := NewRetryPolicy(5, 100*time.Millisecond, 300*time.Millisecond)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(10))
+
+	_, err = NewRetryPolicy(0, time.Second, time.Second)
+	assert.Error(t, err)
+}
+
+func newTestHandler(createOrder commands.CreateOrderCommandHandler, dlq DeadLetterProducer, maxAttempts int) *consumerGroupHandler {
+	policy, _ := NewRetryPolicy(maxAttempts, time.Millisecond, time.Millisecond)
+	return &consumerGroupHandler{
+		createOrderCommandHandler: createOrder,
+		deadLetterProducer:        dlq,
+		retryPolicy:               policy,
+		decoder:                   newBasketConfirmedDecoder(nil),
+		observer:                  noopObserver{},
+		deliveryZone:              time.UTC,
+		now:                       time.Now,
+	}
+}
+
+type recordingObserver struct {
+	results []string
+}
+
+func (o *recordingObserver) MessageReceived(string, int32, int64) {}
+
+func (o *recordingObserver) MessageHandled(_ string, result string) {
+	o.results = append(o.results, result)
+}
+
+// fakeCreateOrderHandler по очереди возвращает заданные ошибки, затем успех
+type fakeCreateOrderHandler struct {
+	errs        []error
+	calls       int
+	spanContext trace.SpanContext
+}
+
+func (h *fakeCreateOrderHandler) Handle(ctx context.Context, _ commands.CreateOrderCmd) error {
+	h.calls++
+	h.spanContext = trace.SpanContextFromContext(ctx)
+	if len(h.errs) == 0 {
+		return nil
+	}
+	err := h.errs[0]
+	h.errs = h.errs[1:]
+	return err
+}
+
+type deadLetter struct {
+	message  *sarama.ConsumerMessage
+	cause    error
+	attempts int
+}
+
+type fakeDeadLetterProducer struct {
+	published []deadLetter
+	err       error
+}
+
+func (p *fakeDeadLetterProducer) Publish(_ context.Context, message *sarama.ConsumerMessage, cause error, attempts int) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, deadLetter{message: message, cause: cause, attempts: attempts})
+	return nil
+}
+
+func (p *fakeDeadLetterProducer) Close() error {
+	return nil
+}
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// fakeSession и fakeClaim реализуют только то, что нужно ConsumeClaim
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	marked []*sarama.ConsumerMessage
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{}
+}
+
+func (s *fakeSession) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeSession) MarkMessage(message *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, message)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeClaim(messages ...*sarama.ConsumerMessage) *fakeClaim {
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(messages))}
+	for _, message := range messages {
+		claim.messages <- message
+	}
+	close(claim.messages)
+	return claim
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+func (c *fakeClaim) HighWaterMarkOffset() int64 {
+	return 0
+}
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"fmt"
+	"github.com/IBM/sarama"
+	"strconv"
+	"time"
+)
+
+// Заголовки, с которыми сообщение попадает в DLQ
+const (
+	HeaderDlqError             = "x-dlq-error"
+	HeaderDlqAttempts          = "x-dlq-attempts"
+	HeaderDlqFailedAt          = "x-dlq-failed-at"
+	HeaderDlqOriginalTopic     = "x-dlq-original-topic"
+	HeaderDlqOriginalPartition = "x-dlq-original-partition"
+	HeaderDlqOriginalOffset    = "x-dlq-original-offset"
+)
+
+// DeadLetterProducer откладывает сообщения, которые не удалось обработать, в отдельный топик
+type DeadLetterProducer interface {
+	Publish(ctx context.Context, message *sarama.ConsumerMessage, cause error, attempts int) error
+	Close() error
+}
+
+var _ DeadLetterProducer = &deadLetterProducer{}
+
+type deadLetterProducer struct {
+	topic    string
+	producer sarama.SyncProducer
+	now      func() time.Time
+}
+
+func NewDeadLetterProducer(brokers []string, topic string) (DeadLetterProducer, error) {
+	if len(brokers) == 0 {
+		return nil, errs.NewValueIsRequiredError("brokers")
+	}
+	if topic == "" {
+		return nil, errs.NewValueIsRequiredError("topic")
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, newSyncProducerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter producer: %w", err)
+	}
+
+	return &deadLetterProducer{
+		topic:    topic,
+		producer: producer,
+		now:      time.Now,
+	}, nil
+}
+
+func (p *deadLetterProducer) Close() error {
+	return p.producer.Close()
+}
+
+func (p *deadLetterProducer) Publish(ctx context.Context, message *sarama.ConsumerMessage, cause error, attempts int) error {
+	if message == nil {
+		return errs.NewValueIsRequiredError("message")
+	}
+	if cause == nil {
+		return errs.NewValueIsRequiredError("cause")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, _, err := p.producer.SendMessage(newDeadLetterMessage(p.topic, message, cause, attempts, p.now()))
+	if err != nil {
+		return fmt.Errorf("failed to send message to dead letter topic: %w", err)
+	}
+	return nil
+}
+
+// newDeadLetterMessage сохраняет ключ, тело и исходные заголовки, добавляя сведения об ошибке
+func newDeadLetterMessage(topic string, message *sarama.ConsumerMessage, cause error, attempts int,
+	failedAt time.Time) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+6)
+	for _, header := range message.Headers {
+		if header == nil || isDeadLetterHeader(string(header.Key)) {
+			continue
+		}
+		headers = append(headers, *header)
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderDlqError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderDlqAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(HeaderDlqFailedAt), Value: []byte(failedAt.UTC().Format(time.RFC3339))},
+		sarama.RecordHeader{Key: []byte(HeaderDlqOriginalTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDlqOriginalPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderDlqOriginalOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+	)
+
+	return &sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     keyEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+}
+
+// keyEncoder сохраняет пустой ключ пустым, чтобы партиционирование не изменилось
+func keyEncoder(key []byte) sarama.Encoder {
+	if key == nil {
+		return nil
+	}
+	return sarama.ByteEncoder(key)
+}
+
+func isDeadLetterHeader(key string) bool {
+	switch key {
+	case HeaderDlqError, HeaderDlqAttempts, HeaderDlqFailedAt,
+		HeaderDlqOriginalTopic, HeaderDlqOriginalPartition, HeaderDlqOriginalOffset:
+		return true
+	}
+	return false
+}
+
+func newSyncProducerConfig() *sarama.Config {
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Version = sarama.V3_4_0_0
+	saramaCfg.Producer.RequiredAcks = sarama.WaitForAll
+	saramaCfg.Producer.Return.Successes = true
+	saramaCfg.Producer.Idempotent = true
+	saramaCfg.Net.MaxOpenRequests = 1
+	return saramaCfg
+}
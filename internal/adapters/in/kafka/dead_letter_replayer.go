@@ -0,0 +1,188 @@
+package kafka
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"fmt"
+	"github.com/IBM/sarama"
+)
+
+// DeadLetterReplayer возвращает сообщения из DLQ в исходный топик.
+// Прочитанная позиция фиксируется в отдельной группе, поэтому повторный запуск не дублирует сообщения
+type DeadLetterReplayer interface {
+	// Replay переотправляет не больше limit сообщений (0 - все накопленные) и возвращает их количество
+	Replay(ctx context.Context, limit int) (int, error)
+	Close() error
+}
+
+var _ DeadLetterReplayer = &deadLetterReplayer{}
+
+type deadLetterReplayer struct {
+	dlqTopic      string
+	fallbackTopic string
+	client        sarama.Client
+	offsets       sarama.OffsetManager
+	consumer      sarama.Consumer
+	producer      sarama.SyncProducer
+}
+
+// NewDeadLetterReplayer - fallbackTopic используется, если у сообщения нет заголовка с исходным топиком
+func NewDeadLetterReplayer(brokers []string, group string, dlqTopic string, fallbackTopic string) (DeadLetterReplayer, error) {
+	if len(brokers) == 0 {
+		return nil, errs.NewValueIsRequiredError("brokers")
+	}
+	if group == "" {
+		return nil, errs.NewValueIsRequiredError("group")
+	}
+	if dlqTopic == "" {
+		return nil, errs.NewValueIsRequiredError("dlqTopic")
+	}
+	if fallbackTopic == "" {
+		return nil, errs.NewValueIsRequiredError("fallbackTopic")
+	}
+
+	saramaCfg := newSyncProducerConfig()
+	saramaCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	saramaCfg.Consumer.Offsets.AutoCommit.Enable = false
+
+	client, err := sarama.NewClient(brokers, saramaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka client: %w", err)
+	}
+
+	replayer := &deadLetterReplayer{
+		dlqTopic:      dlqTopic,
+		fallbackTopic: fallbackTopic,
+		client:        client,
+	}
+	replayer.offsets, err = sarama.NewOffsetManagerFromClient(group, client)
+	if err == nil {
+		replayer.consumer, err = sarama.NewConsumerFromClient(client)
+	}
+	if err == nil {
+		replayer.producer, err = sarama.NewSyncProducerFromClient(client)
+	}
+	if err != nil {
+		_ = replayer.Close()
+		return nil, fmt.Errorf("failed to create dead letter replayer: %w", err)
+	}
+	return replayer, nil
+}
+
+func (r *deadLetterReplayer) Close() error {
+	var closeErr error
+	for _, closer := range []interface{ Close() error }{r.producer, r.consumer, r.offsets} {
+		if closer == nil {
+			continue
+		}
+		if err := closer.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	if err := r.client.Close(); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	return closeErr
+}
+
+func (r *deadLetterReplayer) Replay(ctx context.Context, limit int) (int, error) {
+	if limit < 0 {
+		return 0, errs.NewValueIsOutOfRangeError("limit", limit, 0, nil)
+	}
+
+	partitions, err := r.client.Partitions(r.dlqTopic)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get partitions of %s: %w", r.dlqTopic, err)
+	}
+
+	replayed := 0
+	for _, partition := range partitions {
+		if limit > 0 && replayed >= limit {
+			break
+		}
+		count, err := r.replayPartition(ctx, partition, limit-replayed)
+		replayed += count
+		if err != nil {
+			return replayed, err
+		}
+	}
+	return replayed, nil
+}
+
+// replayPartition переотправляет сообщения партиции, накопленные к моменту запуска
+func (r *deadLetterReplayer) replayPartition(ctx context.Context, partition int32, limit int) (int, error) {
+	newest, err := r.client.GetOffset(r.dlqTopic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, err
+	}
+
+	partitionOffsets, err := r.offsets.ManagePartition(r.dlqTopic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer partitionOffsets.AsyncClose()
+
+	next, _ := partitionOffsets.NextOffset()
+	if next == sarama.OffsetOldest {
+		next, err = r.client.GetOffset(r.dlqTopic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if next >= newest {
+		return 0, nil
+	}
+
+	partitionConsumer, err := r.consumer.ConsumePartition(r.dlqTopic, partition, next)
+	if err != nil {
+		return 0, err
+	}
+	defer partitionConsumer.AsyncClose()
+
+	replayed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return replayed, ctx.Err()
+		case consumerErr := <-partitionConsumer.Errors():
+			return replayed, consumerErr
+		case message := <-partitionConsumer.Messages():
+			_, _, err = r.producer.SendMessage(newReplayMessage(message, r.fallbackTopic))
+			if err != nil {
+				return replayed, fmt.Errorf("failed to replay message %d of partition %d: %w", message.Offset, partition, err)
+			}
+			partitionOffsets.MarkOffset(message.Offset+1, "")
+			r.offsets.Commit()
+			replayed++
+
+			if message.Offset+1 >= newest || (limit > 0 && replayed >= limit) {
+				return replayed, nil
+			}
+		}
+	}
+}
+
+// newReplayMessage убирает служебные заголовки DLQ, чтобы сообщение выглядело как исходное
+func newReplayMessage(message *sarama.ConsumerMessage, fallbackTopic string) *sarama.ProducerMessage {
+	topic := fallbackTopic
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers))
+	for _, header := range message.Headers {
+		if header == nil {
+			continue
+		}
+		if string(header.Key) == HeaderDlqOriginalTopic && len(header.Value) > 0 {
+			topic = string(header.Value)
+		}
+		if isDeadLetterHeader(string(header.Key)) {
+			continue
+		}
+		headers = append(headers, *header)
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     keyEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+}
@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"errors"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterMessage_RoundTrip(t *testing.T) {
+	original := &sarama.ConsumerMessage{
+		Topic:     "basket.confirmed",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("trace-id"), Value: []byte("abc")}},
+	}
+	failedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	deadLetter := newDeadLetterMessage("basket.confirmed.dlq", original, errors.New("boom"), 3, failedAt)
+
+	assert.Equal(t, "basket.confirmed.dlq", deadLetter.Topic)
+	headers := headersToMap(deadLetter.Headers)
+	assert.Equal(t, "abc", headers["trace-id"])
+	assert.Equal(t, "boom", headers[HeaderDlqError])
+	assert.Equal(t, "3", headers[HeaderDlqAttempts])
+	assert.Equal(t, "2025-01-02T03:04:05Z", headers[HeaderDlqFailedAt])
+	assert.Equal(t, "basket.confirmed", headers[HeaderDlqOriginalTopic])
+	assert.Equal(t, "2", headers[HeaderDlqOriginalPartition])
+	assert.Equal(t, "42", headers[HeaderDlqOriginalOffset])
+
+	// Из DLQ сообщение возвращается в исходный топик без служебных заголовков
+	stored := &sarama.ConsumerMessage{Key: original.Key, Value: original.Value}
+	for i := range deadLetter.Headers {
+		stored.Headers = append(stored.Headers, &deadLetter.Headers[i])
+	}
+	replayed := newReplayMessage(stored, "fallback")
+
+	assert.Equal(t, "basket.confirmed", replayed.Topic)
+	assert.Equal(t, map[string]string{"trace-id": "abc"}, headersToMap(replayed.Headers))
+	value, _ := replayed.Value.Encode()
+	assert.Equal(t, original.Value, value)
+}
+
+func TestReplayMessage_WithoutOriginalTopic(t *testing.T) {
+	replayed := newReplayMessage(&sarama.ConsumerMessage{Value: []byte("value")}, "basket.confirmed")
+
+	assert.Equal(t, "basket.confirmed", replayed.Topic)
+	assert.Nil(t, replayed.Key)
+}
+
+func headersToMap(headers []sarama.RecordHeader) map[string]string {
+	res := make(map[string]string, len(headers))
+	for _, header := range headers {
+		res[string(header.Key)] = string(header.Value)
+	}
+	return res
+}
@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"delivery/internal/generated/queues/basketconfirmedpb"
+	"delivery/internal/pkg/errs"
+	"encoding/binary"
+	"fmt"
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"mime"
+	"strings"
+)
+
+const HeaderContentType = "content-type"
+
+// Поддерживаемые значения заголовка content-type
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Формат Confluent Schema Registry: нулевой magic byte, 4 байта ID схемы, затем сами данные
+const (
+	wireFormatMagicByte  = 0
+	wireFormatHeaderSize = 5
+)
+
+var protobufContentTypes = map[string]bool{
+	ContentTypeProtobuf:                          true,
+	"application/protobuf":                       true,
+	"application/vnd.google.protobuf":            true,
+	"application/octet-stream":                   true,
+	"application/vnd.schemaregistry.v1+protobuf": true,
+}
+
+var jsonContentTypes = map[string]bool{
+	ContentTypeJSON:                          true,
+	"application/vnd.schemaregistry.v1+json": true,
+}
+
+// basketConfirmedDecoder разбирает событие из protobuf, protojson или формата Schema Registry.
+// Ошибки разбора постоянные: повтор не поможет, сообщение уходит в DLQ
+type basketConfirmedDecoder struct {
+	schemaRegistry SchemaRegistry
+}
+
+// newBasketConfirmedDecoder - schemaRegistry может быть nil, тогда сообщения в формате
+// Schema Registry считаются protobuf без проверки схемы
+func newBasketConfirmedDecoder(schemaRegistry SchemaRegistry) *basketConfirmedDecoder {
+	return &basketConfirmedDecoder{schemaRegistry: schemaRegistry}
+}
+
+func (d *basketConfirmedDecoder) Decode(ctx context.Context,
+	message *sarama.ConsumerMessage) (*basketconfirmedpb.BasketConfirmedIntegrationEvent, error) {
+	if message == nil {
+		return nil, errs.NewValueIsRequiredError("message")
+	}
+
+	event := &basketconfirmedpb.BasketConfirmedIntegrationEvent{}
+	// Корректное protobuf-сообщение и JSON не начинаются с нулевого байта, поэтому формат Schema Registry определяется однозначно
+	if len(message.Value) >= wireFormatHeaderSize && message.Value[0] == wireFormatMagicByte {
+		err := d.decodeWireFormat(ctx, message.Value, event)
+		if err != nil {
+			return nil, err
+		}
+		return event, nil
+	}
+
+	var err error
+	switch contentType := messageContentType(message); {
+	case protobufContentTypes[contentType]:
+		err = unmarshalProtobuf(message.Value, event)
+	case jsonContentTypes[contentType]:
+		err = unmarshalJSON(message.Value, event)
+	case contentType != "":
+		err = newPermanentError(fmt.Errorf("unsupported content type %q", contentType))
+	case looksLikeJSON(message.Value):
+		err = unmarshalJSON(message.Value, event)
+	default:
+		err = unmarshalProtobuf(message.Value, event)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (d *basketConfirmedDecoder) decodeWireFormat(ctx context.Context, value []byte,
+	event *basketconfirmedpb.BasketConfirmedIntegrationEvent) error {
+	schemaID := int(binary.BigEndian.Uint32(value[1:wireFormatHeaderSize]))
+	payload := value[wireFormatHeaderSize:]
+
+	schemaType := SchemaTypeProtobuf
+	if d.schemaRegistry != nil {
+		var err error
+		schemaType, err = d.schemaRegistry.SchemaType(ctx, schemaID)
+		if err != nil {
+			// Реестр мог быть временно недоступен, поэтому ошибка не постоянная
+			return fmt.Errorf("failed to get schema %d: %w", schemaID, err)
+		}
+	}
+
+	switch schemaType {
+	case SchemaTypeProtobuf:
+		payload, err := skipMessageIndexes(payload, event)
+		if err != nil {
+			return newPermanentError(fmt.Errorf("schema %d: %w", schemaID, err))
+		}
+		return unmarshalProtobuf(payload, event)
+	case SchemaTypeJSON:
+		return unmarshalJSON(payload, event)
+	default:
+		return newPermanentError(fmt.Errorf("schema %d has unsupported type %q", schemaID, schemaType))
+	}
+}
+
+// skipMessageIndexes пропускает путь к типу сообщения внутри .proto файла и проверяет, что это наше событие
+func skipMessageIndexes(payload []byte, event proto.Message) ([]byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 {
+		return nil, fmt.Errorf("malformed message indexes")
+	}
+	payload = payload[n:]
+
+	// Одиночный ноль - сокращённая запись пути [0], то есть первое сообщение в файле
+	indexes := []int64{0}
+	if count > 0 {
+		indexes = make([]int64, count)
+		for i := range indexes {
+			indexes[i], n = binary.Varint(payload)
+			if n <= 0 {
+				return nil, fmt.Errorf("malformed message indexes")
+			}
+			payload = payload[n:]
+		}
+	}
+
+	expected := int64(event.ProtoReflect().Descriptor().Index())
+	if len(indexes) != 1 || indexes[0] != expected {
+		return nil, fmt.Errorf("message indexes %v do not point to %s", indexes, event.ProtoReflect().Descriptor().Name())
+	}
+	return payload, nil
+}
+
+func unmarshalProtobuf(value []byte, event proto.Message) error {
+	err := proto.Unmarshal(value, event)
+	if err != nil {
+		return newPermanentError(fmt.Errorf("failed to unmarshal protobuf message: %w", err))
+	}
+	return nil
+}
+
+// unmarshalJSON разбирает канонический protojson: поля называются как в .proto контракте
+func unmarshalJSON(value []byte, event proto.Message) error {
+	err := protojson.Unmarshal(value, event)
+	if err != nil {
+		return newPermanentError(fmt.Errorf("failed to unmarshal json message: %w", err))
+	}
+	return nil
+}
+
+func messageContentType(message *sarama.ConsumerMessage) string {
+	for _, header := range message.Headers {
+		if header == nil || !strings.EqualFold(string(header.Key), HeaderContentType) {
+			continue
+		}
+		mediaType, _, err := mime.ParseMediaType(string(header.Value))
+		if err != nil {
+			return strings.ToLower(strings.TrimSpace(string(header.Value)))
+		}
+		return mediaType
+	}
+	return ""
+}
+
+func looksLikeJSON(value []byte) bool {
+	trimmed := bytes.TrimSpace(value)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
@@ -0,0 +1,197 @@
+package kafka
+
+import (
+	"context"
+	"delivery/internal/generated/queues/basketconfirmedpb"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasketConfirmedDecoder_Decode(t *testing.T) {
+	event := &basketconfirmedpb.BasketConfirmedIntegrationEvent{
+		BasketId:       "0b4b5ab1-7b3c-4c8e-9b84-2a6e3f8e2e11",
+		Address:        &basketconfirmedpb.Address{Street: "Тестовая"},
+		DeliveryPeriod: &basketconfirmedpb.DeliveryPeriod{From: 9, To: 12},
+		Volume:         7,
+	}
+	binaryValue, err := proto.Marshal(event)
+	assert.NoError(t, err)
+	jsonValue, err := protojson.Marshal(event)
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		value       []byte
+		contentType string
+	}{
+		"protobuf_by_header":      {binaryValue, "application/x-protobuf"},
+		"json_by_header":          {jsonValue, "application/json; charset=utf-8"},
+		"protobuf_without_header": {binaryValue, ""},
+		"json_without_header":     {jsonValue, ""},
+		"json_with_proto_names":   {[]byte(`{"basketId":"0b4b5ab1-7b3c-4c8e-9b84-2a6e3f8e2e11","address":{"street":"Тестовая"},"deliveryPeriod":{"from":9,"to":12},"Volume":7}`), "application/json"},
+		"schema_registry_framing": {wireFormat(1, []byte{0}, binaryValue), ""},
+	}
+
+	decoder := newBasketConfirmedDecoder(nil)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := decoder.Decode(context.Background(), newMessage(test.value, test.contentType))
+
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(event, decoded))
+		})
+	}
+}
+
+func TestBasketConfirmedDecoder_SchemaRegistry(t *testing.T) {
+	event := &basketconfirmedpb.BasketConfirmedIntegrationEvent{BasketId: "id", Volume: 3}
+	binaryValue, _ := proto.Marshal(event)
+	jsonValue, _ := protojson.Marshal(event)
+	registry := &stubSchemaRegistry{types: map[int]string{1: SchemaTypeProtobuf, 2: SchemaTypeJSON, 3: SchemaTypeAvro}}
+	decoder := newBasketConfirmedDecoder(registry)
+
+	t.Run("protobuf schema", func(t *testing.T) {
+		decoded, err := decoder.Decode(context.Background(), newMessage(wireFormat(1, []byte{0}, binaryValue), ""))
+
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(event, decoded))
+	})
+
+	t.Run("protobuf schema with explicit message indexes", func(t *testing.T) {
+		// Путь [0] записан полностью: количество индексов 1, затем индекс 0
+		indexes := binary.AppendVarint(binary.AppendVarint(nil, 1), 0)
+
+		decoded, err := decoder.Decode(context.Background(), newMessage(wireFormat(1, indexes, binaryValue), ""))
+
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(event, decoded))
+	})
+
+	t.Run("json schema", func(t *testing.T) {
+		decoded, err := decoder.Decode(context.Background(), newMessage(wireFormat(2, nil, jsonValue), ""))
+
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(event, decoded))
+	})
+
+	t.Run("avro schema is poison", func(t *testing.T) {
+		_, err := decoder.Decode(context.Background(), newMessage(wireFormat(3, nil, binaryValue), ""))
+
+		assert.True(t, isPermanent(err))
+	})
+
+	t.Run("indexes of another message are poison", func(t *testing.T) {
+		indexes := binary.AppendVarint(binary.AppendVarint(nil, 1), 2)
+
+		_, err := decoder.Decode(context.Background(), newMessage(wireFormat(1, indexes, binaryValue), ""))
+
+		assert.True(t, isPermanent(err))
+	})
+
+	t.Run("unavailable registry is retried", func(t *testing.T) {
+		registryErr := errors.New("registry is down")
+		decoder := newBasketConfirmedDecoder(&stubSchemaRegistry{err: registryErr})
+
+		_, err := decoder.Decode(context.Background(), newMessage(wireFormat(1, []byte{0}, binaryValue), ""))
+
+		assert.ErrorIs(t, err, registryErr)
+		assert.False(t, isPermanent(err))
+	})
+}
+
+func TestBasketConfirmedDecoder_Errors(t *testing.T) {
+	decoder := newBasketConfirmedDecoder(nil)
+
+	tests := map[string]struct {
+		value       []byte
+		contentType string
+	}{
+		"lowercase_volume":         {[]byte(`{"basketId":"id","volume":1}`), "application/json"},
+		"json_sent_as_protobuf":    {[]byte(`{"basketId":"id"}`), "application/x-protobuf"},
+		"unsupported_content_type": {[]byte(`<xml/>`), "application/xml"},
+		"truncated_protobuf":       {[]byte{0x0a, 0x10, 'a'}, ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := decoder.Decode(context.Background(), newMessage(test.value, test.contentType))
+
+			assert.Error(t, err)
+			assert.True(t, isPermanent(err))
+		})
+	}
+}
+
+func TestSchemaRegistryClient_SchemaType(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			_, _ = fmt.Fprint(w, `{"schema":"syntax = \"proto3\";","schemaType":"PROTOBUF"}`)
+		case "/schemas/ids/2":
+			_, _ = fmt.Fprint(w, `{"schema":"{}"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"error_code":40403,"message":"Schema not found"}`)
+		}
+	}))
+	defer server.Close()
+
+	registry, err := NewSchemaRegistryClient(server.URL + "/")
+	assert.NoError(t, err)
+
+	schemaType, err := registry.SchemaType(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaTypeProtobuf, schemaType)
+
+	// Повторный запрос отвечается из кеша
+	_, _ = registry.SchemaType(context.Background(), 1)
+	assert.Equal(t, 1, requests)
+
+	schemaType, err = registry.SchemaType(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaTypeAvro, schemaType)
+
+	_, err = registry.SchemaType(context.Background(), 404)
+	assert.True(t, isPermanent(err))
+}
+
+func newMessage(value []byte, contentType string) *sarama.ConsumerMessage {
+	message := &sarama.ConsumerMessage{Value: value}
+	if contentType != "" {
+		message.Headers = []*sarama.RecordHeader{{Key: []byte("Content-Type"), Value: []byte(contentType)}}
+	}
+	return message
+}
+
+func wireFormat(schemaID uint32, indexes []byte, payload []byte) []byte {
+	res := []byte{wireFormatMagicByte}
+	res = binary.BigEndian.AppendUint32(res, schemaID)
+	res = append(res, indexes...)
+	return append(res, payload...)
+}
+
+// stubSchemaRegistry - локальный реестр схем для тестов
+type stubSchemaRegistry struct {
+	types map[int]string
+	err   error
+}
+
+func (r *stubSchemaRegistry) SchemaType(_ context.Context, schemaID int) (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	schemaType, ok := r.types[schemaID]
+	if !ok {
+		return "", newPermanentError(fmt.Errorf("schema %d not found", schemaID))
+	}
+	return schemaType, nil
+}
@@ -0,0 +1,87 @@
+package kafka
+
+import (
+	"delivery/internal/pkg/errs"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = 200 * time.Millisecond
+	DefaultMaxBackoff     = 5 * time.Second
+)
+
+// RetryPolicy - сколько раз и с какими паузами повторять обработку сообщения при временной ошибке
+type RetryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func NewRetryPolicy(maxAttempts int, initialBackoff time.Duration, maxBackoff time.Duration) (RetryPolicy, error) {
+	if maxAttempts < 1 {
+		return RetryPolicy{}, errs.NewValueIsOutOfRangeError("maxAttempts", maxAttempts, 1, nil)
+	}
+	if initialBackoff < 0 {
+		return RetryPolicy{}, errs.NewValueIsOutOfRangeError("initialBackoff", initialBackoff, 0, nil)
+	}
+	if maxBackoff < initialBackoff {
+		return RetryPolicy{}, errs.NewValueIsOutOfRangeError("maxBackoff", maxBackoff, initialBackoff, nil)
+	}
+
+	return RetryPolicy{
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+	}, nil
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+	}
+}
+
+func (p RetryPolicy) MaxAttempts() int {
+	return p.maxAttempts
+}
+
+// Backoff - пауза перед повтором после attempt-й неудачной попытки, удваивается до maxBackoff
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.initialBackoff
+	for i := 1; i < attempt && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.maxBackoff)
+}
+
+// permanentError - ошибка, которую повтор не исправит: битое сообщение или невалидные данные
+type permanentError struct {
+	cause error
+}
+
+func newPermanentError(cause error) error {
+	return &permanentError{cause: cause}
+}
+
+func (e *permanentError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.cause
+}
+
+// isPermanent - повторять бесполезно: сообщение сразу уходит в DLQ
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+	return errors.Is(err, errs.ErrValueIsRequired) ||
+		errors.Is(err, errs.ErrValueIsInvalid) ||
+		errors.Is(err, errs.ErrValueIsOutOfRange)
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Типы схем Confluent Schema Registry. Пустой schemaType в ответе реестра означает AVRO
+const (
+	SchemaTypeProtobuf = "PROTOBUF"
+	SchemaTypeJSON     = "JSON"
+	SchemaTypeAvro     = "AVRO"
+)
+
+// SchemaRegistry отвечает, в каком формате записаны данные по ID схемы из сообщения
+type SchemaRegistry interface {
+	SchemaType(ctx context.Context, schemaID int) (string, error)
+}
+
+var _ SchemaRegistry = &schemaRegistryClient{}
+
+// schemaRegistryClient ходит в REST API реестра. Схема по ID неизменна, поэтому ответы кешируются без срока
+type schemaRegistryClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	types map[int]string
+}
+
+func NewSchemaRegistryClient(baseURL string) (SchemaRegistry, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		return nil, errs.NewValueIsRequiredError("baseURL")
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, errs.NewValueIsInvalidErrorWithCause("baseURL", err)
+	}
+
+	return &schemaRegistryClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		types:      make(map[int]string),
+	}, nil
+}
+
+func (c *schemaRegistryClient) SchemaType(ctx context.Context, schemaID int) (string, error) {
+	c.mu.RLock()
+	schemaType, ok := c.types[schemaID]
+	c.mu.RUnlock()
+	if ok {
+		return schemaType, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", c.baseURL, schemaID), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", newPermanentError(errs.NewObjectNotFoundError("schemaID", schemaID))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("schema registry responded with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		SchemaType string `json:"schemaType"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	schemaType = body.SchemaType
+	if schemaType == "" {
+		schemaType = SchemaTypeAvro
+	}
+
+	c.mu.Lock()
+	c.types[schemaID] = schemaType
+	c.mu.Unlock()
+	return schemaType, nil
+}
@@ -0,0 +1,104 @@
+package citymap
+
+import (
+	"bufio"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Load читает карту непроходимых клеток из файла.
+// Формат: по одной записи в строке, "x,y" - одна клетка, "x1,y1-x2,y2" - прямоугольник клеток.
+// Пустые строки и всё после "#" игнорируются
+func Load(path string, grid kernel.Grid) (kernel.CityMap, error) {
+	if path == "" {
+		return kernel.CityMap{}, errs.NewValueIsRequiredError("path")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return kernel.CityMap{}, err
+	}
+	defer file.Close()
+
+	return Parse(file, grid)
+}
+
+func Parse(r io.Reader, grid kernel.Grid) (kernel.CityMap, error) {
+	if r == nil {
+		return kernel.CityMap{}, errs.NewValueIsRequiredError("reader")
+	}
+	if grid.IsEmpty() {
+		return kernel.CityMap{}, errs.NewValueIsRequiredError("grid")
+	}
+
+	var blocked []kernel.Location
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		cells, err := parseLine(line, grid)
+		if err != nil {
+			return kernel.CityMap{}, fmt.Errorf("city map line %d: %w", lineNumber, err)
+		}
+		blocked = append(blocked, cells...)
+	}
+	if err := scanner.Err(); err != nil {
+		return kernel.CityMap{}, err
+	}
+
+	return kernel.NewCityMap(grid, blocked)
+}
+
+func parseLine(line string, grid kernel.Grid) ([]kernel.Location, error) {
+	fromPart, toPart, isRange := strings.Cut(line, "-")
+	fromX, fromY, err := parsePoint(fromPart)
+	if err != nil {
+		return nil, err
+	}
+	toX, toY := fromX, fromY
+	if isRange {
+		toX, toY, err = parsePoint(toPart)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !grid.Contains(fromX, fromY) || !grid.Contains(toX, toY) {
+		return nil, errs.NewValueIsOutOfRangeError("cell", line,
+			"1,1", fmt.Sprintf("%d,%d", grid.Width(), grid.Height()))
+	}
+
+	var cells []kernel.Location
+	for x := min(fromX, toX); x <= max(fromX, toX); x++ {
+		for y := min(fromY, toY); y <= max(fromY, toY); y++ {
+			cells = append(cells, kernel.RestoreLocation(x, y))
+		}
+	}
+	return cells, nil
+}
+
+func parsePoint(value string) (int, int, error) {
+	xPart, yPart, ok := strings.Cut(strings.TrimSpace(value), ",")
+	if !ok {
+		return 0, 0, errs.NewValueIsInvalidError("cell")
+	}
+	x, err := strconv.Atoi(strings.TrimSpace(xPart))
+	if err != nil {
+		return 0, 0, errs.NewValueIsInvalidErrorWithCause("x", err)
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(yPart))
+	if err != nil {
+		return 0, 0, errs.NewValueIsInvalidErrorWithCause("y", err)
+	}
+	return x, y, nil
+}
@@ -0,0 +1,66 @@
+package citymap
+
+import (
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("cells, ranges and comments", func(t *testing.T) {
+		input := `
+# река
+5,1-5,3
+8, 8 # пешеходная зона
+`
+		cityMap, err := Parse(strings.NewReader(input), kernel.DefaultGrid())
+
+		assert.NoError(t, err)
+		for _, cell := range []kernel.Location{
+			kernel.RestoreLocation(5, 1), kernel.RestoreLocation(5, 2),
+			kernel.RestoreLocation(5, 3), kernel.RestoreLocation(8, 8),
+		} {
+			assert.False(t, cityMap.IsPassable(cell))
+		}
+		assert.True(t, cityMap.IsPassable(kernel.RestoreLocation(5, 4)))
+	})
+
+	t.Run("empty input gives open map", func(t *testing.T) {
+		cityMap, err := Parse(strings.NewReader(""), kernel.DefaultGrid())
+
+		assert.NoError(t, err)
+		assert.False(t, cityMap.HasObstacles())
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("1;1"), kernel.DefaultGrid())
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+		assert.ErrorContains(t, err, "line 1")
+	})
+
+	t.Run("cell outside of grid", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("1,1-11,1"), kernel.DefaultGrid())
+
+		assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("example file", func(t *testing.T) {
+		cityMap, err := Load("../../../../configs/city_map.example.txt", kernel.DefaultGrid())
+
+		assert.NoError(t, err)
+		assert.False(t, cityMap.IsPassable(kernel.RestoreLocation(5, 1)))
+		assert.True(t, cityMap.IsPassable(kernel.RestoreLocation(5, 5)))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Load("does-not-exist.txt", kernel.DefaultGrid())
+
+		assert.Error(t, err)
+	})
+}
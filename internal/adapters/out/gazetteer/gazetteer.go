@@ -0,0 +1,121 @@
+package gazetteer
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Entry - улица из справочника адресов и её координаты на карте города
+type Entry struct {
+	Country string
+	City    string
+	Street  string
+	X       int
+	Y       int
+}
+
+var _ ports.GeoLocationGateway = &gazetteer{}
+
+// gazetteer определяет координаты по локальному справочнику без обращения к сервису Geo.
+// Названия сравниваются нечётко: без учёта регистра, типа улицы и алфавита, с допуском на опечатки
+type gazetteer struct {
+	entries []entry
+}
+
+type entry struct {
+	country  string
+	city     string
+	street   string
+	location kernel.Location
+}
+
+// NewGazetteer проверяет координаты справочника по границам карты города
+func NewGazetteer(grid kernel.Grid, entries []Entry) (ports.GeoLocationGateway, error) {
+	if grid.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("grid")
+	}
+	if len(entries) == 0 {
+		return nil, errs.NewValueIsRequiredError("entries")
+	}
+
+	normalized := make([]entry, 0, len(entries))
+	for i, e := range entries {
+		street := normalizeStreet(e.Street)
+		if street == "" {
+			return nil, fmt.Errorf("gazetteer entry %d: %w", i+1, errs.NewValueIsRequiredError("street"))
+		}
+		location, err := kernel.NewLocation(grid, e.X, e.Y)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer entry %d: %w", i+1, err)
+		}
+		normalized = append(normalized, entry{
+			country:  normalize(e.Country),
+			city:     normalize(e.City),
+			street:   street,
+			location: location,
+		})
+	}
+
+	return &gazetteer{entries: normalized}, nil
+}
+
+func (g *gazetteer) DefineLocation(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	if address.IsEmpty() {
+		return kernel.Location{}, errs.NewValueIsRequiredError("address")
+	}
+	if err := ctx.Err(); err != nil {
+		return kernel.Location{}, err
+	}
+
+	country := normalize(address.Country())
+	city := normalize(address.City())
+	street := normalizeStreet(address.Street())
+	tolerance := allowedDistance(street)
+
+	var (
+		best      *entry
+		bestScore = tolerance + 1
+		ambiguous bool
+	)
+	for i := range g.entries {
+		candidate := &g.entries[i]
+		if !similarOrUnknown(country, candidate.country) || !similarOrUnknown(city, candidate.city) {
+			continue
+		}
+
+		score := editDistance(street, candidate.street)
+		switch {
+		case score < bestScore:
+			best, bestScore, ambiguous = candidate, score, false
+		case score == bestScore && best != nil && !best.location.Equals(candidate.location):
+			ambiguous = true
+		}
+	}
+
+	if best == nil {
+		return kernel.Location{}, errs.NewValueIsInvalidErrorWithCause("address",
+			fmt.Errorf("%q is not found in gazetteer", address.String()))
+	}
+	if ambiguous {
+		return kernel.Location{}, errs.NewValueIsInvalidErrorWithCause("address",
+			fmt.Errorf("%q matches several places, specify the city", address.String()))
+	}
+	return best.location, nil
+}
+
+// allowedDistance - сколько опечаток прощается: одна на каждые четыре символа, в коротких названиях - ни одной
+func allowedDistance(value string) int {
+	return utf8.RuneCountInString(value) / 4
+}
+
+// similarOrUnknown - часть адреса не сужает поиск, если она не указана в запросе или в справочнике
+func similarOrUnknown(requested string, known string) bool {
+	if requested == "" || known == "" {
+		return true
+	}
+	return editDistance(requested, known) <= allowedDistance(known)
+}
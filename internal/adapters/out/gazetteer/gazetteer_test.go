@@ -0,0 +1,152 @@
+package gazetteer
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCSV = `country,city,street,x,y
+# центр
+Россия,Москва,ул. Ленина,2,3
+Россия,Казань,Ленина,7,8
+Россия,Москва,Проспект Мира,4,4
+,,Тестовая,1,1
+`
+
+func TestGazetteer_DefineLocation(t *testing.T) {
+	gateway, err := ParseCSV(strings.NewReader(testCSV), kernel.DefaultGrid())
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		country, city, street string
+		expected              kernel.Location
+	}{
+		"exact":                {"Россия", "Москва", "ул. Ленина", kernel.RestoreLocation(2, 3)},
+		"city disambiguates":   {"Россия", "Казань", "Ленина", kernel.RestoreLocation(7, 8)},
+		"street type ignored":  {"", "Москва", "Ленина улица", kernel.RestoreLocation(2, 3)},
+		"transliteration":      {"Rossiya", "Moskva", "Mira prospekt", kernel.RestoreLocation(4, 4)},
+		"typo":                 {"", "Москва", "Ленена", kernel.RestoreLocation(2, 3)},
+		"typo in city":         {"", "Казнь", "Ленина", kernel.RestoreLocation(7, 8)},
+		"case and punctuation": {"", "", "ТЕСТОВАЯ!", kernel.RestoreLocation(1, 1)},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			location, err := gateway.DefineLocation(context.Background(),
+				createTestAddress(t, test.country, test.city, test.street))
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, location)
+		})
+	}
+
+	t.Run("same street in several cities is ambiguous", func(t *testing.T) {
+		_, err := gateway.DefineLocation(context.Background(), createTestAddress(t, "", "", "Ленина"))
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+	})
+
+	t.Run("unknown street", func(t *testing.T) {
+		_, err := gateway.DefineLocation(context.Background(), createTestAddress(t, "", "Москва", "Садовая"))
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+	})
+
+	t.Run("empty address", func(t *testing.T) {
+		_, err := gateway.DefineLocation(context.Background(), kernel.Address{})
+
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		path := writeTestFile(t, "gazetteer.json", `[{"city": "Москва", "street": "Ленина", "x": 2, "y": 3}]`)
+
+		gateway, err := Load(path, kernel.DefaultGrid())
+
+		assert.NoError(t, err)
+		assertLocation(t, gateway, "Lenina", kernel.RestoreLocation(2, 3))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		path := writeTestFile(t, "gazetteer.csv", testCSV)
+
+		gateway, err := Load(path, kernel.DefaultGrid())
+
+		assert.NoError(t, err)
+		assertLocation(t, gateway, "Тестовая", kernel.RestoreLocation(1, 1))
+	})
+
+	t.Run("example file", func(t *testing.T) {
+		gateway, err := Load("../../../../configs/gazetteer.example.csv", kernel.DefaultGrid())
+
+		assert.NoError(t, err)
+		assertLocation(t, gateway, "Тестировочная", kernel.RestoreLocation(1, 1))
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		path := writeTestFile(t, "gazetteer.txt", testCSV)
+
+		_, err := Load(path, kernel.DefaultGrid())
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+	})
+}
+
+func TestParseCSV_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected error
+	}{
+		"empty":              {"", errs.ErrValueIsRequired},
+		"no street column":   {"city,x,y\nМосква,1,1", errs.ErrValueIsRequired},
+		"no entries":         {"street,x,y\n", errs.ErrValueIsRequired},
+		"bad coordinate":     {"street,x,y\nЛенина,a,1", errs.ErrValueIsInvalid},
+		"outside of grid":    {"street,x,y\nЛенина,100,1", errs.ErrValueIsOutOfRange},
+		"empty street value": {"street,x,y\n,1,1", errs.ErrValueIsRequired},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(test.input), kernel.DefaultGrid())
+
+			assert.ErrorIs(t, err, test.expected)
+		})
+	}
+}
+
+func TestNormalizeStreet(t *testing.T) {
+	assert.Equal(t, "lenina", normalizeStreet("ул. Ленина"))
+	assert.Equal(t, "lenina", normalizeStreet("Lenina Street"))
+	assert.Equal(t, "shchorsa", normalizeStreet("улица Щорса"))
+	assert.Equal(t, "bulvar", normalizeStreet("Бульвар"))
+	assert.Equal(t, 1, editDistance("lenina", "lenena"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
+
+func createTestAddress(t *testing.T, country string, city string, street string) kernel.Address {
+	address, err := kernel.NewAddress(country, city, street, "", "")
+	assert.NoError(t, err)
+	return address
+}
+
+func assertLocation(t *testing.T, gateway ports.GeoLocationGateway, street string, expected kernel.Location) {
+	location, err := gateway.DefineLocation(context.Background(), createTestAddress(t, "", "", street))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, location)
+}
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
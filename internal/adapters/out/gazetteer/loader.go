@@ -0,0 +1,140 @@
+package gazetteer
+
+import (
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Load читает справочник адресов из файла. Формат определяется по расширению: .csv или .json
+func Load(path string, grid kernel.Grid) (ports.GeoLocationGateway, error) {
+	if path == "" {
+		return nil, errs.NewValueIsRequiredError("path")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(file, grid)
+	case ".json":
+		return ParseJSON(file, grid)
+	default:
+		return nil, errs.NewValueIsInvalidErrorWithCause("path",
+			fmt.Errorf("unsupported gazetteer format %q, expected .csv or .json", filepath.Ext(path)))
+	}
+}
+
+// ParseCSV - первая строка заголовок с колонками country, city, street, x, y в любом порядке.
+// Страна и город необязательны, строки, начинающиеся с "#", игнорируются
+func ParseCSV(r io.Reader, grid kernel.Grid) (ports.GeoLocationGateway, error) {
+	if r == nil {
+		return nil, errs.NewValueIsRequiredError("reader")
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errs.NewValueIsRequiredError("entries")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"street", "x", "y"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errs.NewValueIsRequiredError("column " + required)
+		}
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		e, err := parseRecord(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return NewGazetteer(grid, entries)
+}
+
+func parseRecord(record []string, columns map[string]int) (Entry, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	x, err := strconv.Atoi(field("x"))
+	if err != nil {
+		return Entry{}, errs.NewValueIsInvalidErrorWithCause("x", err)
+	}
+	y, err := strconv.Atoi(field("y"))
+	if err != nil {
+		return Entry{}, errs.NewValueIsInvalidErrorWithCause("y", err)
+	}
+
+	return Entry{
+		Country: field("country"),
+		City:    field("city"),
+		Street:  field("street"),
+		X:       x,
+		Y:       y,
+	}, nil
+}
+
+// ParseJSON - массив объектов с полями country, city, street, x, y
+func ParseJSON(r io.Reader, grid kernel.Grid) (ports.GeoLocationGateway, error) {
+	if r == nil {
+		return nil, errs.NewValueIsRequiredError("reader")
+	}
+
+	var records []struct {
+		Country string `json:"country"`
+		City    string `json:"city"`
+		Street  string `json:"street"`
+		X       int    `json:"x"`
+		Y       int    `json:"y"`
+	}
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, errs.NewValueIsInvalidErrorWithCause("gazetteer", err)
+	}
+
+	entries := make([]Entry, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, Entry(record))
+	}
+	return NewGazetteer(grid, entries)
+}
@@ -0,0 +1,82 @@
+package gazetteer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliteration - кириллица в латиницу по упрощённой схеме, чтобы "Lenina" и "Ленина" совпадали
+var transliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// streetTypes - типы улиц после транслитерации, на поиск они не влияют: "ул. Ленина" и "Lenina street" - одна улица
+var streetTypes = map[string]struct{}{
+	"ul": {}, "ulitsa": {}, "ulica": {}, "street": {}, "st": {},
+	"pr": {}, "prt": {}, "prosp": {}, "prospekt": {}, "avenue": {}, "ave": {},
+	"per": {}, "pereulok": {}, "lane": {},
+	"pl": {}, "ploshchad": {}, "square": {}, "sq": {},
+	"bul": {}, "bulvar": {}, "boulevard": {}, "blvd": {},
+	"nab": {}, "naberezhnaya": {}, "embankment": {},
+	"sh": {}, "shosse": {}, "highway": {},
+}
+
+// normalize приводит название к виду для сравнения: нижний регистр, латиница, без знаков препинания
+func normalize(value string) string {
+	var transliterated strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if latin, ok := transliteration[r]; ok {
+			transliterated.WriteString(latin)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			transliterated.WriteRune(r)
+			continue
+		}
+		transliterated.WriteRune(' ')
+	}
+	return strings.Join(strings.Fields(transliterated.String()), " ")
+}
+
+// normalizeStreet дополнительно убирает тип улицы
+func normalizeStreet(value string) string {
+	words := strings.Fields(normalize(value))
+	significant := make([]string, 0, len(words))
+	for _, word := range words {
+		if _, isType := streetTypes[word]; !isType {
+			significant = append(significant, word)
+		}
+	}
+	// Улица может называться одним типом, например "Бульвар"
+	if len(significant) == 0 {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(significant, " ")
+}
+
+// editDistance - расстояние Левенштейна по символам
+func editDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			substitution := previous[j-1]
+			if ar[i-1] != br[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(br)]
+}
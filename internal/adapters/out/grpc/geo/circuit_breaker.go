@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("geo service circuit breaker is open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker размыкается после threshold сбоев подряд. Через openTimeout пропускает
+// один пробный вызов: успех замыкает цепь, сбой снова размыкает
+type circuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration, now func() time.Time) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		now:         now,
+	}
+}
+
+// allow - можно ли сейчас делать вызов
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		return nil
+	case breakerHalfOpen:
+		// Пробный вызов уже идёт, остальные ждут его результата
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+func (b *circuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+func (b *circuitBreaker) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release снимает пробный вызов, который прервал сам вызывающий: следующий вызов снова станет пробным
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
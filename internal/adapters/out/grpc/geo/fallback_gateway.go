@@ -0,0 +1,36 @@
+package geo
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"errors"
+)
+
+var _ ports.GeoLocationGateway = &fallbackGateway{}
+
+type fallbackGateway struct {
+	primary  ports.GeoLocationGateway
+	fallback ports.GeoLocationGateway
+}
+
+// NewFallbackGateway - пока размыкатель основного шлюза открыт, координаты определяет запасной.
+// Остальные ошибки основного шлюза возвращаются как есть
+func NewFallbackGateway(primary ports.GeoLocationGateway, fallback ports.GeoLocationGateway) (ports.GeoLocationGateway, error) {
+	if primary == nil {
+		return nil, errs.NewValueIsRequiredError("primary")
+	}
+	if fallback == nil {
+		return nil, errs.NewValueIsRequiredError("fallback")
+	}
+	return &fallbackGateway{primary: primary, fallback: fallback}, nil
+}
+
+func (g *fallbackGateway) DefineLocation(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	location, err := g.primary.DefineLocation(ctx, address)
+	if errors.Is(err, ErrCircuitOpen) {
+		return g.fallback.DefineLocation(ctx, address)
+	}
+	return location, err
+}
@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFallbackGateway_DefineLocation(t *testing.T) {
+	t.Run("use fallback while circuit is open", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(errUnavailable))
+		config := testResilienceConfig()
+		config.MaxAttempts = 1
+		config.FailureThreshold = 1
+		primary, _ := newTestGateway(t, client, config)
+		fallback := &stubGateway{location: kernel.RestoreLocation(9, 9)}
+		gateway, err := NewFallbackGateway(primary, fallback)
+		assert.NoError(t, err)
+
+		_, err = gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		location, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.NoError(t, err)
+		assert.Equal(t, kernel.RestoreLocation(9, 9), location)
+		assert.Equal(t, 1, fallback.calls)
+	})
+
+	t.Run("do not hide other errors", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(status.Error(codes.NotFound, "no such street")))
+		primary, _ := newTestGateway(t, client, testResilienceConfig())
+		fallback := &stubGateway{location: kernel.RestoreLocation(9, 9)}
+		gateway, _ := NewFallbackGateway(primary, fallback)
+
+		_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "???"))
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+		assert.Equal(t, 0, fallback.calls)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := NewFallbackGateway(nil, &stubGateway{})
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+
+		_, err = NewFallbackGateway(&stubGateway{}, nil)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+type stubGateway struct {
+	location kernel.Location
+	calls    int
+}
+
+func (s *stubGateway) DefineLocation(context.Context, kernel.Address) (kernel.Location, error) {
+	s.calls++
+	return s.location, nil
+}
@@ -5,37 +5,61 @@ import (
 	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/core/ports"
 	"delivery/internal/generated/clients/geosrv/geopb"
+	"delivery/internal/generated/clients/geosrv/geopbv2"
 	"delivery/internal/pkg/errs"
+	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
+	"google.golang.org/grpc/status"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+const DefaultTimeout = 5 * time.Second
+
 var _ ports.GeoLocationGateway = &geoLocationService{}
 
+// geoLocationService спрашивает координаты по полному адресу (контракт v2). Если Geo ещё
+// не умеет v2, клиент один раз это замечает и дальше ходит по v1 только с улицей
 type geoLocationService struct {
-	conn    *grpc.ClientConn
-	client  geopb.GeoClient
-	timeout time.Duration
+	conn     *grpc.ClientConn
+	client   geopb.GeoClient
+	clientV2 geopbv2.GeoClient
+	timeout  time.Duration
+	grid     kernel.Grid
+
+	v2Unsupported atomic.Bool
 }
 
-func NewGeoLocationService(host string) (*geoLocationService, error) {
+// NewGeoLocationService - timeout ограничивает один вызов Geo, но не продлевает дедлайн входящего контекста.
+// Координаты из ответа проверяются по границам grid. opts дополняют настройки соединения
+// (например, для подключения к тестовому серверу)
+func NewGeoLocationService(host string, timeout time.Duration, grid kernel.Grid, opts ...grpc.DialOption) (*geoLocationService, error) {
 	if host == "" {
 		return nil, errs.NewValueIsRequiredError("host")
 	}
+	if timeout <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("timeout", timeout, "1ns", nil)
+	}
+	if grid.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("grid")
+	}
 
-	conn, err := grpc.NewClient(host, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.NewClient(host, dialOpts...)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to create geo client: %w", err)
 	}
 
-	pbClient := geopb.NewGeoClient(conn)
-
 	return &geoLocationService{
-		conn:    conn,
-		client:  pbClient,
-		timeout: 5 * time.Second,
+		conn:     conn,
+		client:   geopb.NewGeoClient(conn),
+		clientV2: geopbv2.NewGeoClient(conn),
+		timeout:  timeout,
+		grid:     grid,
 	}, nil
 }
 
@@ -43,24 +67,90 @@ func (g *geoLocationService) Close() error {
 	return g.conn.Close()
 }
 
-func (g *geoLocationService) DefineLocation(ctx context.Context, street string) (kernel.Location, error) {
-	// Формируем запрос
-	req := &geopb.GetGeolocationRequest{
-		Street: street,
+// CheckHealth проверяет соединение с Geo. Простаивающее соединение устанавливается заново,
+// пока соединение устанавливается, проверка ждёт его до дедлайна ctx
+func (g *geoLocationService) CheckHealth(ctx context.Context) error {
+	for {
+		state := g.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("geo connection is closed")
+		case connectivity.Idle:
+			g.conn.Connect()
+		case connectivity.TransientFailure:
+			return fmt.Errorf("geo %s is unreachable", g.conn.Target())
+		}
+		if !g.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("geo connection is %s: %w", strings.ToLower(state.String()), ctx.Err())
+		}
+	}
+}
+
+func (g *geoLocationService) DefineLocation(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	if address.IsEmpty() {
+		return kernel.Location{}, errs.NewValueIsRequiredError("address")
 	}
 
-	// Делаем запрос
-	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	// Делаем запрос в пределах дедлайна вызывающего
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
 	defer cancel()
-	resp, err := g.client.GetGeolocation(ctx, req)
+
+	if !g.v2Unsupported.Load() {
+		location, err := g.defineLocationV2(ctx, address)
+		if status.Code(err) != codes.Unimplemented {
+			return location, err
+		}
+		g.v2Unsupported.Store(true)
+	}
+	return g.defineLocationV1(ctx, address)
+}
+
+func (g *geoLocationService) defineLocationV2(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	req := &geopbv2.GetGeolocationRequest{
+		Address: &geopbv2.Address{
+			Country:   address.Country(),
+			City:      address.City(),
+			Street:    address.Street(),
+			House:     address.House(),
+			Apartment: address.Apartment(),
+		},
+	}
+	resp, err := g.clientV2.GetGeolocation(ctx, req)
 	if err != nil {
-		return kernel.Location{}, err
+		if status.Code(err) == codes.Unimplemented {
+			return kernel.Location{}, err
+		}
+		return kernel.Location{}, mapError(address, err)
 	}
+	if resp.GetLocation() == nil {
+		return kernel.Location{}, errs.NewValueIsRequiredError("location")
+	}
+	return kernel.NewLocation(g.grid, int(resp.Location.X), int(resp.Location.Y))
+}
 
-	// Создаем и возвращаем VO Geo
-	location, err := kernel.NewLocation(uint8(resp.Location.X), uint8(resp.Location.Y))
+// defineLocationV1 - прежний контракт знает только улицу
+func (g *geoLocationService) defineLocationV1(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	req := &geopb.GetGeolocationRequest{
+		Street: address.Street(),
+	}
+	resp, err := g.client.GetGeolocation(ctx, req)
 	if err != nil {
-		return kernel.Location{}, err
+		return kernel.Location{}, mapError(address, err)
+	}
+	if resp.GetLocation() == nil {
+		return kernel.Location{}, errs.NewValueIsRequiredError("location")
+	}
+	return kernel.NewLocation(g.grid, int(resp.Location.X), int(resp.Location.Y))
+}
+
+// mapError - адрес, который Geo не смог разобрать, не станет корректным при повторе
+func mapError(address kernel.Address, err error) error {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound:
+		return errs.NewValueIsInvalidErrorWithCause("address", fmt.Errorf("%q: %w", address.String(), err))
+	default:
+		return err
 	}
-	return location, nil
 }
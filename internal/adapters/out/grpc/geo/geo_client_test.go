@@ -0,0 +1,176 @@
+package geo
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/generated/clients/geosrv/geopb"
+	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
+	"delivery/internal/pkg/tracing/tracingtest"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGeoLocationService_CheckHealth(t *testing.T) {
+	t.Run("connect to geo", func(t *testing.T) {
+		_, client := startGeoServer(t, time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		assert.NoError(t, client.CheckHealth(ctx))
+	})
+
+	t.Run("closed connection", func(t *testing.T) {
+		_, client := startGeoServer(t, time.Second)
+		_ = client.Close()
+
+		assert.Error(t, client.CheckHealth(context.Background()))
+	})
+
+	t.Run("unreachable geo", func(t *testing.T) {
+		client, err := NewGeoLocationService("passthrough:///unreachable", time.Second, kernel.DefaultGrid(),
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return nil, errors.New("connection refused")
+			}))
+		assert.NoError(t, err)
+		defer client.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		assert.Error(t, client.CheckHealth(ctx))
+	})
+}
+
+func TestGeoLocationService_Tracing(t *testing.T) {
+	exporter := tracingtest.Use(t)
+	fake := &fakeGeoServer{}
+	var traceParent []string
+	fake.respondWith(func(ctx context.Context, _ string) (*geopb.GetGeolocationReply, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		traceParent = md.Get("traceparent")
+		return &geopb.GetGeolocationReply{Location: &geopb.Location{X: 1, Y: 1}}, nil
+	})
+	client := startServer(t, time.Second, func(server *grpc.Server) {
+		geopb.RegisterGeoServer(server, fake)
+	}, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "CreateOrder")
+	_, err := client.DefineLocation(ctx, streetAddress(t, "Тестовая"))
+	parent.End()
+
+	assert.NoError(t, err)
+	span, ok := tracingtest.SpanByName(exporter, "geo.Geo/GetGeolocation")
+	assert.True(t, ok)
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+	// Geo получает контекст трассы и может её продолжить
+	assert.Len(t, traceParent, 1)
+	assert.Contains(t, traceParent[0], span.SpanContext.TraceID().String())
+}
+
+func TestGeoLocationService_DefineLocation(t *testing.T) {
+	t.Run("return location from geo service", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(reply(3, 7))
+
+		location, err := client.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, location.X())
+		assert.Equal(t, 7, location.Y())
+	})
+
+	t.Run("unknown street is invalid value", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(status.Error(codes.NotFound, "street not found")))
+
+		_, err := client.DefineLocation(context.Background(), streetAddress(t, "Несуществующая"))
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+	})
+
+	t.Run("timeout limits a single call", func(t *testing.T) {
+		fake, client := startGeoServer(t, 50*time.Millisecond)
+		fake.respondWith(slowReply())
+
+		_, err := client.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+
+	t.Run("caller deadline is respected", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Minute)
+		fake.respondWith(slowReply())
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+		_, err := client.DefineLocation(ctx, streetAddress(t, "Тестовая"))
+
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Less(t, time.Since(started), 5*time.Second)
+	})
+
+	t.Run("send full address to geo v2", func(t *testing.T) {
+		fake, fakeV2, client := startGeoServerV2(t, 4, 9)
+		address, err := kernel.NewAddress("Россия", "Казань", "Ленина", "1", "10")
+		assert.NoError(t, err)
+
+		location, err := client.DefineLocation(context.Background(), address)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, location.X())
+		assert.Equal(t, 9, location.Y())
+		assert.Equal(t, "Казань", fakeV2.requestedAddress().GetCity())
+		assert.Equal(t, "Ленина", fakeV2.requestedAddress().GetStreet())
+		assert.Equal(t, "10", fakeV2.requestedAddress().GetApartment())
+		assert.Equal(t, int32(0), fake.calls.Load())
+	})
+
+	t.Run("fall back to v1 when geo does not know v2", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(reply(2, 5))
+
+		for range 2 {
+			location, err := client.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+			assert.NoError(t, err)
+			assert.Equal(t, 2, location.X())
+		}
+		assert.True(t, client.v2Unsupported.Load())
+		assert.Equal(t, int32(2), fake.calls.Load())
+	})
+
+	t.Run("empty address", func(t *testing.T) {
+		_, client := startGeoServer(t, time.Second)
+
+		_, err := client.DefineLocation(context.Background(), kernel.Address{})
+
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := NewGeoLocationService("", time.Second, kernel.DefaultGrid())
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+
+		_, err = NewGeoLocationService("localhost:5004", 0, kernel.DefaultGrid())
+		assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+	})
+}
+
+// slowReply отвечает только после отмены запроса клиентом
+func slowReply() func(context.Context, string) (*geopb.GetGeolocationReply, error) {
+	return func(ctx context.Context, _ string) (*geopb.GetGeolocationReply, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
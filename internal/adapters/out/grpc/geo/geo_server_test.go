@@ -0,0 +1,126 @@
+package geo
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/generated/clients/geosrv/geopb"
+	"delivery/internal/generated/clients/geosrv/geopbv2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeGeoServer - сервис Geo в памяти процесса, отвечает заданными ответами по очереди
+type fakeGeoServer struct {
+	geopb.UnimplementedGeoServer
+
+	mu        sync.Mutex
+	responses []func(ctx context.Context, street string) (*geopb.GetGeolocationReply, error)
+	calls     atomic.Int32
+}
+
+func (s *fakeGeoServer) GetGeolocation(ctx context.Context, req *geopb.GetGeolocationRequest) (*geopb.GetGeolocationReply, error) {
+	s.calls.Add(1)
+
+	s.mu.Lock()
+	respond := s.responses[0]
+	if len(s.responses) > 1 {
+		s.responses = s.responses[1:]
+	}
+	s.mu.Unlock()
+
+	return respond(ctx, req.Street)
+}
+
+func (s *fakeGeoServer) respondWith(responses ...func(ctx context.Context, street string) (*geopb.GetGeolocationReply, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = responses
+}
+
+func reply(x int32, y int32) func(context.Context, string) (*geopb.GetGeolocationReply, error) {
+	return func(context.Context, string) (*geopb.GetGeolocationReply, error) {
+		return &geopb.GetGeolocationReply{Location: &geopb.Location{X: x, Y: y}}, nil
+	}
+}
+
+func fail(err error) func(context.Context, string) (*geopb.GetGeolocationReply, error) {
+	return func(context.Context, string) (*geopb.GetGeolocationReply, error) {
+		return nil, err
+	}
+}
+
+// fakeGeoServerV2 - Geo, который уже умеет искать по полному адресу, запоминает последний запрос
+type fakeGeoServerV2 struct {
+	geopbv2.UnimplementedGeoServer
+
+	mu          sync.Mutex
+	lastAddress *geopbv2.Address
+	location    *geopbv2.Location
+}
+
+func (s *fakeGeoServerV2) GetGeolocation(_ context.Context, req *geopbv2.GetGeolocationRequest) (*geopbv2.GetGeolocationReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastAddress = req.GetAddress()
+	return &geopbv2.GetGeolocationReply{Location: s.location}, nil
+}
+
+func (s *fakeGeoServerV2) requestedAddress() *geopbv2.Address {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastAddress
+}
+
+// startGeoServer поднимает на bufconn Geo, который знает только контракт v1, и возвращает клиента, подключённого к нему
+func startGeoServer(t *testing.T, timeout time.Duration) (*fakeGeoServer, *geoLocationService) {
+	fake := &fakeGeoServer{}
+	fake.respondWith(reply(1, 1))
+	client := startServer(t, timeout, func(server *grpc.Server) {
+		geopb.RegisterGeoServer(server, fake)
+	})
+	return fake, client
+}
+
+// startGeoServerV2 поднимает Geo с обеими версиями контракта
+func startGeoServerV2(t *testing.T, x int32, y int32) (*fakeGeoServer, *fakeGeoServerV2, *geoLocationService) {
+	fake := &fakeGeoServer{}
+	fake.respondWith(reply(1, 1))
+	fakeV2 := &fakeGeoServerV2{location: &geopbv2.Location{X: x, Y: y}}
+	client := startServer(t, time.Second, func(server *grpc.Server) {
+		geopb.RegisterGeoServer(server, fake)
+		geopbv2.RegisterGeoServer(server, fakeV2)
+	})
+	return fake, fakeV2, client
+}
+
+func startServer(t *testing.T, timeout time.Duration, register func(*grpc.Server),
+	opts ...grpc.DialOption) *geoLocationService {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		})}, opts...)
+	client, err := NewGeoLocationService("passthrough:///bufnet", timeout, kernel.DefaultGrid(), dialOpts...)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func streetAddress(t *testing.T, street string) kernel.Address {
+	address, err := kernel.NewAddress("", "", street, "", "")
+	assert.NoError(t, err)
+	return address
+}
@@ -0,0 +1,75 @@
+package geo
+
+import (
+	"container/list"
+	"delivery/internal/core/domain/model/kernel"
+	"sync"
+	"time"
+)
+
+// locationCache - LRU кеш "адрес -> координаты" с ограниченным временем жизни записи
+type locationCache struct {
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[kernel.Address]*list.Element
+}
+
+type cacheEntry struct {
+	address   kernel.Address
+	location  kernel.Location
+	expiresAt time.Time
+}
+
+func newLocationCache(capacity int, ttl time.Duration, now func() time.Time) *locationCache {
+	return &locationCache{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      now,
+		order:    list.New(),
+		entries:  make(map[kernel.Address]*list.Element, capacity),
+	}
+}
+
+func (c *locationCache) get(address kernel.Address) (kernel.Location, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[address]
+	if !ok {
+		return kernel.Location{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, address)
+		return kernel.Location{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.location, true
+}
+
+func (c *locationCache) put(address kernel.Address, location kernel.Location) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[address]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.location = location
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[address] = c.order.PushFront(&cacheEntry{address: address, location: location, expiresAt: expiresAt})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).address)
+	}
+}
@@ -0,0 +1,171 @@
+package geo
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResilienceConfig - настройки повторов, размыкателя и кеша вокруг сервиса Geo
+type ResilienceConfig struct {
+	MaxAttempts      int
+	InitialBackoff   time.Duration
+	MaxBackoff       time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	CacheSize        int
+	CacheTTL         time.Duration
+}
+
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		MaxAttempts:      3,
+		InitialBackoff:   100 * time.Millisecond,
+		MaxBackoff:       time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      10 * time.Second,
+		CacheSize:        1000,
+		CacheTTL:         time.Hour,
+	}
+}
+
+var _ ports.GeoLocationGateway = &resilientGateway{}
+
+type resilientGateway struct {
+	next    ports.GeoLocationGateway
+	config  ResilienceConfig
+	breaker *circuitBreaker
+	cache   *locationCache
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewResilientGateway оборачивает шлюз Geo: ответы кешируются, Unavailable повторяется
+// с jitter, а при серии сбоев размыкатель сразу возвращает ErrCircuitOpen
+func NewResilientGateway(next ports.GeoLocationGateway, config ResilienceConfig) (ports.GeoLocationGateway, error) {
+	return newResilientGateway(next, config, time.Now)
+}
+
+func newResilientGateway(next ports.GeoLocationGateway, config ResilienceConfig,
+	now func() time.Time) (*resilientGateway, error) {
+	if next == nil {
+		return nil, errs.NewValueIsRequiredError("next")
+	}
+	if config.MaxAttempts < 1 {
+		return nil, errs.NewValueIsOutOfRangeError("MaxAttempts", config.MaxAttempts, 1, nil)
+	}
+	if config.InitialBackoff < 0 || config.MaxBackoff < config.InitialBackoff {
+		return nil, errs.NewValueIsOutOfRangeError("MaxBackoff", config.MaxBackoff, config.InitialBackoff, nil)
+	}
+	if config.FailureThreshold < 1 {
+		return nil, errs.NewValueIsOutOfRangeError("FailureThreshold", config.FailureThreshold, 1, nil)
+	}
+	if config.CacheSize < 1 {
+		return nil, errs.NewValueIsOutOfRangeError("CacheSize", config.CacheSize, 1, nil)
+	}
+	if config.CacheTTL <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("CacheTTL", config.CacheTTL, "1ns", nil)
+	}
+
+	return &resilientGateway{
+		next:    next,
+		config:  config,
+		breaker: newCircuitBreaker(config.FailureThreshold, config.OpenTimeout, now),
+		cache:   newLocationCache(config.CacheSize, config.CacheTTL, now),
+		sleep:   sleep,
+	}, nil
+}
+
+func (g *resilientGateway) DefineLocation(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	if location, ok := g.cache.get(address); ok {
+		return location, nil
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var location kernel.Location
+		location, err = g.call(ctx, address)
+		if err == nil {
+			g.cache.put(address, location)
+			return location, nil
+		}
+		if attempt >= g.config.MaxAttempts || status.Code(err) != codes.Unavailable {
+			return kernel.Location{}, err
+		}
+
+		if sleepErr := g.sleep(ctx, g.backoff(attempt)); sleepErr != nil {
+			return kernel.Location{}, err
+		}
+	}
+}
+
+// call делает один вызов через размыкатель
+func (g *resilientGateway) call(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	if err := g.breaker.allow(); err != nil {
+		return kernel.Location{}, err
+	}
+
+	location, err := g.next.DefineLocation(ctx, address)
+	switch {
+	case err == nil:
+		g.breaker.onSuccess()
+	case isServiceFailure(err):
+		if ctx.Err() != nil {
+			// Дедлайн истёк или вызов отменили на нашей стороне - о здоровье Geo это ничего не говорит
+			g.breaker.release()
+		} else {
+			g.breaker.onFailure()
+		}
+	default:
+		// Geo ответил, пусть и ошибкой про сам адрес - сервис жив
+		g.breaker.onSuccess()
+	}
+	return location, err
+}
+
+// backoff - "полный jitter": случайная пауза от нуля до экспоненциально растущего предела
+func (g *resilientGateway) backoff(attempt int) time.Duration {
+	limit := g.config.InitialBackoff
+	for i := 1; i < attempt && limit < g.config.MaxBackoff; i++ {
+		limit *= 2
+	}
+	limit = min(limit, g.config.MaxBackoff)
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit + 1)
+}
+
+// isServiceFailure - ошибка говорит о недоступности или перегрузке Geo, а не о данных запроса
+func isServiceFailure(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	grpcStatus, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch grpcStatus.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,229 @@
+package geo
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUnavailable = status.Error(codes.Unavailable, "geo is down")
+
+func TestResilientGateway_Retry(t *testing.T) {
+	t.Run("retry unavailable until success", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(errUnavailable), fail(errUnavailable), reply(2, 5))
+		gateway, _ := newTestGateway(t, client, testResilienceConfig())
+
+		location, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, location.X())
+		assert.Equal(t, int32(3), fake.calls.Load())
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(errUnavailable))
+		gateway, _ := newTestGateway(t, client, testResilienceConfig())
+
+		_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, int32(3), fake.calls.Load())
+	})
+
+	t.Run("do not retry invalid street", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(status.Error(codes.InvalidArgument, "bad street")))
+		gateway, _ := newTestGateway(t, client, testResilienceConfig())
+
+		_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "???"))
+
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+		assert.Equal(t, int32(1), fake.calls.Load())
+	})
+
+	t.Run("stop retrying when caller gives up", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(errUnavailable))
+		config := testResilienceConfig()
+		config.InitialBackoff, config.MaxBackoff = time.Hour, time.Hour
+		gateway, _ := newTestGateway(t, client, config)
+		gateway.sleep = sleep
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := gateway.DefineLocation(ctx, streetAddress(t, "Тестовая"))
+
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, int32(1), fake.calls.Load())
+	})
+}
+
+func TestResilientGateway_CircuitBreaker(t *testing.T) {
+	t.Run("open after consecutive failures and recover after timeout", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(errUnavailable))
+		config := testResilienceConfig()
+		config.MaxAttempts = 1
+		config.FailureThreshold = 2
+		gateway, clock := newTestGateway(t, client, config)
+
+		for range 2 {
+			_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+		}
+
+		_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), fake.calls.Load())
+
+		// После паузы пробный вызов проходит и замыкает цепь
+		fake.respondWith(reply(4, 4))
+		clock.advance(config.OpenTimeout)
+
+		location, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.NoError(t, err)
+		assert.Equal(t, 4, location.X())
+	})
+
+	t.Run("failed probe opens circuit again", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(errUnavailable))
+		config := testResilienceConfig()
+		config.MaxAttempts = 1
+		config.FailureThreshold = 1
+		gateway, clock := newTestGateway(t, client, config)
+
+		_, _ = gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		clock.advance(config.OpenTimeout)
+		_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		_, err = gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), fake.calls.Load())
+	})
+
+	t.Run("invalid street does not open circuit", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(status.Error(codes.NotFound, "no such street")))
+		config := testResilienceConfig()
+		config.FailureThreshold = 1
+		gateway, _ := newTestGateway(t, client, config)
+
+		for range 3 {
+			_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "???"))
+			assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+		}
+		assert.Equal(t, int32(3), fake.calls.Load())
+	})
+}
+
+func TestResilientGateway_Cache(t *testing.T) {
+	t.Run("serve repeated street from cache until ttl expires", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(reply(1, 2))
+		gateway, clock := newTestGateway(t, client, testResilienceConfig())
+
+		_, _ = gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		location, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, location.Y())
+		assert.Equal(t, int32(1), fake.calls.Load())
+
+		clock.advance(testResilienceConfig().CacheTTL)
+		_, _ = gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.Equal(t, int32(2), fake.calls.Load())
+	})
+
+	t.Run("evict least recently used street", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(reply(1, 1))
+		config := testResilienceConfig()
+		config.CacheSize = 2
+		gateway, _ := newTestGateway(t, client, config)
+
+		for _, street := range []string{"Первая", "Вторая", "Первая", "Третья", "Первая"} {
+			_, err := gateway.DefineLocation(context.Background(), streetAddress(t, street))
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(3), fake.calls.Load())
+
+		// "Вторая" вытеснена как давно не использованная
+		_, _ = gateway.DefineLocation(context.Background(), streetAddress(t, "Вторая"))
+		assert.Equal(t, int32(4), fake.calls.Load())
+	})
+
+	t.Run("same street in different cities is not shared", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		gateway, _ := newTestGateway(t, client, testResilienceConfig())
+
+		for _, city := range []string{"Москва", "Казань", "Москва"} {
+			address, err := kernel.NewAddress("Россия", city, "Ленина", "1", "")
+			assert.NoError(t, err)
+			_, err = gateway.DefineLocation(context.Background(), address)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), fake.calls.Load())
+	})
+
+	t.Run("do not cache errors", func(t *testing.T) {
+		fake, client := startGeoServer(t, time.Second)
+		fake.respondWith(fail(status.Error(codes.InvalidArgument, "bad street")), reply(1, 1))
+		gateway, _ := newTestGateway(t, client, testResilienceConfig())
+
+		_, err := gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.Error(t, err)
+		_, err = gateway.DefineLocation(context.Background(), streetAddress(t, "Тестовая"))
+		assert.NoError(t, err)
+	})
+}
+
+func TestNewResilientGateway_InvalidConfig(t *testing.T) {
+	_, err := NewResilientGateway(nil, DefaultResilienceConfig())
+	assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+
+	config := DefaultResilienceConfig()
+	config.CacheSize = 0
+	_, err = NewResilientGateway(&geoLocationService{}, config)
+	assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+}
+
+func testResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		MaxAttempts:      3,
+		InitialBackoff:   time.Millisecond,
+		MaxBackoff:       time.Millisecond,
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute,
+		CacheSize:        10,
+		CacheTTL:         time.Hour,
+	}
+}
+
+func newTestGateway(t *testing.T, client *geoLocationService, config ResilienceConfig) (*resilientGateway, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	gateway, err := newResilientGateway(client, config, clock.Now)
+	assert.NoError(t, err)
+	gateway.sleep = func(context.Context, time.Duration) error { return nil }
+	return gateway, clock
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
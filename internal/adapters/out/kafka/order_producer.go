@@ -0,0 +1,155 @@
+package kafka
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/ports"
+	"delivery/internal/generated/queues/orderstatuschangedpb"
+	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
+	"fmt"
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/proto"
+	"time"
+)
+
+// sendTimeout ограничивает отправку одного события, даже если контекст вызова без дедлайна
+const sendTimeout = 10 * time.Second
+
+var _ ports.OrderProducer = &orderProducer{}
+
+type orderProducer struct {
+	topic    string
+	producer sarama.AsyncProducer
+	done     chan struct{}
+}
+
+func NewOrderProducer(brokers []string, topic string) (ports.OrderProducer, error) {
+	if brokers == nil || len(brokers) == 0 {
+		return nil, errs.NewValueIsRequiredError("brokers")
+	}
+	if topic == "" {
+		return nil, errs.NewValueIsRequiredError("topic")
+	}
+
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Version = sarama.V3_4_0_0
+	saramaCfg.Producer.RequiredAcks = sarama.WaitForAll
+	saramaCfg.Producer.Return.Successes = true
+	saramaCfg.Producer.Idempotent = true
+	saramaCfg.Producer.Return.Errors = true
+	saramaCfg.Producer.Timeout = sendTimeout
+	saramaCfg.Net.MaxOpenRequests = 1
+	saramaCfg.Net.DialTimeout = sendTimeout
+	saramaCfg.Net.ReadTimeout = sendTimeout
+	saramaCfg.Net.WriteTimeout = sendTimeout
+
+	producer, err := sarama.NewAsyncProducer(brokers, saramaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create async producer: %w", err)
+	}
+
+	p := &orderProducer{
+		topic:    topic,
+		producer: producer,
+		done:     make(chan struct{}),
+	}
+	go p.dispatchResults()
+	return p, nil
+}
+
+// Close дожидается отправки принятых сообщений; их результаты получают ещё ждущие вызовы Publish
+func (p *orderProducer) Close() error {
+	p.producer.AsyncClose()
+	<-p.done
+	return nil
+}
+
+// dispatchResults возвращает результат отправки тому вызову Publish, который передал сообщение
+func (p *orderProducer) dispatchResults() {
+	defer close(p.done)
+	successes, errors := p.producer.Successes(), p.producer.Errors()
+	for successes != nil || errors != nil {
+		select {
+		case message, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			message.Metadata.(chan error) <- nil
+		case producerErr, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+			producerErr.Msg.Metadata.(chan error) <- producerErr.Err
+		}
+	}
+}
+
+func (p *orderProducer) Publish(ctx context.Context, domainEvent order.StatusChangedDomainEvent) error {
+	integrationEvent, err := p.mapDomainEventToIntegrationEvent(domainEvent)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := proto.Marshal(integrationEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal integration event: %w", err)
+	}
+
+	// Буфер на один результат: dispatchResults не блокируется, если Publish уже перестал ждать
+	result := make(chan error, 1)
+	message := &sarama.ProducerMessage{
+		Topic:    p.topic,
+		Key:      sarama.StringEncoder(domainEvent.OrderID.String()),
+		Value:    sarama.ByteEncoder(bytes),
+		Metadata: result,
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	// Потребитель события продолжит трассу, в которой изменился заказ
+	_, span := tracing.StartProducerSpan(ctx, message)
+	span.SetAttributes(tracing.OrderID(domainEvent.OrderID))
+	err = p.send(ctx, message, result)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// send ждёт подтверждения брокера не дольше, чем живёт контекст.
+// Неподтверждённое сообщение останется в outbox, и следующий запуск отправит его повторно
+func (p *orderProducer) send(ctx context.Context, message *sarama.ProducerMessage, result <-chan error) error {
+	select {
+	case p.producer.Input() <- message:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send message to kafka: %w", ctx.Err())
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("failed to send message to kafka: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send message to kafka: %w", ctx.Err())
+	}
+}
+
+func (p *orderProducer) mapDomainEventToIntegrationEvent(
+	domainEvent order.StatusChangedDomainEvent) (*orderstatuschangedpb.OrderStatusChangedIntegrationEvent, error) {
+	status, ok := orderstatuschangedpb.OrderStatus_value[domainEvent.OrderStatus.String()]
+	if !ok {
+		return nil, errs.NewValueIsOutOfRangeError("orderStatus", domainEvent.OrderStatus, nil, nil)
+	}
+
+	return &orderstatuschangedpb.OrderStatusChangedIntegrationEvent{
+		OrderId:     domainEvent.OrderID.String(),
+		OrderStatus: orderstatuschangedpb.OrderStatus(status),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Результаты запроса координат
+const (
+	geoResultOk          = "ok"
+	geoResultInvalid     = "invalid"
+	geoResultUnavailable = "unavailable"
+	geoResultError       = "error"
+)
+
+var _ ports.GeoLocationGateway = &geoGateway{}
+
+type geoGateway struct {
+	next     ports.GeoLocationGateway
+	provider string
+	metrics  *Metrics
+}
+
+// NewGeoGateway замеряет каждый запрос координат к источнику provider (grpc, local)
+func NewGeoGateway(next ports.GeoLocationGateway, provider string, metrics *Metrics) (ports.GeoLocationGateway, error) {
+	if next == nil {
+		return nil, errs.NewValueIsRequiredError("next")
+	}
+	if provider == "" {
+		return nil, errs.NewValueIsRequiredError("provider")
+	}
+	if metrics == nil {
+		return nil, errs.NewValueIsRequiredError("metrics")
+	}
+	return &geoGateway{next: next, provider: provider, metrics: metrics}, nil
+}
+
+func (g *geoGateway) DefineLocation(ctx context.Context, address kernel.Address) (kernel.Location, error) {
+	started := time.Now()
+	location, err := g.next.DefineLocation(ctx, address)
+	g.metrics.geoDuration.WithLabelValues(g.provider, geoResult(err)).Observe(time.Since(started).Seconds())
+	return location, err
+}
+
+func geoResult(err error) string {
+	switch {
+	case err == nil:
+		return geoResultOk
+	case errors.Is(err, errs.ErrValueIsInvalid), errors.Is(err, errs.ErrValueIsRequired):
+		return geoResultInvalid
+	case errors.Is(err, context.DeadlineExceeded), status.Code(err) == codes.Unavailable,
+		status.Code(err) == codes.DeadlineExceeded:
+		return geoResultUnavailable
+	default:
+		return geoResultError
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"delivery/internal/pkg/errs"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var _ cron.Job = &job{}
+
+type job struct {
+	name    string
+	next    cron.Job
+	metrics *Metrics
+}
+
+// NewJob замеряет длительность каждого запуска задания
+func NewJob(name string, next cron.Job, metrics *Metrics) (cron.Job, error) {
+	if name == "" {
+		return nil, errs.NewValueIsRequiredError("name")
+	}
+	if next == nil {
+		return nil, errs.NewValueIsRequiredError("next")
+	}
+	if metrics == nil {
+		return nil, errs.NewValueIsRequiredError("metrics")
+	}
+	return &job{name: name, next: next, metrics: metrics}, nil
+}
+
+func (j *job) Run() {
+	started := time.Now()
+	defer func() {
+		j.metrics.jobDuration.WithLabelValues(j.name).Observe(time.Since(started).Seconds())
+	}()
+	j.next.Run()
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"delivery/internal/pkg/errs"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "delivery"
+
+// durationBuckets - границы гистограмм задержки назначения и длительности доставки, в секундах
+var durationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+
+// Metrics - реестр метрик сервиса. Сценарии о нём не знают: метрики снимают декораторы
+// портов и заданий, репозиторий заказов сообщает о переходах, а число заказов и курьеров
+// считается в БД при каждом опросе
+type Metrics struct {
+	registry *prometheus.Registry
+
+	assignmentLatency prometheus.Histogram
+	deliveryDuration  prometheus.Histogram
+	geoDuration       *prometheus.HistogramVec
+	jobDuration       *prometheus.HistogramVec
+	kafkaMessages     *prometheus.CounterVec
+	kafkaConsumerLag  *prometheus.GaugeVec
+}
+
+func NewMetrics(db *gorm.DB) (*Metrics, error) {
+	if db == nil {
+		return nil, errs.NewValueIsRequiredError("db")
+	}
+
+	m := newMetrics()
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newStateCollector(db),
+	)
+	return m, nil
+}
+
+// newMetrics - метрики, которые ведёт сам сервис, без состояния из БД
+func newMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		assignmentLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "order",
+			Name:      "assignment_latency_seconds",
+			Help:      "Время от создания заказа до назначения курьера",
+			Buckets:   durationBuckets,
+		}),
+		deliveryDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "order",
+			Name:      "delivery_duration_seconds",
+			Help:      "Время от назначения курьера до доставки",
+			Buckets:   durationBuckets,
+		}),
+		geoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "geo",
+			Name:      "request_duration_seconds",
+			Help:      "Длительность одного запроса координат по источнику и результату",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider", "result"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "duration_seconds",
+			Help:      "Длительность одного запуска фонового задания",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"job"}),
+		kafkaMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "consumed_messages_total",
+			Help:      "Прочитанные сообщения по результату обработки",
+		}, []string{"topic", "result"}),
+		kafkaConsumerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "consumer_lag",
+			Help:      "Сколько сообщений в партиции ещё не прочитано консьюмером",
+		}, []string{"topic", "partition"}),
+	}
+
+	m.registry.MustRegister(m.assignmentLatency, m.deliveryDuration, m.geoDuration, m.jobDuration,
+		m.kafkaMessages, m.kafkaConsumerLag)
+	return m
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// OrderAssigned замеряет, сколько заказ ждал курьера
+func (m *Metrics) OrderAssigned(latency time.Duration) {
+	m.assignmentLatency.Observe(latency.Seconds())
+}
+
+// OrderCompleted замеряет, сколько курьер вёз заказ
+func (m *Metrics) OrderCompleted(duration time.Duration) {
+	m.deliveryDuration.Observe(duration.Seconds())
+}
+
+// MessageReceived запоминает отставание консьюмера на момент чтения сообщения
+func (m *Metrics) MessageReceived(topic string, partition int32, lag int64) {
+	m.kafkaConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+// MessageHandled считает сообщение с итогом обработки: processed, retried, dead_lettered или failed
+func (m *Metrics) MessageHandled(topic string, result string) {
+	m.kafkaMessages.WithLabelValues(topic, result).Inc()
+}
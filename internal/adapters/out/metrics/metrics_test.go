@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGeoGateway_DefineLocation(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		result string
+	}{
+		"success":       {nil, geoResultOk},
+		"invalid":       {errs.NewValueIsInvalidError("address"), geoResultInvalid},
+		"unavailable":   {status.Error(codes.Unavailable, "down"), geoResultUnavailable},
+		"deadline":      {context.DeadlineExceeded, geoResultUnavailable},
+		"unknown error": {errors.New("boom"), geoResultError},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newMetrics()
+			gateway, err := NewGeoGateway(&stubGateway{err: test.err}, "grpc", m)
+			assert.NoError(t, err)
+
+			_, err = gateway.DefineLocation(context.Background(), kernel.Address{})
+
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, 1, testutil.CollectAndCount(m.geoDuration))
+			assert.Equal(t, uint64(1), histogramCount(t, m.geoDuration.WithLabelValues("grpc", test.result)))
+		})
+	}
+}
+
+func TestJob_Run(t *testing.T) {
+	m := newMetrics()
+	next := &stubJob{}
+	job, err := NewJob("assign_orders", next, m)
+	assert.NoError(t, err)
+
+	job.Run()
+	job.Run()
+
+	assert.Equal(t, 2, next.runs)
+	assert.Equal(t, uint64(2), histogramCount(t, m.jobDuration.WithLabelValues("assign_orders")))
+}
+
+func TestMetrics_Kafka(t *testing.T) {
+	m := newMetrics()
+
+	m.MessageReceived("basket.confirmed", 0, 10)
+	m.MessageReceived("basket.confirmed", 0, 3)
+	m.MessageHandled("basket.confirmed", "processed")
+	m.MessageHandled("basket.confirmed", "processed")
+	m.MessageHandled("basket.confirmed", "dead_lettered")
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.kafkaConsumerLag.WithLabelValues("basket.confirmed", "0")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.kafkaMessages.WithLabelValues("basket.confirmed", "processed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.kafkaMessages.WithLabelValues("basket.confirmed", "dead_lettered")))
+}
+
+func TestMetrics_OrderTransitions(t *testing.T) {
+	m := newMetrics()
+
+	m.OrderAssigned(3 * time.Second)
+	m.OrderAssigned(20 * time.Second)
+	m.OrderCompleted(10 * time.Minute)
+
+	assert.Equal(t, uint64(2), histogramCount(t, m.assignmentLatency))
+	assert.Equal(t, uint64(1), histogramCount(t, m.deliveryDuration))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := newMetrics()
+	m.MessageHandled("basket.confirmed", "processed")
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, strings.Contains(string(body),
+		`delivery_kafka_consumed_messages_total{result="processed",topic="basket.confirmed"} 1`))
+}
+
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	metric, ok := observer.(prometheus.Metric)
+	assert.True(t, ok)
+	var written dto.Metric
+	assert.NoError(t, metric.Write(&written))
+	return written.GetHistogram().GetSampleCount()
+}
+
+type stubGateway struct {
+	err error
+}
+
+func (s *stubGateway) DefineLocation(context.Context, kernel.Address) (kernel.Location, error) {
+	return kernel.Location{}, s.err
+}
+
+type stubJob struct {
+	runs int
+}
+
+func (s *stubJob) Run() {
+	s.runs++
+}
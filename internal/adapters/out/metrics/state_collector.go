@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// collectTimeout - опрос не должен держать соединение с БД дольше, чем Prometheus ждёт ответа
+const collectTimeout = 5 * time.Second
+
+var _ prometheus.Collector = &stateCollector{}
+
+// stateCollector при каждом опросе считает заказы и курьеров в БД. Здесь только подсчёты по индексам,
+// а время назначения и доставки замеряется в момент перехода (см. Metrics.OrderAssigned)
+type stateCollector struct {
+	db *gorm.DB
+
+	orders        *prometheus.Desc
+	couriers      *prometheus.Desc
+	storageVolume *prometheus.Desc
+	storageUsage  *prometheus.Desc
+}
+
+func newStateCollector(db *gorm.DB) *stateCollector {
+	return &stateCollector{
+		db: db,
+		orders: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "orders"),
+			"Заказы по статусу", []string{"status"}, nil),
+		couriers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "couriers"),
+			"Курьеры по статусу смены и занятости", []string{"status", "busy"}, nil),
+		storageVolume: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage", "volume"),
+			"Суммарный объём мест хранения курьеров: total - всего, occupied - занято заказами", []string{"state"}, nil),
+		storageUsage: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage", "utilization_ratio"),
+			"Доля занятого объёма мест хранения", nil, nil),
+	}
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.orders
+	ch <- c.couriers
+	ch <- c.storageVolume
+	ch <- c.storageUsage
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	db := c.db.WithContext(ctx)
+
+	c.collectOrders(db, ch)
+	c.collectCouriers(db, ch)
+	c.collectStorage(db, ch)
+}
+
+func (c *stateCollector) collectOrders(db *gorm.DB, ch chan<- prometheus.Metric) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := db.Raw(`SELECT status, count(*) AS count FROM orders GROUP BY status`).Scan(&rows).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.orders, err)
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(c.orders, prometheus.GaugeValue, float64(row.Count), row.Status)
+	}
+}
+
+func (c *stateCollector) collectCouriers(db *gorm.DB, ch chan<- prometheus.Metric) {
+	var rows []struct {
+		Status string
+		Busy   bool
+		Count  int64
+	}
+	err := db.Raw(`SELECT c.status,
+			EXISTS (SELECT 1 FROM storage_places sp WHERE sp.courier_id = c.id AND sp.order_id IS NOT NULL) AS busy,
+			count(*) AS count
+		FROM couriers c
+		GROUP BY 1, 2`).Scan(&rows).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.couriers, err)
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(c.couriers, prometheus.GaugeValue, float64(row.Count),
+			row.Status, strconv.FormatBool(row.Busy))
+	}
+}
+
+func (c *stateCollector) collectStorage(db *gorm.DB, ch chan<- prometheus.Metric) {
+	var row struct {
+		Total    int64
+		Occupied int64
+	}
+	err := db.Raw(`SELECT coalesce(sum(sp.total_volume), 0) AS total, coalesce(sum(o.volume), 0) AS occupied
+		FROM storage_places sp
+		LEFT JOIN orders o ON o.id = sp.order_id`).Scan(&row).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.storageVolume, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.storageVolume, prometheus.GaugeValue, float64(row.Total), "total")
+	ch <- prometheus.MustNewConstMetric(c.storageVolume, prometheus.GaugeValue, float64(row.Occupied), "occupied")
+	usage := 0.0
+	if row.Total > 0 {
+		usage = float64(row.Occupied) / float64(row.Total)
+	}
+	ch <- prometheus.MustNewConstMetric(c.storageUsage, prometheus.GaugeValue, usage)
+}
@@ -4,12 +4,15 @@ import (
 	"delivery/internal/adapters/out/postgres/courierrepo"
 	"delivery/internal/adapters/out/postgres/shared"
 	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/core/domain/model/order"
 	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm/clause"
 	"testing"
+	"time"
 )
 
 func Test_CourierRepository_Add(t *testing.T) {
@@ -19,7 +22,7 @@ func Test_CourierRepository_Add(t *testing.T) {
 		repo := createCourierRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		expected, err := courier.NewCourier("test", 5, location)
+		expected, err := courier.NewCourier("test", createTestTransport(t, db, 5), location)
 		assert.NoError(t, err)
 
 		err = repo.Add(ctx, expected)
@@ -31,7 +34,7 @@ func Test_CourierRepository_Add(t *testing.T) {
 
 		assert.Equal(t, expected.ID(), result.ID)
 		assert.Equal(t, expected.Name(), result.Name)
-		assert.Equal(t, expected.Speed(), result.Speed)
+		assert.Equal(t, expected.Transport().ID(), result.TransportID)
 		assert.Equal(t, expected.Location().X(), result.Location.X)
 		assert.Equal(t, expected.Location().Y(), result.Location.Y)
 	})
@@ -42,7 +45,7 @@ func Test_CourierRepository_Add(t *testing.T) {
 		repo := createCourierRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		expected, err := courier.NewCourier("test", 5, location)
+		expected, err := courier.NewCourier("test", createTestTransport(t, db, 5), location)
 		assert.NoError(t, err)
 		err = expected.AddStoragePlace("Bag", 5)
 		assert.NoError(t, err)
@@ -67,7 +70,7 @@ func Test_CourierRepository_Update(t *testing.T) {
 		repo := createCourierRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		old, err := courier.NewCourier("test", 5, location)
+		old, err := courier.NewCourier("test", createTestTransport(t, db, 5), location)
 		assert.NoError(t, err)
 
 		err = db.Create(courierrepo.DomainToDTO(old)).Error
@@ -87,6 +90,58 @@ func Test_CourierRepository_Update(t *testing.T) {
 		assert.Equal(t, len(old.StoragePlaces()[0].ID()), len(old.StoragePlaces()[0].ID()))
 		assert.Equal(t, old.StoragePlaces()[0].Name(), result.StoragePlaces[0].Name)
 		assert.Equal(t, old.StoragePlaces()[0].TotalVolume(), result.StoragePlaces[0].TotalVolume)
+		assert.Equal(t, int64(1), result.Version)
+	})
+
+	t.Run("Delete removed storage places", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createCourierRepository(t, tx)
+
+		created, err := courier.NewCourier("test", createTestTransport(t, db, 5), createTestLocation(t, 1, 1))
+		assert.NoError(t, err)
+		_ = created.AddStoragePlace("Bag", 5)
+		_ = created.AddStoragePlace("Trunk", 20)
+		err = repo.Add(ctx, created)
+		assert.NoError(t, err)
+
+		err = created.RemoveStoragePlace(created.StoragePlaces()[0].ID())
+		assert.NoError(t, err)
+		err = repo.Update(ctx, created)
+		assert.NoError(t, err)
+
+		var storagePlaces []courierrepo.StoragePlaceDTO
+		err = db.Where("courier_id = ?", created.ID()).Find(&storagePlaces).Error
+		assert.NoError(t, err)
+		assert.Len(t, storagePlaces, 1)
+		assert.Equal(t, "Trunk", storagePlaces[0].Name)
+	})
+
+	t.Run("Return version conflict if courier was changed concurrently", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createCourierRepository(t, tx)
+
+		created, err := courier.NewCourier("test", createTestTransport(t, db, 5), createTestLocation(t, 1, 1))
+		assert.NoError(t, err)
+		err = repo.Add(ctx, created)
+		assert.NoError(t, err)
+
+		first, err := repo.Get(ctx, created.ID())
+		assert.NoError(t, err)
+		second, err := repo.Get(ctx, created.ID())
+		assert.NoError(t, err)
+
+		err = first.MoveAlongPath([]kernel.Location{createTestLocation(t, 2, 1)})
+		assert.NoError(t, err)
+		err = repo.Update(ctx, first)
+		assert.NoError(t, err)
+
+		err = second.MoveAlongPath([]kernel.Location{createTestLocation(t, 1, 2)})
+		assert.NoError(t, err)
+		err = repo.Update(ctx, second)
+		assert.ErrorIs(t, err, errs.ErrVersionIsInvalid)
+		assert.False(t, tx.InTx())
 	})
 }
 
@@ -97,7 +152,7 @@ func Test_CourierRepository_GetByID(t *testing.T) {
 		repo := createCourierRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		expected, err := courier.NewCourier("test", 5, location)
+		expected, err := courier.NewCourier("test", createTestTransport(t, db, 5), location)
 		assert.NoError(t, err)
 
 		err = db.Create(courierrepo.DomainToDTO(expected)).Error
@@ -107,43 +162,174 @@ func Test_CourierRepository_GetByID(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, expected.ID(), result.ID())
+		assert.True(t, expected.Transport().Equals(result.Transport()))
+		assert.Equal(t, expected.Speed(), result.Speed())
 	})
 }
 
 func Test_CourierRepository_GetAllFree(t *testing.T) {
-	t.Run("Return all free couriers", func(t *testing.T) {
+	t.Run("Return all on-duty couriers with free capacity", func(t *testing.T) {
 		ctx, db := setupTest(t)
 		tx := createTxManager(t, db)
 		repo := createCourierRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		first, _ := courier.NewCourier("test", 5, location)
-		second, _ := courier.NewCourier("test", 6, location)
+		first, _ := courier.NewCourier("test", createTestTransport(t, db, 5), location)
+		_ = first.StartShift()
+		_ = first.AddStoragePlace("Bag", 5)
 
-		newOrder, _ := order.NewOrder(uuid.New(), createTestLocation(t, 10, 10), 5)
-		third, _ := courier.NewCourier("test", 7, location)
-		_ = third.AddStoragePlace("Bag", 5)
-		_ = third.TakeOrder(newOrder)
+		offDuty, _ := courier.NewCourier("test", createTestTransport(t, db, 5), location)
+		_ = offDuty.AddStoragePlace("Bag", 5)
+		_ = offDuty.EndShift()
+
+		withoutStorage, _ := courier.NewCourier("test", createTestTransport(t, db, 6), location)
+
+		busy, _ := courier.NewCourier("test", createTestTransport(t, db, 7), location)
+		_ = busy.StartShift()
+		_ = busy.AddStoragePlace("Bag", 5)
+		_ = busy.TakeOrder(createTestOrder(t, 10, 10))
+
+		partlyBusy, _ := courier.NewCourier("test", createTestTransport(t, db, 8), location)
+		_ = partlyBusy.StartShift()
+		_ = partlyBusy.AddStoragePlace("Bag", 5)
+		_ = partlyBusy.AddStoragePlace("Trunk", 5)
+		_ = partlyBusy.TakeOrder(createTestOrder(t, 10, 10))
 
 		db.Create(courierrepo.DomainToDTO(first)).
-			Create(courierrepo.DomainToDTO(second)).
-			Create(courierrepo.DomainToDTO(third))
+			Create(courierrepo.DomainToDTO(offDuty)).
+			Create(courierrepo.DomainToDTO(withoutStorage)).
+			Create(courierrepo.DomainToDTO(busy)).
+			Create(courierrepo.DomainToDTO(partlyBusy))
 
 		result, err := repo.GetAllFree(ctx)
 		assert.NoError(t, err)
 
-		assert.Len(t, result, 2)
-		notContainsBusyCourier := true
+		var ids []uuid.UUID
 		for _, c := range result {
-			if c.ID() == third.ID() {
-				notContainsBusyCourier = false
-			}
+			ids = append(ids, c.ID())
 		}
+		assert.ElementsMatch(t, []uuid.UUID{first.ID(), partlyBusy.ID()}, ids)
+	})
+}
+
+func Test_CourierRepository_Route(t *testing.T) {
+	t.Run("Persist route order and return couriers on route", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createCourierRepository(t, tx)
 
-		assert.True(t, notContainsBusyCourier)
+		onRoute, _ := courier.NewCourier("test", createTestTransport(t, db, 5), createTestLocation(t, 1, 1))
+		_ = onRoute.StartShift()
+		_ = onRoute.AddStoragePlace("Bag", 5)
+		_ = onRoute.AddStoragePlace("Trunk", 5)
+		err := repo.Add(ctx, onRoute)
+		assert.NoError(t, err)
+		idle, _ := courier.NewCourier("test", createTestTransport(t, db, 5), createTestLocation(t, 1, 1))
+		err = repo.Add(ctx, idle)
+		assert.NoError(t, err)
+
+		_ = onRoute.TakeOrder(createTestOrder(t, 9, 9))
+		_ = onRoute.TakeOrder(createTestOrder(t, 2, 2))
+		route := onRoute.Route()
+		err = onRoute.ReplanRoute([]courier.RouteStop{route[1], route[0]})
+		assert.NoError(t, err)
+		err = repo.Update(ctx, onRoute)
+		assert.NoError(t, err)
+
+		result, err := repo.GetAllOnRoute(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, onRoute.ID(), result[0].ID())
+		assert.Equal(t, onRoute.Route(), result[0].Route())
+
+		// Доставленный заказ пропадает из маршрута и в БД
+		restored := result[0]
+		next, _ := restored.NextStop()
+		delivered, _ := order.NewOrder(next.OrderID(), createTestAddress(t), next.Location(), 5)
+		err = restored.CompleteOrder(delivered)
+		assert.NoError(t, err)
+		err = repo.Update(ctx, restored)
+		assert.NoError(t, err)
+
+		fromDb, err := repo.Get(ctx, onRoute.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, restored.Route(), fromDb.Route())
+		assert.Len(t, fromDb.Route(), 1)
+	})
+}
+
+func Test_CourierRepository_ClaimFree(t *testing.T) {
+	t.Run("Claim free couriers not claimed by another handler", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		repo := createCourierRepository(t, createTxManager(t, db))
+		location := createTestLocation(t, 1, 1)
+		free, _ := courier.NewCourier("test", createTestTransport(t, db, 5), location)
+		_ = free.AddStoragePlace("Bag", 5)
+		offDuty, _ := courier.NewCourier("test", createTestTransport(t, db, 5), location)
+		_ = offDuty.AddStoragePlace("Bag", 5)
+		_ = offDuty.EndShift()
+		db.Create(courierrepo.DomainToDTO(free))
+		db.Create(courierrepo.DomainToDTO(offDuty))
+
+		claimed, err := repo.ClaimFree(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+		assert.Equal(t, free.ID(), claimed[0].ID())
+
+		claimed, err = repo.ClaimFree(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		// После снятия аренды курьера снова может забрать любой обработчик
+		err = repo.ReleaseClaim(ctx, []uuid.UUID{free.ID()})
+		assert.NoError(t, err)
+		claimed, err = repo.ClaimFree(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
 	})
 }
 
+func Test_CourierRepository_LockFree(t *testing.T) {
+	t.Run("Courier locked by one transaction is skipped by another", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		location := createTestLocation(t, 1, 1)
+		free, _ := courier.NewCourier("test", createTestTransport(t, db, 5), location)
+		_ = free.StartShift()
+		_ = free.AddStoragePlace("Bag", 5)
+		db.Create(courierrepo.DomainToDTO(free))
+		ids := []uuid.UUID{free.ID()}
+
+		firstTx := createTxManager(t, db)
+		firstTx.Begin(ctx)
+		defer firstTx.Rollback(ctx)
+		locked, err := createCourierRepository(t, firstTx).LockFree(ctx, ids)
+		assert.NoError(t, err)
+		assert.Len(t, locked, 1)
+
+		secondTx := createTxManager(t, db)
+		secondTx.Begin(ctx)
+		locked, err = createCourierRepository(t, secondTx).LockFree(ctx, ids)
+		assert.NoError(t, err)
+		assert.Empty(t, locked)
+		secondTx.Rollback(ctx)
+
+		// После отката блокировка снята
+		firstTx.Rollback(ctx)
+		thirdTx := createTxManager(t, db)
+		thirdTx.Begin(ctx)
+		defer thirdTx.Rollback(ctx)
+		locked, err = createCourierRepository(t, thirdTx).LockFree(ctx, ids)
+		assert.NoError(t, err)
+		assert.Len(t, locked, 1)
+	})
+}
+
+func createTestOrder(t *testing.T, x int, y int) *order.Order {
+	o, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, x, y), 5)
+	assert.NoError(t, err)
+	return o
+}
+
 func createCourierRepository(t *testing.T, tx shared.TxManager) ports.CourierRepository {
 	res, err := courierrepo.NewCourierRepository(tx)
 	assert.NoError(t, err)
@@ -1,15 +1,21 @@
 package courierrepo
 
 import (
+	"delivery/internal/adapters/out/postgres/transportrepo"
+	"delivery/internal/core/domain/model/courier"
 	"github.com/google/uuid"
 )
 
 type CourierDTO struct {
 	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Name          string
-	Speed         int
-	StoragePlaces []*StoragePlaceDTO `gorm:"foreignKey:CourierID;constraint:OnDelete:CASCADE;"`
-	Location      LocationDTO        `gorm:"embedded;embeddedPrefix:location_"`
+	TransportID   uuid.UUID                   `gorm:"type:uuid;not null;index"`
+	Transport     *transportrepo.TransportDTO `gorm:"foreignKey:TransportID"`
+	Status        courier.Status              `gorm:"type:varchar(20)"`
+	StoragePlaces []*StoragePlaceDTO          `gorm:"foreignKey:CourierID;constraint:OnDelete:CASCADE;"`
+	RouteStops    []*RouteStopDTO             `gorm:"foreignKey:CourierID;constraint:OnDelete:CASCADE;"`
+	Location      LocationDTO                 `gorm:"embedded;embeddedPrefix:location_"`
+	Version       int64                       `gorm:"not null;default:0"`
 }
 
 type StoragePlaceDTO struct {
@@ -20,9 +26,16 @@ type StoragePlaceDTO struct {
 	CourierID   uuid.UUID `gorm:"type:uuid;index"`
 }
 
+type RouteStopDTO struct {
+	OrderID   uuid.UUID   `gorm:"type:uuid;primaryKey"`
+	CourierID uuid.UUID   `gorm:"type:uuid;index"`
+	Position  int         `gorm:"not null"`
+	Location  LocationDTO `gorm:"embedded;embeddedPrefix:location_"`
+}
+
 type LocationDTO struct {
-	X uint8
-	Y uint8
+	X int
+	Y int
 }
 
 func (CourierDTO) TableName() string {
@@ -32,3 +45,7 @@ func (CourierDTO) TableName() string {
 func (StoragePlaceDTO) TableName() string {
 	return "storage_places"
 }
+
+func (RouteStopDTO) TableName() string {
+	return "courier_route_stops"
+}
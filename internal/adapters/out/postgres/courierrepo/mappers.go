@@ -1,15 +1,19 @@
 package courierrepo
 
 import (
+	"delivery/internal/adapters/out/postgres/transportrepo"
 	"delivery/internal/core/domain/model/courier"
 	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"sort"
 )
 
 func DomainToDTO(aggregate *courier.Courier) CourierDTO {
 	var courierDTO CourierDTO
 	courierDTO.ID = aggregate.ID()
 	courierDTO.Name = aggregate.Name()
-	courierDTO.Speed = aggregate.Speed()
+	courierDTO.TransportID = aggregate.Transport().ID()
+	courierDTO.Status = aggregate.Status()
 	courierDTO.StoragePlaces = make([]*StoragePlaceDTO, 0)
 	for _, storagePlace := range aggregate.StoragePlaces() {
 		storagePlaceDTO := &StoragePlaceDTO{
@@ -21,22 +25,51 @@ func DomainToDTO(aggregate *courier.Courier) CourierDTO {
 		}
 		courierDTO.StoragePlaces = append(courierDTO.StoragePlaces, storagePlaceDTO)
 	}
+	courierDTO.RouteStops = make([]*RouteStopDTO, 0)
+	for position, stop := range aggregate.Route() {
+		courierDTO.RouteStops = append(courierDTO.RouteStops, &RouteStopDTO{
+			OrderID:   stop.OrderID(),
+			CourierID: aggregate.ID(),
+			Position:  position,
+			Location: LocationDTO{
+				X: stop.Location().X(),
+				Y: stop.Location().Y(),
+			},
+		})
+	}
 	courierDTO.Location = LocationDTO{
 		X: aggregate.Location().X(),
 		Y: aggregate.Location().Y(),
 	}
+	courierDTO.Version = aggregate.Version()
 	return courierDTO
 }
 
-func DtoToDomain(dto CourierDTO) *courier.Courier {
-	var aggregate *courier.Courier
+// DtoToDomain не восстанавливает курьера без корректного транспорта: у такого курьера нет скорости и мест хранения
+func DtoToDomain(dto CourierDTO) (*courier.Courier, error) {
 	var storagePlaces []*courier.StoragePlace
 	for _, dtoStoragePlace := range dto.StoragePlaces {
 		item := courier.RestoreStoragePlace(dtoStoragePlace.ID, dtoStoragePlace.Name,
 			dtoStoragePlace.TotalVolume, dtoStoragePlace.OrderID)
 		storagePlaces = append(storagePlaces, item)
 	}
-	location, _ := kernel.NewLocation(dto.Location.X, dto.Location.Y)
-	aggregate = courier.RestoreCourier(dto.ID, dto.Name, dto.Speed, location, storagePlaces)
-	return aggregate
+	dtoRouteStops := append([]*RouteStopDTO(nil), dto.RouteStops...)
+	sort.Slice(dtoRouteStops, func(i, j int) bool {
+		return dtoRouteStops[i].Position < dtoRouteStops[j].Position
+	})
+	route := make([]courier.RouteStop, 0, len(dtoRouteStops))
+	for _, dtoRouteStop := range dtoRouteStops {
+		stopLocation := kernel.RestoreLocation(dtoRouteStop.Location.X, dtoRouteStop.Location.Y)
+		route = append(route, courier.RestoreRouteStop(dtoRouteStop.OrderID, stopLocation))
+	}
+	if dto.Transport == nil {
+		return nil, errs.NewValueIsRequiredError("transport")
+	}
+	transport, err := transportrepo.DtoToDomain(*dto.Transport)
+	if err != nil {
+		return nil, errs.NewValueIsInvalidErrorWithCause("transport", err)
+	}
+	location := kernel.RestoreLocation(dto.Location.X, dto.Location.Y)
+	aggregate := courier.RestoreCourier(dto.ID, dto.Name, transport, location, dto.Status, storagePlaces, route, dto.Version)
+	return aggregate, nil
 }
@@ -3,12 +3,14 @@ package courierrepo
 import (
 	"context"
 	"delivery/internal/adapters/out/postgres/shared"
+	"delivery/internal/adapters/out/postgres/transportrepo"
 	"delivery/internal/core/domain/model/courier"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/errs"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
 var _ ports.CourierRepository = &Repository{}
@@ -36,11 +38,15 @@ func (r *Repository) Add(ctx context.Context, aggregate *courier.Courier) error
 	isInTransaction := r.txManager.InTx()
 	if !isInTransaction {
 		r.txManager.Begin(ctx)
+		defer r.txManager.Rollback(ctx)
 	}
 
 	tx := r.txManager.Tx()
 
-	err := tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Create(&dto).Error
+	// Транспорт берётся из справочника, сам справочник курьер не меняет
+	err := tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).
+		Omit("Transport").
+		Create(&dto).Error
 	if err != nil {
 		return err
 	}
@@ -66,13 +72,58 @@ func (r *Repository) Update(ctx context.Context, aggregate *courier.Courier) err
 	isInTransaction := r.txManager.InTx()
 	if !isInTransaction {
 		r.txManager.Begin(ctx)
+		defer r.txManager.Rollback(ctx)
 	}
 	tx := r.txManager.Tx()
 
-	err := tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(&dto).Error
+	// Обновляем курьера только если его версия не изменилась с момента чтения
+	expectedVersion := dto.Version
+	dto.Version = expectedVersion + 1
+	result := tx.WithContext(ctx).
+		Model(&dto).
+		Where("version = ?", expectedVersion).
+		Select("*").
+		Omit(clause.Associations).
+		Updates(&dto)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errs.NewVersionIsInvalidError("Courier " + dto.ID.String())
+	}
+
+	// Save не удаляет места хранения, которые курьер убрал, поэтому удаляем их явно
+	storagePlaceIDs := make([]uuid.UUID, 0, len(dto.StoragePlaces))
+	for _, storagePlace := range dto.StoragePlaces {
+		storagePlaceIDs = append(storagePlaceIDs, storagePlace.ID)
+	}
+	deleteOrphans := tx.WithContext(ctx).Where("courier_id = ?", dto.ID)
+	if len(storagePlaceIDs) > 0 {
+		deleteOrphans = deleteOrphans.Where("id NOT IN ?", storagePlaceIDs)
+	}
+	err := deleteOrphans.Delete(&StoragePlaceDTO{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(dto.StoragePlaces) > 0 {
+		err := tx.WithContext(ctx).Save(&dto.StoragePlaces).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// Маршрут целиком переписываем: точки удаляются по мере доставки и меняют порядок при перепланировании
+	err = tx.WithContext(ctx).Where("courier_id = ?", dto.ID).Delete(&RouteStopDTO{}).Error
 	if err != nil {
 		return err
 	}
+	if len(dto.RouteStops) > 0 {
+		err := tx.WithContext(ctx).Create(&dto.RouteStops).Error
+		if err != nil {
+			return err
+		}
+	}
 
 	if !isInTransaction {
 		err := r.txManager.Commit(ctx)
@@ -81,6 +132,7 @@ func (r *Repository) Update(ctx context.Context, aggregate *courier.Courier) err
 		}
 	}
 
+	aggregate.SetVersion(dto.Version)
 	return nil
 }
 
@@ -92,8 +144,7 @@ func (r *Repository) Get(ctx context.Context, ID uuid.UUID) (*courier.Courier, e
 	dto := CourierDTO{}
 
 	tx := r.getTxOrDb()
-	result := tx.WithContext(ctx).
-		Preload(clause.Associations).
+	result := withAssociations(tx.WithContext(ctx)).
 		Find(&dto, ID)
 
 	if result.Error != nil {
@@ -104,36 +155,150 @@ func (r *Repository) Get(ctx context.Context, ID uuid.UUID) (*courier.Courier, e
 		return nil, errs.NewObjectNotFoundError("Courier by ID", ID)
 	}
 
-	aggregate := DtoToDomain(dto)
-	return aggregate, nil
+	return DtoToDomain(dto)
 }
 
+// GetAllFree возвращает курьеров на смене, у которых есть хотя бы одно свободное место хранения
 func (r *Repository) GetAllFree(ctx context.Context) ([]*courier.Courier, error) {
+	return findFree(ctx, r.getTxOrDb())
+}
+
+func findFree(ctx context.Context, tx *gorm.DB) ([]*courier.Courier, error) {
 	var dtos []CourierDTO
 
-	tx := r.getTxOrDb()
-	result := tx.WithContext(ctx).
-		Preload(clause.Associations).
-		Where(`NOT EXISTS (
+	result := whereFree(withAssociations(tx.WithContext(ctx))).Find(&dtos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errs.NewObjectNotFoundError("Free couriers", nil)
+	}
+
+	aggregates := make([]*courier.Courier, len(dtos))
+	for i, dto := range dtos {
+		aggregate, err := DtoToDomain(dto)
+		if err != nil {
+			return nil, err
+		}
+		aggregates[i] = aggregate
+	}
+
+	return aggregates, nil
+}
+
+// ClaimFree арендует до limit свободных курьеров, поэтому параллельные обработчики распределяют заказы
+// между разными курьерами. Пустой результат - не ошибка: свободных курьеров уже арендовали другие
+func (r *Repository) ClaimFree(ctx context.Context, limit int, lease time.Duration) ([]*courier.Courier, error) {
+	if limit <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("limit", limit, 1, nil)
+	}
+	if lease <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("lease", lease, "1ns", nil)
+	}
+
+	tx := r.getTxOrDb().WithContext(ctx)
+	unclaimed := whereFree(shared.WhereNotClaimed(tx.Model(&CourierDTO{}).Select("id"))).
+		Order("id").
+		Limit(limit)
+	ids, err := shared.Claim(tx, "couriers", unclaimed, lease)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*courier.Courier{}, nil
+	}
+
+	var dtos []CourierDTO
+	result := withAssociations(tx).Where("couriers.id IN ?", ids).Order("couriers.id").Find(&dtos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return dtosToDomain(dtos)
+}
+
+// ReleaseClaim снимает аренду ClaimFree
+func (r *Repository) ReleaseClaim(ctx context.Context, ids []uuid.UUID) error {
+	return shared.ReleaseClaim(r.getTxOrDb().WithContext(ctx), "couriers", ids)
+}
+
+// LockFree блокирует выбранных курьеров, если они всё ещё свободны, поэтому свободное место курьера
+// не достанется двум обработчикам сразу. Пустой результат - не ошибка: курьеров уже заняли другие
+func (r *Repository) LockFree(ctx context.Context, ids []uuid.UUID) ([]*courier.Courier, error) {
+	tx, err := shared.SkipLocked(r.txManager)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*courier.Courier{}, nil
+	}
+
+	var dtos []CourierDTO
+	result := whereFree(withAssociations(tx.WithContext(ctx))).Where("couriers.id IN ?", ids).Find(&dtos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return dtosToDomain(dtos)
+}
+
+func dtosToDomain(dtos []CourierDTO) ([]*courier.Courier, error) {
+	aggregates := make([]*courier.Courier, len(dtos))
+	for i, dto := range dtos {
+		aggregate, err := DtoToDomain(dto)
+		if err != nil {
+			return nil, err
+		}
+		aggregates[i] = aggregate
+	}
+	return aggregates, nil
+}
+
+func whereFree(tx *gorm.DB) *gorm.DB {
+	return tx.Where("status = ?", courier.StatusOnDuty).
+		Where(`EXISTS (
             SELECT 1 FROM storage_places sp
-            WHERE sp.courier_id = couriers.id AND sp.order_id IS NOT NULL
+            WHERE sp.courier_id = couriers.id AND sp.order_id IS NULL
+        )`)
+}
+
+// GetAllOnRoute возвращает курьеров, которым есть куда ехать
+func (r *Repository) GetAllOnRoute(ctx context.Context) ([]*courier.Courier, error) {
+	var dtos []CourierDTO
+
+	tx := r.getTxOrDb()
+	result := withAssociations(tx.WithContext(ctx)).
+		Where(`EXISTS (
+            SELECT 1 FROM courier_route_stops rs
+            WHERE rs.courier_id = couriers.id
         )`).Find(&dtos)
 
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	if result.RowsAffected == 0 {
-		return nil, errs.NewObjectNotFoundError("Free couriers", nil)
+		return nil, errs.NewObjectNotFoundError("Couriers on route", nil)
 	}
 
 	aggregates := make([]*courier.Courier, len(dtos))
 	for i, dto := range dtos {
-		aggregates[i] = DtoToDomain(dto)
+		aggregate, err := DtoToDomain(dto)
+		if err != nil {
+			return nil, err
+		}
+		aggregates[i] = aggregate
 	}
 
 	return aggregates, nil
 }
 
+// withAssociations подгружает транспорт, места хранения и точки маршрута в порядке объезда
+func withAssociations(tx *gorm.DB) *gorm.DB {
+	return transportrepo.WithStorageLayout(tx, "Transport.StorageLayout").
+		Preload("StoragePlaces").
+		Preload("RouteStops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		})
+}
+
 func (r *Repository) getTxOrDb() *gorm.DB {
 	if tx := r.txManager.Tx(); tx != nil {
 		return tx
@@ -0,0 +1,167 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// DefaultLockKey - ключ advisory lock, за который соревнуются реплики сервиса
+const DefaultLockKey int64 = 0x64656c6976657279 // "delivery"
+
+// Elector выбирает лидера среди реплик через advisory lock Postgres. Блокировка принадлежит сессии,
+// поэтому лидер держит отдельное соединение: если процесс упал или соединение оборвалось,
+// Postgres снимает блокировку и её забирает другая реплика
+type Elector struct {
+	db       *sql.DB
+	key      int64
+	interval time.Duration
+
+	// conn используется только горутиной run, а после её завершения - Shutdown
+	conn   *sql.Conn
+	leader atomic.Bool
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewElector(db *sql.DB, key int64, interval time.Duration) (*Elector, error) {
+	if db == nil {
+		return nil, errs.NewValueIsRequiredError("db")
+	}
+	if interval <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("interval", interval, "1ns", nil)
+	}
+	return &Elector{
+		db:       db,
+		key:      key,
+		interval: interval,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start пытается стать лидером каждые interval, а лидер с тем же интервалом проверяет, что его соединение живо
+func (e *Elector) Start() {
+	e.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+		go e.run(ctx)
+	})
+}
+
+// IsLeader - лидерство проверяется раз в interval, поэтому после обрыва соединения
+// реплика ещё до interval считает себя лидером
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Shutdown отпускает блокировку, чтобы другая реплика стала лидером, не дожидаясь обрыва соединения
+func (e *Elector) Shutdown(ctx context.Context) error {
+	var err error
+	e.stopOnce.Do(func() {
+		started := true
+		e.startOnce.Do(func() { started = false })
+		if started {
+			e.cancel()
+			select {
+			case <-e.done:
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			}
+		}
+		err = e.resign(ctx)
+	})
+	return err
+}
+
+func (e *Elector) run(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		e.elect(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) elect(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	if e.leader.Load() {
+		// Пока сессия жива, блокировка за ней
+		if err := e.conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("leadership lost: %v", err)
+			e.dropConn()
+		}
+		return
+	}
+
+	acquired, err := e.tryLock(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Errorf("leader election failed: %v", err)
+		}
+		e.dropConn()
+		return
+	}
+	if acquired {
+		log.Info("this replica is the leader now")
+		e.leader.Store(true)
+	}
+}
+
+func (e *Elector) tryLock(ctx context.Context) (bool, error) {
+	if e.conn == nil {
+		conn, err := e.db.Conn(ctx)
+		if err != nil {
+			return false, err
+		}
+		e.conn = conn
+	}
+	var acquired bool
+	err := e.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&acquired)
+	return acquired, err
+}
+
+func (e *Elector) resign(ctx context.Context) error {
+	if e.conn == nil {
+		return nil
+	}
+	var err error
+	if e.leader.Load() {
+		var released bool
+		err = e.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", e.key).Scan(&released)
+		if err == nil && !released {
+			err = errors.New("advisory lock was not held")
+		}
+	}
+	e.dropConn()
+	return err
+}
+
+// dropConn закрывает сессию вместе с блокировкой. Conn.Close вернул бы соединение в пул, и блокировка,
+// которую не удалось снять или которую сервер выдал уже после таймаута, осталась бы в чужой сессии.
+// Поэтому соединение помечается испорченным, и пул закрывает его физически
+func (e *Elector) dropConn() {
+	e.leader.Store(false)
+	if e.conn != nil {
+		_ = e.conn.Raw(func(any) error { return driver.ErrBadConn })
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"delivery/internal/adapters/out/postgres/leader"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LeaderElector(t *testing.T) {
+	t.Run("Only one replica is the leader and another takes over after shutdown", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		sqlDb, err := db.DB()
+		assert.NoError(t, err)
+		interval := 50 * time.Millisecond
+		first, err := leader.NewElector(sqlDb, leader.DefaultLockKey, interval)
+		assert.NoError(t, err)
+		second, err := leader.NewElector(sqlDb, leader.DefaultLockKey, interval)
+		assert.NoError(t, err)
+
+		first.Start()
+		assert.Eventually(t, first.IsLeader, time.Second, interval)
+		second.Start()
+		time.Sleep(3 * interval)
+		assert.False(t, second.IsLeader())
+
+		err = first.Shutdown(ctx)
+		assert.NoError(t, err)
+		assert.False(t, first.IsLeader())
+		assert.Eventually(t, second.IsLeader, time.Second, interval)
+
+		err = second.Shutdown(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Leadership is lost with the session", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		sqlDb, err := db.DB()
+		assert.NoError(t, err)
+		interval := 50 * time.Millisecond
+		elector, err := leader.NewElector(sqlDb, leader.DefaultLockKey, interval)
+		assert.NoError(t, err)
+		elector.Start()
+		assert.Eventually(t, elector.IsLeader, time.Second, interval)
+
+		// Обрываем сессию лидера, как при сетевом сбое
+		err = db.Exec(`SELECT pg_terminate_backend(pid) FROM pg_locks
+			WHERE locktype = 'advisory' AND pid <> pg_backend_pid()`).Error
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return !elector.IsLeader() }, time.Second, interval/10)
+		// Следующая попытка берёт блокировку на новом соединении
+		assert.Eventually(t, elector.IsLeader, time.Second, interval)
+		assert.NoError(t, elector.Shutdown(ctx))
+	})
+}
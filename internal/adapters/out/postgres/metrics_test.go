@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"delivery/internal/adapters/out/metrics"
+	"delivery/internal/adapters/out/postgres/orderrepo"
+	"delivery/internal/core/domain/model/order"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Metrics_State(t *testing.T) {
+	t.Run("Must report orders by status and order timings", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		m, err := metrics.NewMetrics(db)
+		assert.NoError(t, err)
+		repo, err := orderrepo.NewOrderRepositoryWithObserver(tx, m)
+		assert.NoError(t, err)
+
+		created, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Add(ctx, created))
+		assigned, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Add(ctx, assigned))
+		assert.NoError(t, assigned.Assign(uuid.New()))
+		assert.NoError(t, repo.Update(ctx, assigned))
+
+		recorder := httptest.NewRecorder()
+		m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		body, _ := io.ReadAll(recorder.Result().Body)
+
+		assert.Contains(t, string(body), `delivery_orders{status="Created"} 1`)
+		assert.Contains(t, string(body), `delivery_orders{status="Assigned"} 1`)
+		assert.Contains(t, string(body), `delivery_order_assignment_latency_seconds_count 1`)
+		assert.Contains(t, string(body), `delivery_order_delivery_duration_seconds_count 0`)
+		assert.Contains(t, string(body), `delivery_storage_utilization_ratio 0`)
+	})
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS couriers
+(
+    id         uuid PRIMARY KEY,
+    name       text,
+    speed      bigint,
+    location_x smallint,
+    location_y smallint,
+    version    bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS storage_places
+(
+    id           uuid PRIMARY KEY,
+    order_id     uuid,
+    name         text,
+    total_volume bigint,
+    courier_id   uuid,
+    CONSTRAINT fk_couriers_storage_places FOREIGN KEY (courier_id) REFERENCES couriers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_places_courier_id ON storage_places (courier_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS storage_places;
+DROP TABLE IF EXISTS couriers;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS orders
+(
+    id            uuid PRIMARY KEY,
+    courier_id    uuid,
+    location_x    smallint,
+    location_y    smallint,
+    volume        bigint,
+    status        varchar(20),
+    cancel_reason text,
+    delivery_from timestamptz,
+    delivery_to   timestamptz,
+    version       bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_courier_id ON orders (courier_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS orders;
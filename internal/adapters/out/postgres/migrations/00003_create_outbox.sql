@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id               uuid PRIMARY KEY,
+    name             varchar(255),
+    payload          jsonb,
+    occurred_at_utc  timestamptz,
+    processed_at_utc timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_occurred_at_utc ON outbox (occurred_at_utc);
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Джобы назначения и перемещения выбирают заказы по статусу
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+
+-- Поиск свободных курьеров смотрит только на занятые места хранения
+CREATE INDEX IF NOT EXISTS idx_storage_places_order_id ON storage_places (order_id) WHERE order_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_storage_places_order_id;
+DROP INDEX IF EXISTS idx_orders_status;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS courier_route_stops
+(
+    order_id   uuid PRIMARY KEY,
+    courier_id uuid,
+    position   bigint NOT NULL,
+    location_x smallint,
+    location_y smallint,
+    CONSTRAINT fk_couriers_route_stops FOREIGN KEY (courier_id) REFERENCES couriers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_route_stops_courier_id ON courier_route_stops (courier_id);
+
+-- Уже назначенные заказы становятся точками маршрута своих курьеров
+INSERT INTO courier_route_stops (order_id, courier_id, position, location_x, location_y)
+SELECT o.id,
+       o.courier_id,
+       row_number() OVER (PARTITION BY o.courier_id ORDER BY o.id) - 1,
+       o.location_x,
+       o.location_y
+FROM orders o
+         JOIN couriers c ON c.id = o.courier_id
+WHERE o.status = 'Assigned'
+ON CONFLICT (order_id) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS courier_route_stops;
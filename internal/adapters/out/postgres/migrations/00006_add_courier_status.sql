@@ -0,0 +1,10 @@
+-- +goose Up
+-- Курьеры, которые уже есть в БД, остаются доступными для назначения
+ALTER TABLE couriers ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'OnDuty';
+ALTER TABLE couriers ALTER COLUMN status SET DEFAULT 'OffDuty';
+
+CREATE INDEX IF NOT EXISTS idx_couriers_status ON couriers (status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_couriers_status;
+ALTER TABLE couriers DROP COLUMN IF EXISTS status;
@@ -0,0 +1,62 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS transports
+(
+    id    uuid PRIMARY KEY,
+    name  text   NOT NULL,
+    speed bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transport_storage_places
+(
+    transport_id uuid,
+    position     bigint,
+    name         text   NOT NULL,
+    total_volume bigint NOT NULL,
+    PRIMARY KEY (transport_id, position),
+    CONSTRAINT fk_transports_storage_layout FOREIGN KEY (transport_id) REFERENCES transports (id) ON DELETE CASCADE
+);
+
+-- Справочник транспорта
+INSERT INTO transports (id, name, speed)
+VALUES ('921e3d64-7c68-45ed-88fb-97ceb8148a7e', 'Пешком', 1),
+       ('b96a9d83-aefa-4d06-99fb-e630d17c3868', 'Велосипед', 2),
+       ('d719d5e7-c8a9-42d8-bed2-12acbea5908e', 'Авто', 3)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO transport_storage_places (transport_id, position, name, total_volume)
+VALUES ('921e3d64-7c68-45ed-88fb-97ceb8148a7e', 0, 'backpack', 8),
+       ('b96a9d83-aefa-4d06-99fb-e630d17c3868', 0, 'bag', 10),
+       ('d719d5e7-c8a9-42d8-bed2-12acbea5908e', 0, 'trunk', 30)
+ON CONFLICT (transport_id, position) DO NOTHING;
+
+-- Уже созданным курьерам достаётся транспорт с ближайшей скоростью, скорость теперь берётся из справочника
+ALTER TABLE couriers ADD COLUMN IF NOT EXISTS transport_id uuid;
+
+UPDATE couriers c
+SET transport_id = (SELECT t.id
+                    FROM transports t
+                    ORDER BY abs(t.speed - coalesce(c.speed, 1)), t.speed
+                    LIMIT 1)
+WHERE c.transport_id IS NULL;
+
+ALTER TABLE couriers ALTER COLUMN transport_id SET NOT NULL;
+ALTER TABLE couriers
+    ADD CONSTRAINT fk_couriers_transport FOREIGN KEY (transport_id) REFERENCES transports (id);
+CREATE INDEX IF NOT EXISTS idx_couriers_transport_id ON couriers (transport_id);
+
+ALTER TABLE couriers DROP COLUMN IF EXISTS speed;
+
+-- +goose Down
+ALTER TABLE couriers ADD COLUMN IF NOT EXISTS speed bigint;
+
+UPDATE couriers c
+SET speed = t.speed
+FROM transports t
+WHERE t.id = c.transport_id;
+
+ALTER TABLE couriers DROP CONSTRAINT IF EXISTS fk_couriers_transport;
+DROP INDEX IF EXISTS idx_couriers_transport_id;
+ALTER TABLE couriers DROP COLUMN IF EXISTS transport_id;
+
+DROP TABLE IF EXISTS transport_storage_places;
+DROP TABLE IF EXISTS transports;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Карта города настраивается, smallint не вмещает координаты больших городов
+ALTER TABLE couriers
+    ALTER COLUMN location_x TYPE integer,
+    ALTER COLUMN location_y TYPE integer;
+ALTER TABLE orders
+    ALTER COLUMN location_x TYPE integer,
+    ALTER COLUMN location_y TYPE integer;
+ALTER TABLE courier_route_stops
+    ALTER COLUMN location_x TYPE integer,
+    ALTER COLUMN location_y TYPE integer;
+
+-- +goose Down
+-- Откат возможен, только если все координаты помещаются в smallint
+ALTER TABLE courier_route_stops
+    ALTER COLUMN location_x TYPE smallint,
+    ALTER COLUMN location_y TYPE smallint;
+ALTER TABLE orders
+    ALTER COLUMN location_x TYPE smallint,
+    ALTER COLUMN location_y TYPE smallint;
+ALTER TABLE couriers
+    ALTER COLUMN location_x TYPE smallint,
+    ALTER COLUMN location_y TYPE smallint;
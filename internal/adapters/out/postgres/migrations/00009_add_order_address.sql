@@ -0,0 +1,17 @@
+-- +goose Up
+-- Полный адрес доставки: одноимённые улицы в разных городах больше не путаются.
+-- У заказов, созданных раньше, адрес остаётся пустым
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS address_country   text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS address_city      text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS address_street    text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS address_house     text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS address_apartment text NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS address_apartment,
+    DROP COLUMN IF EXISTS address_house,
+    DROP COLUMN IF EXISTS address_street,
+    DROP COLUMN IF EXISTS address_city,
+    DROP COLUMN IF EXISTS address_country;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Время переходов заказа нужно для метрик задержки назначения и длительности доставки.
+-- Для заказов, созданных раньше, время создания неизвестно и считается моментом миграции
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS created_at   timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS assigned_at  timestamptz,
+    ADD COLUMN IF NOT EXISTS completed_at timestamptz;
+
+-- +goose Down
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS created_at;
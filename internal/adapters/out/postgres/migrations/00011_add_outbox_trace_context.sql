@@ -0,0 +1,8 @@
+-- +goose Up
+-- Контекст трассы, в которой возникло событие: при публикации в Kafka трасса продолжается
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS trace_context jsonb;
+
+-- +goose Down
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS trace_context;
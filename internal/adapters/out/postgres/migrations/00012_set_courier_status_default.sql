@@ -0,0 +1,6 @@
+-- +goose Up
+-- Новый курьер по умолчанию на смене, как и в домене
+ALTER TABLE couriers ALTER COLUMN status SET DEFAULT 'OnDuty';
+
+-- +goose Down
+ALTER TABLE couriers ALTER COLUMN status SET DEFAULT 'OffDuty';
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Обработчик назначения арендует пачку заказов и курьеров до этого времени: параллельные обработчики
+-- разбирают разные строки, а распределение считается без блокировок. Если обработчик упал, аренда истекает сама
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS claimed_until timestamptz;
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS claimed_until timestamptz;
+
+-- +goose Down
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS claimed_until;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS claimed_until;
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"delivery/internal/pkg/errs"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"time"
+)
+
+//go:embed *.sql
+var embedMigrations embed.FS
+
+var ErrSchemaVersionMismatch = errors.New("schema version mismatch")
+
+// Status - состояние одной миграции
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	if db == nil {
+		return nil, errs.NewValueIsRequiredError("db")
+	}
+
+	// Блокировка не даёт нескольким репликам накатывать миграции одновременно
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, embedMigrations,
+		goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{provider: provider}, nil
+}
+
+// Up применяет все неприменённые миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	_, err := m.provider.Up(ctx)
+	return err
+}
+
+// Down откатывает последнюю применённую миграцию
+func (m *Migrator) Down(ctx context.Context) error {
+	_, err := m.provider.Down(ctx)
+	return err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, Status{
+			Version:   status.Source.Version,
+			Name:      status.Source.Path,
+			Applied:   status.State == goose.StateApplied,
+			AppliedAt: status.AppliedAt,
+		})
+	}
+	return result, nil
+}
+
+// CheckVersion возвращает ошибку, если версия схемы БД не совпадает с последней миграцией
+func (m *Migrator) CheckVersion(ctx context.Context) error {
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if current != target {
+		return fmt.Errorf("%w: database is at %d, application expects %d", ErrSchemaVersionMismatch, current, target)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"delivery/internal/adapters/out/postgres/migrations"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Migrator(t *testing.T) {
+	t.Run("Schema is up to date after setup", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		sqlDb, err := db.DB()
+		assert.NoError(t, err)
+		migrator, err := migrations.NewMigrator(sqlDb)
+		assert.NoError(t, err)
+
+		err = migrator.CheckVersion(ctx)
+		assert.NoError(t, err)
+
+		statuses, err := migrator.Status(ctx)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, statuses)
+		for _, status := range statuses {
+			assert.True(t, status.Applied)
+		}
+	})
+
+	t.Run("Return mismatch after rolling back a migration", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		sqlDb, err := db.DB()
+		assert.NoError(t, err)
+		migrator, err := migrations.NewMigrator(sqlDb)
+		assert.NoError(t, err)
+
+		err = migrator.Down(ctx)
+		assert.NoError(t, err)
+
+		err = migrator.CheckVersion(ctx)
+		assert.ErrorIs(t, err, migrations.ErrSchemaVersionMismatch)
+	})
+
+	t.Run("Create partial index on occupied storage places", func(t *testing.T) {
+		_, db := setupTest(t)
+
+		var definition string
+		err := db.Raw("SELECT indexdef FROM pg_indexes WHERE indexname = ?", "idx_storage_places_order_id").
+			Scan(&definition).Error
+		assert.NoError(t, err)
+		assert.Contains(t, definition, "WHERE (order_id IS NOT NULL)")
+	})
+}
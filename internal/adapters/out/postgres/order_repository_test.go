@@ -5,9 +5,11 @@ import (
 	"delivery/internal/adapters/out/postgres/shared"
 	"delivery/internal/core/domain/model/order"
 	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func Test_OrderRepository_Add(t *testing.T) {
@@ -16,7 +18,7 @@ func Test_OrderRepository_Add(t *testing.T) {
 		tx := createTxManager(t, db)
 		repo := createOrderRepository(t, tx)
 
-		newOrder, err := order.NewOrder(uuid.New(), createTestLocation(t, 1, 1), 5)
+		newOrder, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
 		assert.NoError(t, err)
 
 		err = repo.Add(ctx, newOrder)
@@ -31,6 +33,9 @@ func Test_OrderRepository_Add(t *testing.T) {
 		assert.Equal(t, newOrder.Status(), orderFromDb.Status)
 		assert.Equal(t, newOrder.Location().X(), orderFromDb.Location.X)
 		assert.Equal(t, newOrder.Location().Y(), orderFromDb.Location.Y)
+		assert.Equal(t, newOrder.Address().City(), orderFromDb.Address.City)
+		assert.Equal(t, newOrder.Address().Street(), orderFromDb.Address.Street)
+		assert.Equal(t, newOrder.Address().Apartment(), orderFromDb.Address.Apartment)
 	})
 }
 
@@ -41,7 +46,7 @@ func Test_OrderRepository_Update(t *testing.T) {
 		repo := createOrderRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		oldOrder, err := order.NewOrder(uuid.New(), location, 5)
+		oldOrder, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 		assert.NoError(t, err)
 		dto := orderrepo.DomainToDTO(oldOrder)
 
@@ -60,6 +65,42 @@ func Test_OrderRepository_Update(t *testing.T) {
 
 		assert.Equal(t, oldOrder.CourierID(), orderFromDb.CourierID)
 		assert.Equal(t, order.StatusAssigned, orderFromDb.Status)
+		assert.Equal(t, int64(1), orderFromDb.Version)
+		assert.Equal(t, int64(1), oldOrder.Version())
+		assert.NotNil(t, orderFromDb.AssignedAt)
+		assert.Nil(t, orderFromDb.CompletedAt)
+		assert.False(t, orderFromDb.CreatedAt.IsZero())
+	})
+
+	t.Run("Return version conflict if order was changed concurrently", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createOrderRepository(t, tx)
+
+		created, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		err = repo.Add(ctx, created)
+		assert.NoError(t, err)
+
+		first, err := repo.Get(ctx, created.ID())
+		assert.NoError(t, err)
+		second, err := repo.Get(ctx, created.ID())
+		assert.NoError(t, err)
+
+		err = first.Assign(uuid.New())
+		assert.NoError(t, err)
+		err = repo.Update(ctx, first)
+		assert.NoError(t, err)
+
+		err = second.Cancel("reason")
+		assert.NoError(t, err)
+		err = repo.Update(ctx, second)
+		assert.ErrorIs(t, err, errs.ErrVersionIsInvalid)
+
+		var orderFromDb orderrepo.OrderDTO
+		err = db.First(&orderFromDb, "id = ?", created.ID()).Error
+		assert.NoError(t, err)
+		assert.Equal(t, order.StatusAssigned, orderFromDb.Status)
 	})
 }
 
@@ -70,10 +111,10 @@ func Test_OrderRepository_GetFirstInCreatedStatus(t *testing.T) {
 		repo := createOrderRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		inCreated, err := order.NewOrder(uuid.New(), location, 5)
+		inCreated, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 		assert.NoError(t, err)
 
-		assigned, err := order.NewOrder(uuid.New(), location, 5)
+		assigned, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 		assert.NoError(t, err)
 		err = assigned.Assign(uuid.New())
 		assert.NoError(t, err)
@@ -88,6 +129,62 @@ func Test_OrderRepository_GetFirstInCreatedStatus(t *testing.T) {
 	})
 }
 
+func Test_OrderRepository_ClaimInCreatedStatus(t *testing.T) {
+	t.Run("Claim the oldest created orders not claimed by another handler", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		repo := createOrderRepository(t, createTxManager(t, db))
+
+		location := createTestLocation(t, 1, 1)
+		first, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
+		assert.NoError(t, err)
+		second, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
+		assert.NoError(t, err)
+		assigned, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
+		assert.NoError(t, err)
+		err = assigned.Assign(uuid.New())
+		assert.NoError(t, err)
+
+		db.Create(orderrepo.DomainToDTO(first))
+		db.Create(orderrepo.DomainToDTO(second))
+		db.Create(orderrepo.DomainToDTO(assigned))
+
+		// Пачка ограничена, первыми идут самые старые заказы
+		claimed, err := repo.ClaimInCreatedStatus(ctx, 1, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+		assert.Equal(t, first.ID(), claimed[0].ID())
+
+		// Арендованный и уже назначенный заказы другой обработчик не получит
+		claimed, err = repo.ClaimInCreatedStatus(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+		assert.Equal(t, second.ID(), claimed[0].ID())
+
+		claimed, err = repo.ClaimInCreatedStatus(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	t.Run("Claim orders again after release", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		repo := createOrderRepository(t, createTxManager(t, db))
+
+		created, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		db.Create(orderrepo.DomainToDTO(created))
+
+		claimed, err := repo.ClaimInCreatedStatus(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+
+		err = repo.ReleaseClaim(ctx, []uuid.UUID{created.ID()})
+		assert.NoError(t, err)
+		claimed, err = repo.ClaimInCreatedStatus(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+	})
+}
+
 func Test_OrderRepository_GetAllAssignedOrders(t *testing.T) {
 	t.Run("Return all assigned orders", func(t *testing.T) {
 		ctx, db := setupTest(t)
@@ -95,9 +192,9 @@ func Test_OrderRepository_GetAllAssignedOrders(t *testing.T) {
 		repo := createOrderRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		first, err := order.NewOrder(uuid.New(), location, 5)
+		first, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 		assert.NoError(t, err)
-		second, err := order.NewOrder(uuid.New(), location, 5)
+		second, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 		assert.NoError(t, err)
 		err = first.Assign(uuid.New())
 		assert.NoError(t, err)
@@ -121,7 +218,7 @@ func Test_OrderRepository_GetByID(t *testing.T) {
 		repo := createOrderRepository(t, tx)
 
 		location := createTestLocation(t, 1, 1)
-		expected, err := order.NewOrder(uuid.New(), location, 5)
+		expected, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 		assert.NoError(t, err)
 		db.Create(orderrepo.DomainToDTO(expected))
 
@@ -132,6 +229,75 @@ func Test_OrderRepository_GetByID(t *testing.T) {
 	})
 }
 
+func Test_OrderRepository_DeliveryPeriod(t *testing.T) {
+	t.Run("Persist and restore delivery period", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createOrderRepository(t, tx)
+
+		from := time.Now().UTC().Truncate(time.Second)
+		period, err := order.NewDeliveryPeriod(from, from.Add(4*time.Hour))
+		assert.NoError(t, err)
+		expected, err := order.NewOrderWithDeliveryPeriod(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5, period)
+		assert.NoError(t, err)
+
+		err = repo.Add(ctx, expected)
+		assert.NoError(t, err)
+
+		fromDb, err := repo.Get(ctx, expected.ID())
+		assert.NoError(t, err)
+		assert.True(t, expected.DeliveryPeriod().Equals(fromDb.DeliveryPeriod()))
+	})
+}
+
+func Test_OrderRepository_LockInCreatedStatus(t *testing.T) {
+	t.Run("Concurrent transactions skip locked orders", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		first, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		second, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 2, 2), 5)
+		assert.NoError(t, err)
+		assigned, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 3, 3), 5)
+		assert.NoError(t, err)
+		assert.NoError(t, assigned.Assign(uuid.New()))
+		db.Create(orderrepo.DomainToDTO(first))
+		db.Create(orderrepo.DomainToDTO(second))
+		db.Create(orderrepo.DomainToDTO(assigned))
+		ids := []uuid.UUID{first.ID(), second.ID(), assigned.ID()}
+
+		firstTx := createTxManager(t, db)
+		firstTx.Begin(ctx)
+		defer firstTx.Rollback(ctx)
+		lockedFirst, err := createOrderRepository(t, firstTx).LockInCreatedStatus(ctx, []uuid.UUID{first.ID()})
+		assert.NoError(t, err)
+		assert.Len(t, lockedFirst, 1)
+
+		// Заблокированный и уже назначенный заказы пропускаются
+		secondTx := createTxManager(t, db)
+		secondTx.Begin(ctx)
+		defer secondTx.Rollback(ctx)
+		lockedSecond, err := createOrderRepository(t, secondTx).LockInCreatedStatus(ctx, ids)
+		assert.NoError(t, err)
+		assert.Len(t, lockedSecond, 1)
+		assert.Equal(t, second.ID(), lockedSecond[0].ID())
+
+		thirdTx := createTxManager(t, db)
+		thirdTx.Begin(ctx)
+		defer thirdTx.Rollback(ctx)
+		lockedThird, err := createOrderRepository(t, thirdTx).LockInCreatedStatus(ctx, ids)
+		assert.NoError(t, err)
+		assert.Empty(t, lockedThird)
+	})
+
+	t.Run("Lock requires transaction", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		repo := createOrderRepository(t, createTxManager(t, db))
+
+		_, err := repo.LockInCreatedStatus(ctx, []uuid.UUID{uuid.New()})
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
 func createOrderRepository(t *testing.T, tx shared.TxManager) ports.OrderRepository {
 	res, err := orderrepo.NewOrderRepository(tx)
 	assert.NoError(t, err)
@@ -3,19 +3,42 @@ package orderrepo
 import (
 	"delivery/internal/core/domain/model/order"
 	"github.com/google/uuid"
+	"time"
 )
 
 type OrderDTO struct {
-	ID        uuid.UUID   `gorm:"type:uuid;primaryKey"`
-	CourierID *uuid.UUID  `gorm:"type:uuid;index"`
-	Location  LocationDTO `gorm:"embedded;embeddedPrefix:location_"`
-	Volume    int
-	Status    order.Status `gorm:"type:varchar(20)"`
+	ID             uuid.UUID   `gorm:"type:uuid;primaryKey"`
+	CourierID      *uuid.UUID  `gorm:"type:uuid;index"`
+	Address        AddressDTO  `gorm:"embedded;embeddedPrefix:address_"`
+	Location       LocationDTO `gorm:"embedded;embeddedPrefix:location_"`
+	Volume         int
+	Status         order.Status `gorm:"type:varchar(20)"`
+	CancelReason   string
+	DeliveryPeriod DeliveryPeriodDTO `gorm:"embedded;embeddedPrefix:delivery_"`
+	Version        int64             `gorm:"not null;default:0"`
+
+	// Время переходов ведёт репозиторий для метрик, в домен оно не попадает
+	CreatedAt   time.Time  `gorm:"<-:create;not null"`
+	AssignedAt  *time.Time `gorm:"->"`
+	CompletedAt *time.Time `gorm:"->"`
+}
+
+type AddressDTO struct {
+	Country   string `gorm:"not null;default:''"`
+	City      string `gorm:"not null;default:''"`
+	Street    string `gorm:"not null;default:''"`
+	House     string `gorm:"not null;default:''"`
+	Apartment string `gorm:"not null;default:''"`
 }
 
 type LocationDTO struct {
-	X uint8
-	Y uint8
+	X int
+	Y int
+}
+
+type DeliveryPeriodDTO struct {
+	From *time.Time
+	To   *time.Time
 }
 
 func (OrderDTO) TableName() string {
@@ -9,18 +9,41 @@ func DomainToDTO(aggregate *order.Order) OrderDTO {
 	var orderDTO OrderDTO
 	orderDTO.ID = aggregate.ID()
 	orderDTO.CourierID = aggregate.CourierID()
+	orderDTO.Address = AddressDTO{
+		Country:   aggregate.Address().Country(),
+		City:      aggregate.Address().City(),
+		Street:    aggregate.Address().Street(),
+		House:     aggregate.Address().House(),
+		Apartment: aggregate.Address().Apartment(),
+	}
 	orderDTO.Location = LocationDTO{
 		X: aggregate.Location().X(),
 		Y: aggregate.Location().Y(),
 	}
 	orderDTO.Volume = aggregate.Volume()
 	orderDTO.Status = aggregate.Status()
+	orderDTO.CancelReason = aggregate.CancelReason()
+	if deliveryPeriod := aggregate.DeliveryPeriod(); !deliveryPeriod.IsEmpty() {
+		from, to := deliveryPeriod.From(), deliveryPeriod.To()
+		orderDTO.DeliveryPeriod = DeliveryPeriodDTO{
+			From: &from,
+			To:   &to,
+		}
+	}
+	orderDTO.Version = aggregate.Version()
 	return orderDTO
 }
 
 func DtoToDomain(dto OrderDTO) *order.Order {
 	var aggregate *order.Order
-	location, _ := kernel.NewLocation(dto.Location.X, dto.Location.Y)
-	aggregate = order.RestoreOrder(dto.ID, dto.CourierID, location, dto.Volume, dto.Status)
+	address := kernel.RestoreAddress(dto.Address.Country, dto.Address.City, dto.Address.Street,
+		dto.Address.House, dto.Address.Apartment)
+	location := kernel.RestoreLocation(dto.Location.X, dto.Location.Y)
+	var deliveryPeriod order.DeliveryPeriod
+	if dto.DeliveryPeriod.From != nil && dto.DeliveryPeriod.To != nil {
+		deliveryPeriod, _ = order.NewDeliveryPeriod(*dto.DeliveryPeriod.From, *dto.DeliveryPeriod.To)
+	}
+	aggregate = order.RestoreOrder(dto.ID, dto.CourierID, address, location, dto.Volume, dto.Status, dto.CancelReason,
+		deliveryPeriod, dto.Version)
 	return aggregate
 }
@@ -6,16 +6,26 @@ import (
 	"delivery/internal/core/domain/model/order"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
 	"errors"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
 var _ ports.OrderRepository = &Repository{}
 
+// TransitionObserver узнаёт, сколько заказ ждал назначения и сколько его везли. Вызывается после фиксации транзакции
+type TransitionObserver interface {
+	OrderAssigned(latency time.Duration)
+	OrderCompleted(duration time.Duration)
+}
+
 type Repository struct {
 	txManager shared.TxManager
+	observer  TransitionObserver
 }
 
 func NewOrderRepository(tx shared.TxManager) (*Repository, error) {
@@ -28,7 +38,29 @@ func NewOrderRepository(tx shared.TxManager) (*Repository, error) {
 	}, nil
 }
 
+// NewOrderRepositoryWithObserver создаёт репозиторий, который сообщает observer о назначении и доставке заказов
+func NewOrderRepositoryWithObserver(tx shared.TxManager, observer TransitionObserver) (*Repository, error) {
+	if observer == nil {
+		return nil, errs.NewValueIsRequiredError("observer")
+	}
+
+	repository, err := NewOrderRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+	repository.observer = observer
+	return repository, nil
+}
+
+// Add, Update и Get отмечают спан идентификатором заказа, чтобы заказ можно было найти в трассах заданий
 func (r *Repository) Add(ctx context.Context, aggregate *order.Order) error {
+	ctx, span := startSpan(ctx, "OrderRepository.Add", aggregate.ID())
+	err := r.add(ctx, aggregate)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (r *Repository) add(ctx context.Context, aggregate *order.Order) error {
 	r.txManager.Track(aggregate)
 
 	dto := DomainToDTO(aggregate)
@@ -37,6 +69,7 @@ func (r *Repository) Add(ctx context.Context, aggregate *order.Order) error {
 	isInTransaction := r.txManager.InTx()
 	if !isInTransaction {
 		r.txManager.Begin(ctx)
+		defer r.txManager.Rollback(ctx)
 	}
 	tx := r.txManager.Tx()
 
@@ -57,6 +90,13 @@ func (r *Repository) Add(ctx context.Context, aggregate *order.Order) error {
 }
 
 func (r *Repository) Update(ctx context.Context, aggregate *order.Order) error {
+	ctx, span := startSpan(ctx, "OrderRepository.Update", aggregate.ID())
+	err := r.update(ctx, aggregate)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (r *Repository) update(ctx context.Context, aggregate *order.Order) error {
 	r.txManager.Track(aggregate)
 
 	dto := DomainToDTO(aggregate)
@@ -65,11 +105,25 @@ func (r *Repository) Update(ctx context.Context, aggregate *order.Order) error {
 	isInTransaction := r.txManager.InTx()
 	if !isInTransaction {
 		r.txManager.Begin(ctx)
+		defer r.txManager.Rollback(ctx)
 	}
 	tx := r.txManager.Tx()
 
-	// Вносим изменения
-	err := tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(&dto).Error
+	// Вносим изменения, только если заказ не изменили с момента чтения
+	expectedVersion := dto.Version
+	dto.Version = expectedVersion + 1
+	result := tx.WithContext(ctx).
+		Model(&dto).
+		Where("version = ?", expectedVersion).
+		Select("*").
+		Updates(&dto)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errs.NewVersionIsInvalidError("Order " + dto.ID.String())
+	}
+	err := r.markTransitionTime(ctx, tx, dto)
 	if err != nil {
 		return err
 	}
@@ -81,10 +135,49 @@ func (r *Repository) Update(ctx context.Context, aggregate *order.Order) error {
 			return err
 		}
 	}
+
+	aggregate.SetVersion(dto.Version)
+	return nil
+}
+
+// markTransitionTime запоминает, когда заказ впервые был назначен и доставлен, и сообщает длительность наблюдателю
+func (r *Repository) markTransitionTime(ctx context.Context, tx *gorm.DB, dto OrderDTO) error {
+	var seconds []float64
+	switch dto.Status {
+	case order.StatusAssigned:
+		err := tx.WithContext(ctx).
+			Raw(`UPDATE orders SET assigned_at = now() WHERE id = ? AND assigned_at IS NULL
+				RETURNING extract(epoch FROM assigned_at - created_at)`, dto.ID).Scan(&seconds).Error
+		if err != nil {
+			return err
+		}
+		r.observe(seconds, func(latency time.Duration) { r.observer.OrderAssigned(latency) })
+	case order.StatusCompleted:
+		err := tx.WithContext(ctx).
+			Raw(`UPDATE orders SET assigned_at = coalesce(assigned_at, now()), completed_at = coalesce(completed_at, now())
+				WHERE id = ? AND completed_at IS NULL
+				RETURNING extract(epoch FROM completed_at - assigned_at)`, dto.ID).Scan(&seconds).Error
+		if err != nil {
+			return err
+		}
+		r.observe(seconds, func(duration time.Duration) { r.observer.OrderCompleted(duration) })
+	}
 	return nil
 }
 
+// observe откладывает замер до фиксации транзакции: откаченный переход не попадает в метрики
+func (r *Repository) observe(seconds []float64, observe func(time.Duration)) {
+	if r.observer == nil || len(seconds) == 0 {
+		return
+	}
+	duration := time.Duration(seconds[0] * float64(time.Second))
+	r.txManager.AfterCommit(func() { observe(duration) })
+}
+
 func (r *Repository) Get(ctx context.Context, ID uuid.UUID) (*order.Order, error) {
+	ctx, span := startSpan(ctx, "OrderRepository.Get", ID)
+	defer span.End()
+
 	dto := OrderDTO{}
 
 	tx := r.getTxOrDb()
@@ -141,9 +234,84 @@ func (r *Repository) GetAllInAssignedStatus(ctx context.Context) ([]*order.Order
 	return aggregates, nil
 }
 
+// ClaimInCreatedStatus арендует до limit самых старых новых заказов. Строки блокируются только на время
+// запроса (FOR UPDATE SKIP LOCKED), а аренда остаётся после него: параллельные обработчики получают разные
+// заказы и не ждут друг друга. Пустой результат - не ошибка: все новые заказы уже арендованы
+func (r *Repository) ClaimInCreatedStatus(ctx context.Context, limit int, lease time.Duration) ([]*order.Order, error) {
+	if limit <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("limit", limit, 1, nil)
+	}
+	if lease <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("lease", lease, "1ns", nil)
+	}
+
+	tx := r.getTxOrDb().WithContext(ctx)
+	unclaimed := shared.WhereNotClaimed(tx.Model(&OrderDTO{}).Select("id")).
+		Where("status = ?", order.StatusCreated).
+		Order("created_at, id").
+		Limit(limit)
+	ids, err := shared.Claim(tx, "orders", unclaimed, lease)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*order.Order{}, nil
+	}
+
+	var dtos []OrderDTO
+	result := tx.Preload(clause.Associations).
+		Where("id IN ?", ids).
+		Order("created_at, id").
+		Find(&dtos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return dtosToDomain(dtos), nil
+}
+
+// ReleaseClaim снимает аренду ClaimInCreatedStatus
+func (r *Repository) ReleaseClaim(ctx context.Context, ids []uuid.UUID) error {
+	return shared.ReleaseClaim(r.getTxOrDb().WithContext(ctx), "orders", ids)
+}
+
+// LockInCreatedStatus блокирует выбранные заказы, которые всё ещё ждут назначения. Пустой результат - не ошибка:
+// все заказы уже забрали или изменили другие обработчики
+func (r *Repository) LockInCreatedStatus(ctx context.Context, ids []uuid.UUID) ([]*order.Order, error) {
+	tx, err := shared.SkipLocked(r.txManager)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*order.Order{}, nil
+	}
+
+	var dtos []OrderDTO
+	result := tx.WithContext(ctx).
+		Preload(clause.Associations).
+		Where("id IN ?", ids).
+		Where("status = ?", order.StatusCreated).
+		Find(&dtos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return dtosToDomain(dtos), nil
+}
+
+func dtosToDomain(dtos []OrderDTO) []*order.Order {
+	aggregates := make([]*order.Order, len(dtos))
+	for i, dto := range dtos {
+		aggregates[i] = DtoToDomain(dto)
+	}
+	return aggregates
+}
+
 func (r *Repository) getTxOrDb() *gorm.DB {
 	if tx := r.txManager.Tx(); tx != nil {
 		return tx
 	}
 	return r.txManager.Db()
 }
+
+func startSpan(ctx context.Context, name string, orderID uuid.UUID) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(tracing.OrderID(orderID)))
+}
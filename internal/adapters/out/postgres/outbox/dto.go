@@ -0,0 +1,19 @@
+package outbox
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type MessageDTO struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name           string    `gorm:"type:varchar(255)"`
+	Payload        []byte    `gorm:"type:jsonb"`
+	TraceContext   []byte    `gorm:"type:jsonb"`
+	OccurredAtUtc  time.Time `gorm:"index"`
+	ProcessedAtUtc *time.Time
+}
+
+func (MessageDTO) TableName() string {
+	return "outbox"
+}
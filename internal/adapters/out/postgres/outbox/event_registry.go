@@ -0,0 +1,67 @@
+package outbox
+
+import (
+	"delivery/internal/pkg/ddd"
+	"delivery/internal/pkg/errs"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type EventRegistry interface {
+	RegisterDomainEvent(name string, eventType reflect.Type) error
+	DecodeDomainEvent(message *MessageDTO) (ddd.DomainEvent, error)
+}
+
+var _ EventRegistry = &eventRegistry{}
+
+type eventRegistry struct {
+	mu         sync.RWMutex
+	eventTypes map[string]reflect.Type
+}
+
+func NewEventRegistry() EventRegistry {
+	return &eventRegistry{
+		eventTypes: make(map[string]reflect.Type),
+	}
+}
+
+func (r *eventRegistry) RegisterDomainEvent(name string, eventType reflect.Type) error {
+	if name == "" {
+		return errs.NewValueIsRequiredError("name")
+	}
+	if eventType == nil {
+		return errs.NewValueIsRequiredError("eventType")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventTypes[name] = eventType
+	return nil
+}
+
+func (r *eventRegistry) DecodeDomainEvent(message *MessageDTO) (ddd.DomainEvent, error) {
+	if message == nil {
+		return nil, errs.NewValueIsRequiredError("message")
+	}
+
+	r.mu.RLock()
+	eventType, ok := r.eventTypes[message.Name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, errs.NewObjectNotFoundError("Domain event type", message.Name)
+	}
+
+	eventPtr := reflect.New(eventType)
+	if err := json.Unmarshal(message.Payload, eventPtr.Interface()); err != nil {
+		return nil, err
+	}
+
+	domainEvent, ok := eventPtr.Elem().Interface().(ddd.DomainEvent)
+	if !ok {
+		return nil, errs.NewValueIsInvalidErrorWithCause("eventType",
+			fmt.Errorf("%s does not implement ddd.DomainEvent", eventType))
+	}
+	return domainEvent, nil
+}
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"delivery/internal/pkg/ddd"
+	"delivery/internal/pkg/errs"
+	"delivery/internal/pkg/tracing"
+	"encoding/json"
+	"time"
+)
+
+func EncodeDomainEvent(domainEvent ddd.DomainEvent) (MessageDTO, error) {
+	if domainEvent == nil {
+		return MessageDTO{}, errs.NewValueIsRequiredError("domainEvent")
+	}
+
+	payload, err := json.Marshal(domainEvent)
+	if err != nil {
+		return MessageDTO{}, err
+	}
+
+	return MessageDTO{
+		ID:            domainEvent.GetID(),
+		Name:          domainEvent.GetName(),
+		Payload:       payload,
+		OccurredAtUtc: time.Now().UTC(),
+	}, nil
+}
+
+func EncodeDomainEvents(domainEvents []ddd.DomainEvent) ([]MessageDTO, error) {
+	messages := make([]MessageDTO, 0, len(domainEvents))
+	for _, domainEvent := range domainEvents {
+		message, err := EncodeDomainEvent(domainEvent)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// EncodeTraceContext сохраняет контекст трассы, в которой возникло событие. Вне трассы возвращает nil
+func EncodeTraceContext(ctx context.Context) ([]byte, error) {
+	carrier := tracing.Inject(ctx)
+	if len(carrier) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(carrier)
+}
+
+// DecodeTraceContext продолжает трассу, в которой возникло событие. Если контекст
+// не сохранён или повреждён, возвращается ctx без изменений
+func DecodeTraceContext(ctx context.Context, message *MessageDTO) context.Context {
+	if message == nil || len(message.TraceContext) == 0 {
+		return ctx
+	}
+
+	var carrier map[string]string
+	if err := json.Unmarshal(message.TraceContext, &carrier); err != nil {
+		return ctx
+	}
+	return tracing.Extract(ctx, carrier)
+}
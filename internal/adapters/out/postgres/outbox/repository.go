@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"gorm.io/gorm"
+	"time"
+)
+
+const defaultBatchSize = 20
+
+type Repository interface {
+	GetNotPublishedMessages(ctx context.Context) ([]*MessageDTO, error)
+	MarkAsPublished(ctx context.Context, message *MessageDTO) error
+}
+
+var _ Repository = &repository{}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) (Repository, error) {
+	if db == nil {
+		return nil, errs.NewValueIsRequiredError("db")
+	}
+	return &repository{db: db}, nil
+}
+
+func (r *repository) GetNotPublishedMessages(ctx context.Context) ([]*MessageDTO, error) {
+	var messages []*MessageDTO
+	result := r.db.WithContext(ctx).
+		Where("processed_at_utc IS NULL").
+		Order("occurred_at_utc").
+		Limit(defaultBatchSize).
+		Find(&messages)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return messages, nil
+}
+
+func (r *repository) MarkAsPublished(ctx context.Context, message *MessageDTO) error {
+	if message == nil {
+		return errs.NewValueIsRequiredError("message")
+	}
+
+	processedAt := time.Now().UTC()
+	result := r.db.WithContext(ctx).
+		Model(message).
+		Update("processed_at_utc", processedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	message.ProcessedAtUtc = &processedAt
+	return nil
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"delivery/internal/adapters/out/postgres/orderrepo"
+	"delivery/internal/adapters/out/postgres/outbox"
+	"delivery/internal/core/domain/model/order"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func Test_TxManager_SavesDomainEventsToOutbox(t *testing.T) {
+	t.Run("Must save order status changed event on commit", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createOrderRepository(t, tx)
+
+		newOrder, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		dto := orderrepo.DomainToDTO(newOrder)
+		db.Create(&dto)
+
+		err = newOrder.Assign(uuid.New())
+		assert.NoError(t, err)
+
+		tx.Begin(ctx)
+		err = repo.Update(ctx, newOrder)
+		assert.NoError(t, err)
+		err = tx.Commit(ctx)
+		assert.NoError(t, err)
+
+		var messages []outbox.MessageDTO
+		err = db.Find(&messages).Error
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, order.StatusChangedDomainEventName, messages[0].Name)
+		assert.Nil(t, messages[0].ProcessedAtUtc)
+		assert.Empty(t, newOrder.GetDomainEvents())
+
+		registry := outbox.NewEventRegistry()
+		err = registry.RegisterDomainEvent(order.StatusChangedDomainEventName,
+			reflect.TypeOf(order.StatusChangedDomainEvent{}))
+		assert.NoError(t, err)
+
+		domainEvent, err := registry.DecodeDomainEvent(&messages[0])
+		assert.NoError(t, err)
+		event := domainEvent.(order.StatusChangedDomainEvent)
+		assert.Equal(t, newOrder.ID(), event.OrderID)
+		assert.Equal(t, order.StatusAssigned, event.OrderStatus)
+	})
+}
+
+func Test_OutboxRepository(t *testing.T) {
+	t.Run("Must return only not published messages", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		repo, err := outbox.NewRepository(db)
+		assert.NoError(t, err)
+
+		newOrder, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		_ = newOrder.Assign(uuid.New())
+		_ = newOrder.Complete()
+		messages, err := outbox.EncodeDomainEvents(newOrder.GetDomainEvents())
+		assert.NoError(t, err)
+		db.Create(&messages)
+
+		notPublished, err := repo.GetNotPublishedMessages(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, notPublished, 2)
+
+		err = repo.MarkAsPublished(ctx, notPublished[0])
+		assert.NoError(t, err)
+
+		notPublished, err = repo.GetNotPublishedMessages(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, notPublished, 1)
+	})
+}
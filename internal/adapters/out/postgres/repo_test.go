@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
-	"delivery/internal/adapters/out/postgres/courierrepo"
-	"delivery/internal/adapters/out/postgres/orderrepo"
+	"delivery/internal/adapters/out/postgres/migrations"
 	"delivery/internal/adapters/out/postgres/shared"
+	"delivery/internal/adapters/out/postgres/transportrepo"
+	"delivery/internal/core/domain/model/courier"
 	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/pkg/testcnts"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	postgresgorm "gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -24,15 +26,12 @@ func setupTest(t *testing.T) (context.Context, *gorm.DB) {
 	db, err := gorm.Open(postgresgorm.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
 
-	// Авто миграция (создаём таблицу)
-	err = db.AutoMigrate(&courierrepo.CourierDTO{})
+	// Накатываем схему теми же миграциями, что и в проде
+	sqlDb, err := db.DB()
 	assert.NoError(t, err)
-	err = db.AutoMigrate(&courierrepo.StoragePlaceDTO{})
+	migrator, err := migrations.NewMigrator(sqlDb)
 	assert.NoError(t, err)
-	err = db.AutoMigrate(&orderrepo.OrderDTO{})
-	assert.NoError(t, err)
-
-	err = db.AutoMigrate(&courierrepo.StoragePlaceDTO{})
+	err = migrator.Up(ctx)
 	assert.NoError(t, err)
 
 	// Очистка выполняется после завершения теста
@@ -50,10 +49,26 @@ func createTxManager(t *testing.T, db *gorm.DB) shared.TxManager {
 	return tx
 }
 
-func createTestLocation(t *testing.T, x uint8, y uint8) kernel.Location {
-	result, err := kernel.NewLocation(x, y)
+func createTestAddress(t *testing.T) kernel.Address {
+	address, err := kernel.NewAddress("Россия", "Москва", "Тестовая", "1", "10")
+	assert.NoError(t, err)
+	return address
+}
+
+func createTestLocation(t *testing.T, x int, y int) kernel.Location {
+	result, err := kernel.NewLocation(kernel.DefaultGrid(), x, y)
 	if err != nil {
 		assert.NoError(t, err)
 	}
 	return result
 }
+
+// createTestTransport добавляет в справочник транспорт без мест хранения, чтобы тесты сами задавали их набор
+func createTestTransport(t *testing.T, db *gorm.DB, speed int) courier.Transport {
+	transport, err := courier.NewTransport(uuid.New(), "test", speed, nil)
+	assert.NoError(t, err)
+	dto := transportrepo.DomainToDTO(transport)
+	err = db.Create(&dto).Error
+	assert.NoError(t, err)
+	return transport
+}
@@ -0,0 +1,36 @@
+package shared
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// WhereNotClaimed оставляет строки, которые не арендовал другой обработчик или аренда которых истекла
+func WhereNotClaimed(tx *gorm.DB) *gorm.DB {
+	return tx.Where("claimed_until IS NULL OR claimed_until < now()")
+}
+
+// Claim арендует строки table, выбранные candidates, до now() + lease и возвращает их идентификаторы.
+// Кандидаты блокируются с SKIP LOCKED только на время запроса: строки, которые прямо сейчас арендует
+// другой обработчик, пропускаются, а после запроса их защищает уже аренда, а не блокировка
+func Claim(tx *gorm.DB, table string, candidates *gorm.DB, lease time.Duration) ([]uuid.UUID, error) {
+	candidates = candidates.Clauses(clause.Locking{
+		Strength: clause.LockingStrengthUpdate,
+		Options:  clause.LockingOptionsSkipLocked,
+	})
+
+	var ids []uuid.UUID
+	err := tx.Raw("UPDATE ? SET claimed_until = now() + make_interval(secs => ?) WHERE id IN (?) RETURNING id",
+		clause.Table{Name: table}, lease.Seconds(), candidates).Scan(&ids).Error
+	return ids, err
+}
+
+// ReleaseClaim снимает аренду, чтобы строки сразу могли забрать другие обработчики
+func ReleaseClaim(tx *gorm.DB, table string, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Exec("UPDATE ? SET claimed_until = NULL WHERE id IN ?", clause.Table{Name: table}, ids).Error
+}
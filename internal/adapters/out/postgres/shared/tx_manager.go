@@ -2,12 +2,15 @@ package shared
 
 import (
 	"context"
+	"delivery/internal/adapters/out/postgres/outbox"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/ddd"
 	"delivery/internal/pkg/errs"
 	"errors"
+	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TxManager interface {
@@ -15,6 +18,8 @@ type TxManager interface {
 	Db() *gorm.DB
 	InTx() bool
 	Track(agg ddd.AggregateRoot)
+	// AfterCommit выполняет действие, только если текущая транзакция зафиксирована
+	AfterCommit(fn func())
 	ports.UnitOfWork
 }
 
@@ -25,6 +30,7 @@ type txManager struct {
 	tx                *gorm.DB
 	db                *gorm.DB
 	trackedAggregates []ddd.AggregateRoot
+	afterCommit       []func()
 }
 
 func NewTxManager(db *gorm.DB) (TxManager, error) {
@@ -54,6 +60,10 @@ func (u *txManager) Track(agg ddd.AggregateRoot) {
 	u.trackedAggregates = append(u.trackedAggregates, agg)
 }
 
+func (u *txManager) AfterCommit(fn func()) {
+	u.afterCommit = append(u.afterCommit, fn)
+}
+
 func (u *txManager) Begin(ctx context.Context) {
 	u.tx = u.db.WithContext(ctx).Begin()
 }
@@ -73,16 +83,95 @@ func (u *txManager) Commit(ctx context.Context) error {
 		}
 	}()
 
+	domainEvents := u.collectDomainEvents()
+	if err := u.saveDomainEvents(ctx, domainEvents); err != nil {
+		return err
+	}
+
 	if err := u.tx.WithContext(ctx).Commit().Error; err != nil {
 		return err
 	}
 	committed = true
+	u.clearDomainEvents()
+	afterCommit := u.afterCommit
 	u.clearTx()
+	for _, fn := range afterCommit {
+		fn()
+	}
 
 	return nil
 }
 
+// Rollback откатывает незакоммиченную транзакцию. После Commit ничего не делает
+func (u *txManager) Rollback(ctx context.Context) {
+	if u.tx == nil {
+		return
+	}
+
+	if err := u.tx.WithContext(ctx).Rollback().Error; err != nil && !errors.Is(err, gorm.ErrInvalidTransaction) {
+		log.Error(err)
+	}
+	u.clearTx()
+}
+
+// collectDomainEvents собирает события всех отслеживаемых агрегатов без повторов
+func (u *txManager) collectDomainEvents() []ddd.DomainEvent {
+	seen := make(map[uuid.UUID]struct{})
+	var domainEvents []ddd.DomainEvent
+	for _, agg := range u.trackedAggregates {
+		for _, domainEvent := range agg.GetDomainEvents() {
+			if _, ok := seen[domainEvent.GetID()]; ok {
+				continue
+			}
+			seen[domainEvent.GetID()] = struct{}{}
+			domainEvents = append(domainEvents, domainEvent)
+		}
+	}
+	return domainEvents
+}
+
+// saveDomainEvents сохраняет события в outbox в рамках текущей транзакции вместе с контекстом трассы
+func (u *txManager) saveDomainEvents(ctx context.Context, domainEvents []ddd.DomainEvent) error {
+	if len(domainEvents) == 0 {
+		return nil
+	}
+
+	messages, err := outbox.EncodeDomainEvents(domainEvents)
+	if err != nil {
+		return err
+	}
+	traceContext, err := outbox.EncodeTraceContext(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].TraceContext = traceContext
+	}
+	return u.tx.WithContext(ctx).Create(&messages).Error
+}
+
+// SkipLocked блокирует выбранные строки до конца транзакции и пропускает строки, уже заблокированные
+// другими транзакциями. Так несколько обработчиков разбирают разные строки, не дожидаясь друг друга.
+// Вне транзакции блокировка снялась бы сразу после запроса, поэтому без неё возвращается ошибка
+func SkipLocked(txManager TxManager) (*gorm.DB, error) {
+	tx := txManager.Tx()
+	if tx == nil {
+		return nil, errs.NewValueIsRequiredError("transaction")
+	}
+	return tx.Clauses(clause.Locking{
+		Strength: clause.LockingStrengthUpdate,
+		Options:  clause.LockingOptionsSkipLocked,
+	}), nil
+}
+
+func (u *txManager) clearDomainEvents() {
+	for _, agg := range u.trackedAggregates {
+		agg.ClearDomainEvents()
+	}
+}
+
 func (u *txManager) clearTx() {
 	u.tx = nil
 	u.trackedAggregates = nil
+	u.afterCommit = nil
 }
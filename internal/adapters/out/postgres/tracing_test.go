@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"delivery/internal/adapters/out/postgres/orderrepo"
+	"delivery/internal/adapters/out/postgres/outbox"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/pkg/tracing"
+	"delivery/internal/pkg/tracing/tracingtest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_Tracing(t *testing.T) {
+	t.Run("Must save trace context with outbox message and continue it on publish", func(t *testing.T) {
+		exporter := tracingtest.Use(t)
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createOrderRepository(t, tx)
+
+		newOrder, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t, 1, 1), 5)
+		assert.NoError(t, err)
+		dto := orderrepo.DomainToDTO(newOrder)
+		db.Create(&dto)
+		err = newOrder.Assign(uuid.New())
+		assert.NoError(t, err)
+
+		ctx, span := tracing.Tracer().Start(ctx, "AssignOrderJob")
+		tx.Begin(ctx)
+		err = repo.Update(ctx, newOrder)
+		assert.NoError(t, err)
+		err = tx.Commit(ctx)
+		assert.NoError(t, err)
+		span.End()
+
+		var messages []outbox.MessageDTO
+		err = db.Find(&messages).Error
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		restored := trace.SpanContextFromContext(outbox.DecodeTraceContext(context.Background(), &messages[0]))
+		assert.Equal(t, span.SpanContext().TraceID(), restored.TraceID())
+
+		update, ok := tracingtest.SpanByName(exporter, "OrderRepository.Update")
+		assert.True(t, ok)
+		assert.Equal(t, newOrder.ID().String(), tracingtest.Attribute(update, string(tracing.OrderIDKey)))
+	})
+
+	t.Run("Must record span for every GORM query", func(t *testing.T) {
+		exporter := tracingtest.Use(t)
+		ctx, db := setupTest(t)
+		err := db.Use(tracing.NewGormPlugin())
+		assert.NoError(t, err)
+
+		ctx, parent := tracing.Tracer().Start(ctx, "GetOrders")
+		var orders []orderrepo.OrderDTO
+		err = db.WithContext(ctx).Find(&orders).Error
+		parent.End()
+
+		assert.NoError(t, err)
+		span, ok := tracingtest.SpanByName(exporter, "gorm.query orders")
+		assert.True(t, ok)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		assert.Contains(t, tracingtest.Attribute(span, "db.query.text"), "FROM \"orders\"")
+	})
+
+	t.Run("Message without trace context stays in caller trace", func(t *testing.T) {
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1},
+		}))
+
+		restored := outbox.DecodeTraceContext(ctx, &outbox.MessageDTO{})
+
+		assert.Equal(t, trace.TraceID{1}, trace.SpanContextFromContext(restored).TraceID())
+	})
+}
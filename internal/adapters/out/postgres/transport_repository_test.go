@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"delivery/internal/adapters/out/postgres/shared"
+	"delivery/internal/adapters/out/postgres/transportrepo"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_TransportRepository_Get(t *testing.T) {
+	t.Run("Get transport from seeded catalogue", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createTransportRepository(t, tx)
+
+		car, err := repo.Get(ctx, uuid.MustParse("d719d5e7-c8a9-42d8-bed2-12acbea5908e"))
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Авто", car.Name())
+		assert.Equal(t, 3, car.Speed())
+		assert.Len(t, car.StorageLayout(), 1)
+		assert.Equal(t, "trunk", car.StorageLayout()[0].Name())
+	})
+
+	t.Run("Keep storage layout order", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createTransportRepository(t, tx)
+
+		trunk, _ := courier.NewStorageLayoutItem("trunk", 30)
+		bag, _ := courier.NewStorageLayoutItem("bag", 5)
+		expected, err := courier.NewTransport(uuid.New(), "Van", 2, []courier.StorageLayoutItem{trunk, bag})
+		assert.NoError(t, err)
+		dto := transportrepo.DomainToDTO(expected)
+		err = db.Create(&dto).Error
+		assert.NoError(t, err)
+
+		result, err := repo.Get(ctx, expected.ID())
+		assert.NoError(t, err)
+		assert.True(t, expected.Equals(result))
+	})
+
+	t.Run("Return not found for unknown transport", func(t *testing.T) {
+		ctx, db := setupTest(t)
+		tx := createTxManager(t, db)
+		repo := createTransportRepository(t, tx)
+
+		_, err := repo.Get(ctx, uuid.New())
+		assert.ErrorIs(t, err, errs.ErrObjectNotFound)
+	})
+}
+
+func createTransportRepository(t *testing.T, tx shared.TxManager) ports.TransportRepository {
+	repo, err := transportrepo.NewTransportRepository(tx)
+	assert.NoError(t, err)
+	return repo
+}
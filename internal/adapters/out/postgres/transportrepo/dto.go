@@ -0,0 +1,27 @@
+package transportrepo
+
+import (
+	"github.com/google/uuid"
+)
+
+type TransportDTO struct {
+	ID            uuid.UUID                   `gorm:"type:uuid;primaryKey"`
+	Name          string                      `gorm:"not null"`
+	Speed         int                         `gorm:"not null"`
+	StorageLayout []*TransportStoragePlaceDTO `gorm:"foreignKey:TransportID;constraint:OnDelete:CASCADE;"`
+}
+
+type TransportStoragePlaceDTO struct {
+	TransportID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Position    int       `gorm:"primaryKey"`
+	Name        string    `gorm:"not null"`
+	TotalVolume int       `gorm:"not null"`
+}
+
+func (TransportDTO) TableName() string {
+	return "transports"
+}
+
+func (TransportStoragePlaceDTO) TableName() string {
+	return "transport_storage_places"
+}
@@ -0,0 +1,39 @@
+package transportrepo
+
+import (
+	"delivery/internal/core/domain/model/courier"
+	"sort"
+)
+
+func DomainToDTO(transport courier.Transport) TransportDTO {
+	var transportDTO TransportDTO
+	transportDTO.ID = transport.ID()
+	transportDTO.Name = transport.Name()
+	transportDTO.Speed = transport.Speed()
+	transportDTO.StorageLayout = make([]*TransportStoragePlaceDTO, 0)
+	for position, item := range transport.StorageLayout() {
+		transportDTO.StorageLayout = append(transportDTO.StorageLayout, &TransportStoragePlaceDTO{
+			TransportID: transport.ID(),
+			Position:    position,
+			Name:        item.Name(),
+			TotalVolume: item.Volume(),
+		})
+	}
+	return transportDTO
+}
+
+func DtoToDomain(dto TransportDTO) (courier.Transport, error) {
+	dtoStorageLayout := append([]*TransportStoragePlaceDTO(nil), dto.StorageLayout...)
+	sort.Slice(dtoStorageLayout, func(i, j int) bool {
+		return dtoStorageLayout[i].Position < dtoStorageLayout[j].Position
+	})
+	storageLayout := make([]courier.StorageLayoutItem, 0, len(dtoStorageLayout))
+	for _, dtoItem := range dtoStorageLayout {
+		item, err := courier.NewStorageLayoutItem(dtoItem.Name, dtoItem.TotalVolume)
+		if err != nil {
+			return courier.Transport{}, err
+		}
+		storageLayout = append(storageLayout, item)
+	}
+	return courier.NewTransport(dto.ID, dto.Name, dto.Speed, storageLayout)
+}
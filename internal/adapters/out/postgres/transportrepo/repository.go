@@ -0,0 +1,63 @@
+package transportrepo
+
+import (
+	"context"
+	"delivery/internal/adapters/out/postgres/shared"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var _ ports.TransportRepository = &Repository{}
+
+// Repository читает справочник транспорта. Справочник заполняется миграциями
+type Repository struct {
+	txManager shared.TxManager
+}
+
+func NewTransportRepository(txManager shared.TxManager) (*Repository, error) {
+	if txManager == nil {
+		return nil, errs.NewValueIsRequiredError("uow")
+	}
+
+	return &Repository{txManager}, nil
+}
+
+func (r *Repository) Get(ctx context.Context, ID uuid.UUID) (courier.Transport, error) {
+	if ID == uuid.Nil {
+		return courier.Transport{}, errs.NewValueIsRequiredError("ID")
+	}
+
+	dto := TransportDTO{}
+
+	tx := r.getTxOrDb()
+	result := WithStorageLayout(tx.WithContext(ctx), "StorageLayout").
+		Find(&dto, ID)
+
+	if result.Error != nil {
+		return courier.Transport{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return courier.Transport{}, errs.NewObjectNotFoundError("Transport by ID", ID)
+	}
+
+	return DtoToDomain(dto)
+}
+
+// WithStorageLayout подгружает места хранения транспорта в исходном порядке.
+// field - путь до ассоциации, например "Transport.StorageLayout" при загрузке курьера
+func WithStorageLayout(tx *gorm.DB, field string) *gorm.DB {
+	return tx.Preload(field, func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	})
+}
+
+func (r *Repository) getTxOrDb() *gorm.DB {
+	if tx := r.txManager.Tx(); tx != nil {
+		return tx
+	}
+	return r.txManager.Db()
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"strings"
+)
+
+type AddStoragePlaceCmd struct {
+	courierID   uuid.UUID
+	name        string
+	totalVolume int
+
+	isSet bool
+}
+
+func NewAddStoragePlaceCmd(courierID uuid.UUID, name string, totalVolume int) (AddStoragePlaceCmd, error) {
+	if courierID == uuid.Nil {
+		return AddStoragePlaceCmd{}, errs.NewValueIsRequiredError("courierID")
+	}
+	if strings.TrimSpace(name) == "" {
+		return AddStoragePlaceCmd{}, errs.NewValueIsRequiredError("name")
+	}
+	if totalVolume < courier.MinVolume {
+		return AddStoragePlaceCmd{}, errs.NewValueIsRequiredError("totalVolume")
+	}
+
+	return AddStoragePlaceCmd{
+		courierID:   courierID,
+		name:        name,
+		totalVolume: totalVolume,
+		isSet:       true,
+	}, nil
+}
+
+func (cmd AddStoragePlaceCmd) CourierID() uuid.UUID {
+	return cmd.courierID
+}
+
+func (cmd AddStoragePlaceCmd) Name() string {
+	return cmd.name
+}
+
+func (cmd AddStoragePlaceCmd) TotalVolume() int {
+	return cmd.totalVolume
+}
+
+func (cmd AddStoragePlaceCmd) IsEmpty() bool {
+	return !cmd.isSet
+}
+
+type AddStoragePlaceCommandHandler interface {
+	Handle(ctx context.Context, cmd AddStoragePlaceCmd) error
+}
+
+var _ AddStoragePlaceCommandHandler = &addStoragePlaceCommandHandler{}
+
+type addStoragePlaceCommandHandler struct {
+	unitOfWork        ports.UnitOfWork
+	courierRepository ports.CourierRepository
+}
+
+func NewAddStoragePlaceCommandHandler(
+	unitOfWork ports.UnitOfWork,
+	courierRepository ports.CourierRepository,
+) (AddStoragePlaceCommandHandler, error) {
+	if unitOfWork == nil {
+		return nil, errs.NewValueIsRequiredError("unitOfWork")
+	}
+	if courierRepository == nil {
+		return nil, errs.NewValueIsRequiredError("courierRepository")
+	}
+
+	return &addStoragePlaceCommandHandler{
+		unitOfWork:        unitOfWork,
+		courierRepository: courierRepository,
+	}, nil
+}
+
+func (ch *addStoragePlaceCommandHandler) Handle(ctx context.Context, cmd AddStoragePlaceCmd) error {
+	if cmd.IsEmpty() {
+		return errs.NewValueIsRequiredError("cmd")
+	}
+
+	return updateCourier(ctx, ch.unitOfWork, ch.courierRepository, cmd.CourierID(), func(c *courier.Courier) error {
+		return c.AddStoragePlace(cmd.Name(), cmd.TotalVolume())
+	})
+}
@@ -2,10 +2,15 @@ package commands
 
 import (
 	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/order"
 	"delivery/internal/core/domain/services"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/errs"
 	"errors"
+	"github.com/google/uuid"
+	"slices"
+	"time"
 )
 
 var (
@@ -13,6 +18,14 @@ var (
 	NotAvailableCouriers = errors.New("not available couriers")
 )
 
+const (
+	// AssignBatchSize - сколько новых заказов и свободных курьеров один обработчик забирает за раз,
+	// остальные достаются параллельным
+	AssignBatchSize = 100
+	// AssignClaimLease - на сколько обработчик арендует пачку. Если он упал, не отпустив её, пачку заберут другие
+	AssignClaimLease = 30 * time.Second
+)
+
 type AssignOrderCmd struct {
 	isSet bool
 }
@@ -35,12 +48,14 @@ type assignOrdersCommandHandler struct {
 	unitOfWork        ports.UnitOfWork
 	orderRepository   ports.OrderRepository
 	courierRepository ports.CourierRepository
-	orderDispatcher   services.OrderDispatcher
+	batchDispatcher   services.BatchDispatcher
+	routePlanner      services.RoutePlanner
 }
 
 func NewAssignOrderCommandHandler(
 	unitOfWork ports.UnitOfWork,
-	orderDispatcher services.OrderDispatcher,
+	batchDispatcher services.BatchDispatcher,
+	routePlanner services.RoutePlanner,
 	orderRepository ports.OrderRepository,
 	courierRepository ports.CourierRepository,
 ) (AssignOrderCommandHandler, error) {
@@ -53,60 +68,130 @@ func NewAssignOrderCommandHandler(
 	if courierRepository == nil {
 		return nil, errs.NewValueIsRequiredError("courierRepository")
 	}
-	if orderDispatcher == nil {
-		return nil, errs.NewValueIsRequiredError("orderDispatcher")
+	if batchDispatcher == nil {
+		return nil, errs.NewValueIsRequiredError("batchDispatcher")
+	}
+	if routePlanner == nil {
+		return nil, errs.NewValueIsRequiredError("routePlanner")
 	}
 
 	return &assignOrdersCommandHandler{
 		unitOfWork:        unitOfWork,
 		orderRepository:   orderRepository,
 		courierRepository: courierRepository,
-		orderDispatcher:   orderDispatcher}, nil
+		batchDispatcher:   batchDispatcher,
+		routePlanner:      routePlanner}, nil
 }
 
-func (ch *assignOrdersCommandHandler) Handle(ctx context.Context, command AssignOrderCmd) error {
+func (ch *assignOrdersCommandHandler) Handle(ctx context.Context, command AssignOrderCmd) (err error) {
 	if command.IsEmpty() {
 		return errs.NewValueIsRequiredError("command")
 	}
 
-	orderAggregate, err := ch.orderRepository.GetFirstInCreatedStatus(ctx)
+	// Пачка заказов и курьеров арендуется сразу, поэтому параллельные обработчики распределяют разные заказы
+	// между разными курьерами. Блокировок при этом нет: пока работают венгерский алгоритм и поиск путей,
+	// перемещение курьеров и HTTP запросы не ждут этот обработчик
+	orders, err := ch.orderRepository.ClaimInCreatedStatus(ctx, AssignBatchSize, AssignClaimLease)
 	if err != nil {
-		if errors.Is(err, errs.ErrObjectNotFound) {
-			return NotAvailableOrders
-		}
 		return err
 	}
+	if len(orders) == 0 {
+		return NotAvailableOrders
+	}
+	defer func() { err = errors.Join(err, ch.orderRepository.ReleaseClaim(ctx, orderIDs(orders))) }()
 
-	couriers, err := ch.courierRepository.GetAllFree(ctx)
+	couriers, err := ch.courierRepository.ClaimFree(ctx, AssignBatchSize, AssignClaimLease)
 	if err != nil {
-		if errors.Is(err, errs.ErrObjectNotFound) {
-			return NotAvailableCouriers
-		}
 		return err
 	}
-	if couriers == nil || len(couriers) == 0 {
+	if len(couriers) == 0 {
 		return NotAvailableCouriers
 	}
+	defer func() { err = errors.Join(err, ch.courierRepository.ReleaseClaim(ctx, courierIDs(couriers))) }()
 
-	courier, err := ch.orderDispatcher.Dispatch(orderAggregate, couriers)
+	assignments, err := ch.batchDispatcher.Dispatch(orders, couriers)
 	if err != nil {
 		return err
 	}
 
+	// Новые заказы встали в конец маршрутов - перестраиваем порядок объезда один раз для каждого курьера
+	var assignedCouriers []*courier.Courier
+	ordersByCourier := make(map[uuid.UUID][]*order.Order)
+	for _, assignment := range assignments {
+		courierID := assignment.Courier.ID()
+		if _, ok := ordersByCourier[courierID]; !ok {
+			assignedCouriers = append(assignedCouriers, assignment.Courier)
+			err = ch.routePlanner.Plan(assignment.Courier)
+			if err != nil {
+				return err
+			}
+		}
+		ordersByCourier[courierID] = append(ordersByCourier[courierID], assignment.Order)
+	}
+
+	for _, assignedCourier := range assignedCouriers {
+		err = ch.save(ctx, assignedCourier, ordersByCourier[assignedCourier.ID()])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save сохраняет заказы одного курьера в своей транзакции и блокирует только их. Курьер, которого за это время
+// занял или изменил другой обработчик, пропускается вместе со всеми своими заказами, а заказ, который уже
+// забрали, - вместе со своим курьером. Назначения остальных курьеров сохраняются, а пропущенные заказы
+// попадут в следующий запуск
+func (ch *assignOrdersCommandHandler) save(ctx context.Context, assignedCourier *courier.Courier,
+	assignedOrders []*order.Order) error {
 	ch.unitOfWork.Begin(ctx)
+	defer ch.unitOfWork.Rollback(ctx)
 
-	err = ch.orderRepository.Update(ctx, orderAggregate)
+	lockedOrders, err := ch.orderRepository.LockInCreatedStatus(ctx, orderIDs(assignedOrders))
 	if err != nil {
 		return err
 	}
-	err = ch.courierRepository.Update(ctx, courier)
+	lockedCouriers, err := ch.courierRepository.LockFree(ctx, []uuid.UUID{assignedCourier.ID()})
 	if err != nil {
 		return err
 	}
+	if len(lockedCouriers) == 0 || lockedCouriers[0].Version() != assignedCourier.Version() {
+		return nil
+	}
+	for _, assignedOrder := range assignedOrders {
+		isUnchanged := slices.ContainsFunc(lockedOrders, func(o *order.Order) bool {
+			return o.ID() == assignedOrder.ID() && o.Version() == assignedOrder.Version()
+		})
+		if !isUnchanged {
+			return nil
+		}
+	}
 
-	err = ch.unitOfWork.Commit(ctx)
+	for _, assignedOrder := range assignedOrders {
+		err = ch.orderRepository.Update(ctx, assignedOrder)
+		if err != nil {
+			return skipOnVersionConflict(err)
+		}
+	}
+	err = ch.courierRepository.Update(ctx, assignedCourier)
 	if err != nil {
-		return err
+		return skipOnVersionConflict(err)
 	}
-	return nil
+	return ch.unitOfWork.Commit(ctx)
+}
+
+func orderIDs(orders []*order.Order) []uuid.UUID {
+	ids := make([]uuid.UUID, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID()
+	}
+	return ids
+}
+
+func courierIDs(couriers []*courier.Courier) []uuid.UUID {
+	ids := make([]uuid.UUID, len(couriers))
+	for i, c := range couriers {
+		ids[i] = c.ID()
+	}
+	return ids
 }
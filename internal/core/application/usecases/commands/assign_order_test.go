@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/domain/services"
+	"delivery/internal/pkg/errs"
+	"delivery/mocks/core/ports"
+	"github.com/stretchr/testify/mock"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AssignOrders_Handle(t *testing.T) {
+	t.Run("Return NotAvailableOrders if there are no created orders", func(t *testing.T) {
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		orderRepo.On("ClaimInCreatedStatus", mock.Anything, AssignBatchSize, AssignClaimLease).
+			Return([]*order.Order{}, nil)
+
+		handler, _ := NewAssignOrderCommandHandler(uow, services.NewBatchDispatcher(), services.NewRoutePlanner(),
+			orderRepo, courierRepo)
+
+		err := handler.Handle(context.Background(), NewAssignOrdersCommand())
+		assert.ErrorIs(t, err, NotAvailableOrders)
+		uow.AssertNotCalled(t, "Begin", mock.Anything)
+	})
+
+	t.Run("Release claimed orders if there are no free couriers", func(t *testing.T) {
+		o := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 4, 1))
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		orderRepo.On("ClaimInCreatedStatus", mock.Anything, AssignBatchSize, AssignClaimLease).
+			Return([]*order.Order{o}, nil)
+		courierRepo.On("ClaimFree", mock.Anything, AssignBatchSize, AssignClaimLease).
+			Return([]*courier.Courier{}, nil)
+		orderRepo.On("ReleaseClaim", mock.Anything, []uuid.UUID{o.ID()}).Return(nil).Once()
+
+		handler, _ := NewAssignOrderCommandHandler(uow, services.NewBatchDispatcher(), services.NewRoutePlanner(),
+			orderRepo, courierRepo)
+
+		err := handler.Handle(context.Background(), NewAssignOrdersCommand())
+		assert.ErrorIs(t, err, NotAvailableCouriers)
+		uow.AssertNotCalled(t, "Begin", mock.Anything)
+	})
+
+	t.Run("Persist assignments of each courier in its own unit of work", func(t *testing.T) {
+		first := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 4, 1))
+		second := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 6, 1))
+		near := createTestCourier(t, createTestLocation(t, 5, 1), 1)
+		far := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = near.AddStoragePlace("bag", 10)
+		_ = far.AddStoragePlace("bag", 10)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return().Twice()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil).Twice()
+		claimOrdersAndCouriers(orderRepo, courierRepo, []*order.Order{first, second}, []*courier.Courier{near, far})
+		orderRepo.On("LockInCreatedStatus", mock.Anything, []uuid.UUID{first.ID()}).Return([]*order.Order{first}, nil)
+		orderRepo.On("LockInCreatedStatus", mock.Anything, []uuid.UUID{second.ID()}).Return([]*order.Order{second}, nil)
+		courierRepo.On("LockFree", mock.Anything, []uuid.UUID{near.ID()}).Return([]*courier.Courier{near}, nil)
+		courierRepo.On("LockFree", mock.Anything, []uuid.UUID{far.ID()}).Return([]*courier.Courier{far}, nil)
+		orderRepo.On("Update", mock.Anything, first).Return(nil)
+		orderRepo.On("Update", mock.Anything, second).Return(nil)
+		courierRepo.On("Update", mock.Anything, near).Return(nil)
+		courierRepo.On("Update", mock.Anything, far).Return(nil)
+
+		handler, _ := NewAssignOrderCommandHandler(uow, services.NewBatchDispatcher(), services.NewRoutePlanner(),
+			orderRepo, courierRepo)
+
+		err := handler.Handle(context.Background(), NewAssignOrdersCommand())
+		assert.NoError(t, err)
+		assert.Equal(t, far.ID(), *first.CourierID())
+		assert.Equal(t, near.ID(), *second.CourierID())
+	})
+
+	t.Run("Skip the courier taken by another handler together with its orders", func(t *testing.T) {
+		first := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 4, 1))
+		second := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 6, 1))
+		near := createTestCourier(t, createTestLocation(t, 5, 1), 1)
+		far := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = near.AddStoragePlace("bag", 10)
+		_ = far.AddStoragePlace("bag", 10)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return().Twice()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil).Once()
+		claimOrdersAndCouriers(orderRepo, courierRepo, []*order.Order{first, second}, []*courier.Courier{near, far})
+		orderRepo.On("LockInCreatedStatus", mock.Anything, mock.Anything).Return([]*order.Order{first, second}, nil)
+		// near уже заблокирован другим обработчиком
+		courierRepo.On("LockFree", mock.Anything, []uuid.UUID{near.ID()}).Return([]*courier.Courier{}, nil)
+		courierRepo.On("LockFree", mock.Anything, []uuid.UUID{far.ID()}).Return([]*courier.Courier{far}, nil)
+		orderRepo.On("Update", mock.Anything, first).Return(nil).Once()
+		courierRepo.On("Update", mock.Anything, far).Return(nil).Once()
+
+		handler, _ := NewAssignOrderCommandHandler(uow, services.NewBatchDispatcher(), services.NewRoutePlanner(),
+			orderRepo, courierRepo)
+
+		err := handler.Handle(context.Background(), NewAssignOrdersCommand())
+		assert.NoError(t, err)
+		orderRepo.AssertNotCalled(t, "Update", mock.Anything, second)
+		courierRepo.AssertNotCalled(t, "Update", mock.Anything, near)
+	})
+
+	t.Run("Keep other couriers' assignments on a version conflict", func(t *testing.T) {
+		first := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 4, 1))
+		second := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 6, 1))
+		near := createTestCourier(t, createTestLocation(t, 5, 1), 1)
+		far := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = near.AddStoragePlace("bag", 10)
+		_ = far.AddStoragePlace("bag", 10)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return().Twice()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil).Once()
+		claimOrdersAndCouriers(orderRepo, courierRepo, []*order.Order{first, second}, []*courier.Courier{near, far})
+		orderRepo.On("LockInCreatedStatus", mock.Anything, mock.Anything).Return([]*order.Order{first, second}, nil)
+		courierRepo.On("LockFree", mock.Anything, []uuid.UUID{near.ID()}).Return([]*courier.Courier{near}, nil)
+		courierRepo.On("LockFree", mock.Anything, []uuid.UUID{far.ID()}).Return([]*courier.Courier{far}, nil)
+		orderRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		courierRepo.On("Update", mock.Anything, near).Return(errs.NewVersionIsInvalidError("Courier"))
+		courierRepo.On("Update", mock.Anything, far).Return(nil)
+
+		handler, _ := NewAssignOrderCommandHandler(uow, services.NewBatchDispatcher(), services.NewRoutePlanner(),
+			orderRepo, courierRepo)
+
+		err := handler.Handle(context.Background(), NewAssignOrdersCommand())
+		assert.NoError(t, err)
+		courierRepo.AssertCalled(t, "Update", mock.Anything, far)
+	})
+}
+
+// claimOrdersAndCouriers - обработчик арендует пачку и в конце запуска снимает аренду со всей пачки
+func claimOrdersAndCouriers(orderRepo *ports.MockOrderRepository, courierRepo *ports.MockCourierRepository,
+	orders []*order.Order, couriers []*courier.Courier) {
+	orderRepo.On("ClaimInCreatedStatus", mock.Anything, AssignBatchSize, AssignClaimLease).Return(orders, nil)
+	courierRepo.On("ClaimFree", mock.Anything, AssignBatchSize, AssignClaimLease).Return(couriers, nil)
+	orderRepo.On("ReleaseClaim", mock.Anything, orderIDs(orders)).Return(nil).Once()
+	courierRepo.On("ReleaseClaim", mock.Anything, courierIDs(couriers)).Return(nil).Once()
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"strings"
+)
+
+type CancelOrderCmd struct {
+	orderID uuid.UUID
+	reason  string
+
+	isSet bool
+}
+
+func NewCancelOrderCmd(orderID uuid.UUID, reason string) (CancelOrderCmd, error) {
+	if orderID == uuid.Nil {
+		return CancelOrderCmd{}, errs.NewValueIsRequiredError("orderID")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return CancelOrderCmd{}, errs.NewValueIsRequiredError("reason")
+	}
+
+	return CancelOrderCmd{
+		orderID: orderID,
+		reason:  reason,
+		isSet:   true,
+	}, nil
+}
+
+func (cmd CancelOrderCmd) OrderID() uuid.UUID {
+	return cmd.orderID
+}
+
+func (cmd CancelOrderCmd) Reason() string {
+	return cmd.reason
+}
+
+func (cmd CancelOrderCmd) IsEmpty() bool {
+	return !cmd.isSet
+}
+
+type CancelOrderCommandHandler interface {
+	Handle(ctx context.Context, cmd CancelOrderCmd) error
+}
+
+var _ CancelOrderCommandHandler = &cancelOrderCommandHandler{}
+
+type cancelOrderCommandHandler struct {
+	unitOfWork        ports.UnitOfWork
+	orderRepository   ports.OrderRepository
+	courierRepository ports.CourierRepository
+}
+
+func NewCancelOrderCommandHandler(
+	unitOfWork ports.UnitOfWork,
+	orderRepository ports.OrderRepository,
+	courierRepository ports.CourierRepository,
+) (CancelOrderCommandHandler, error) {
+	if unitOfWork == nil {
+		return nil, errs.NewValueIsRequiredError("unitOfWork")
+	}
+	if orderRepository == nil {
+		return nil, errs.NewValueIsRequiredError("orderRepository")
+	}
+	if courierRepository == nil {
+		return nil, errs.NewValueIsRequiredError("courierRepository")
+	}
+
+	return &cancelOrderCommandHandler{
+		unitOfWork:        unitOfWork,
+		orderRepository:   orderRepository,
+		courierRepository: courierRepository,
+	}, nil
+}
+
+func (ch *cancelOrderCommandHandler) Handle(ctx context.Context, cmd CancelOrderCmd) error {
+	if cmd.IsEmpty() {
+		return errs.NewValueIsRequiredError("cmd")
+	}
+
+	// Отмену инициирует пользователь, поэтому при конфликте версий перечитываем агрегаты и повторяем
+	return retryOnVersionConflict(ctx, func(ctx context.Context) error {
+		return ch.cancel(ctx, cmd)
+	})
+}
+
+func (ch *cancelOrderCommandHandler) cancel(ctx context.Context, cmd CancelOrderCmd) error {
+	orderAggregate, err := ch.orderRepository.Get(ctx, cmd.OrderID())
+	if err != nil {
+		return err
+	}
+	if orderAggregate == nil {
+		return errs.NewObjectNotFoundError("orderID", cmd.OrderID())
+	}
+
+	wasAssigned := orderAggregate.Status() == order.StatusAssigned && orderAggregate.CourierID() != nil
+	err = orderAggregate.Cancel(cmd.Reason())
+	if err != nil {
+		return err
+	}
+
+	// Если заказ уже у курьера — освобождаем место хранения
+	var courierAggregate *courier.Courier
+	if wasAssigned {
+		courierAggregate, err = ch.courierRepository.Get(ctx, *orderAggregate.CourierID())
+		if err != nil {
+			return err
+		}
+		err = courierAggregate.ReleaseOrder(orderAggregate)
+		if err != nil {
+			return err
+		}
+	}
+
+	ch.unitOfWork.Begin(ctx)
+	defer ch.unitOfWork.Rollback(ctx)
+
+	err = ch.orderRepository.Update(ctx, orderAggregate)
+	if err != nil {
+		return err
+	}
+	if courierAggregate != nil {
+		err = ch.courierRepository.Update(ctx, courierAggregate)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ch.unitOfWork.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
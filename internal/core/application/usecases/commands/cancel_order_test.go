@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/pkg/errs"
+	"delivery/mocks/core/ports"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_CancelOrder_NewCmd(t *testing.T) {
+	t.Run("Return err with nil order id", func(t *testing.T) {
+		_, err := NewCancelOrderCmd(uuid.Nil, "reason")
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+	t.Run("Return err with empty reason", func(t *testing.T) {
+		_, err := NewCancelOrderCmd(uuid.New(), " ")
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func Test_CancelOrder_Handle(t *testing.T) {
+	t.Run("Return not found if order does not exist", func(t *testing.T) {
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		orderID := uuid.New()
+		orderRepo.On("Get", mock.Anything, orderID).Return(nil, nil)
+
+		handler, _ := NewCancelOrderCommandHandler(uow, orderRepo, courierRepo)
+		cmd, _ := NewCancelOrderCmd(orderID, "reason")
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, errs.ErrObjectNotFound)
+	})
+
+	t.Run("Cancel created order without touching couriers", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 5, createTestLocation(t, 3, 3))
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
+		orderRepo.On("Get", mock.Anything, testOrder.ID()).Return(testOrder, nil)
+		orderRepo.On("Update", mock.Anything, testOrder).Return(nil)
+
+		handler, _ := NewCancelOrderCommandHandler(uow, orderRepo, courierRepo)
+		cmd, _ := NewCancelOrderCmd(testOrder.ID(), "reason")
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, order.StatusCancelled, testOrder.Status())
+	})
+
+	t.Run("Cancel assigned order and release courier storage", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 5, createTestLocation(t, 3, 3))
+		testCourier := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = testCourier.AddStoragePlace("bag", 10)
+		_ = testOrder.Assign(testCourier.ID())
+		_ = testCourier.TakeOrder(testOrder)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
+		orderRepo.On("Get", mock.Anything, testOrder.ID()).Return(testOrder, nil)
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+		orderRepo.On("Update", mock.Anything, testOrder).Return(nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil)
+
+		handler, _ := NewCancelOrderCommandHandler(uow, orderRepo, courierRepo)
+		cmd, _ := NewCancelOrderCmd(testOrder.ID(), "reason")
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, order.StatusCancelled, testOrder.Status())
+		assert.Nil(t, testCourier.StoragePlaces()[0].OrderID())
+	})
+
+	t.Run("Return err if order is completed", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 5, createTestLocation(t, 3, 3))
+		_ = testOrder.Assign(uuid.New())
+		_ = testOrder.Complete()
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		orderRepo.On("Get", mock.Anything, testOrder.ID()).Return(testOrder, nil)
+
+		handler, _ := NewCancelOrderCommandHandler(uow, orderRepo, courierRepo)
+		cmd, _ := NewCancelOrderCmd(testOrder.ID(), "reason")
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, order.ErrOrderHasAlreadyBeenCompleted)
+	})
+
+	t.Run("Retry on version conflict with fresh order", func(t *testing.T) {
+		orderID := uuid.New()
+		staleOrder := createTestOrder(t, orderID, 5, createTestLocation(t, 3, 3))
+		freshOrder := createTestOrder(t, orderID, 5, createTestLocation(t, 3, 3))
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil).Once()
+		orderRepo.On("Get", mock.Anything, orderID).Return(staleOrder, nil).Once()
+		orderRepo.On("Get", mock.Anything, orderID).Return(freshOrder, nil).Once()
+		orderRepo.On("Update", mock.Anything, staleOrder).Return(errs.NewVersionIsInvalidError("Order")).Once()
+		orderRepo.On("Update", mock.Anything, freshOrder).Return(nil).Once()
+
+		handler, _ := NewCancelOrderCommandHandler(uow, orderRepo, courierRepo)
+		cmd, _ := NewCancelOrderCmd(orderID, "reason")
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, order.StatusCancelled, freshOrder.Status())
+	})
+
+	t.Run("Return version conflict when attempts are exhausted", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 5, createTestLocation(t, 3, 3))
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		orderRepo.On("Get", mock.Anything, testOrder.ID()).Return(func(context.Context, uuid.UUID) *order.Order {
+			return createTestOrder(t, testOrder.ID(), 5, createTestLocation(t, 3, 3))
+		}, nil)
+		orderRepo.On("Update", mock.Anything, mock.Anything).Return(errs.NewVersionIsInvalidError("Order"))
+
+		handler, _ := NewCancelOrderCommandHandler(uow, orderRepo, courierRepo)
+		cmd, _ := NewCancelOrderCmd(testOrder.ID(), "reason")
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, errs.ErrVersionIsInvalid)
+		orderRepo.AssertNumberOfCalls(t, "Update", maxVersionConflictAttempts)
+	})
+}
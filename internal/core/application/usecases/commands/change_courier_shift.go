@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+)
+
+// ShiftAction - действие со сменой курьера
+type ShiftAction string
+
+const (
+	ShiftActionStartShift ShiftAction = "StartShift"
+	ShiftActionEndShift   ShiftAction = "EndShift"
+	ShiftActionStartBreak ShiftAction = "StartBreak"
+	ShiftActionEndBreak   ShiftAction = "EndBreak"
+)
+
+type ChangeCourierShiftCmd struct {
+	courierID uuid.UUID
+	action    ShiftAction
+
+	isSet bool
+}
+
+func NewChangeCourierShiftCmd(courierID uuid.UUID, action ShiftAction) (ChangeCourierShiftCmd, error) {
+	if courierID == uuid.Nil {
+		return ChangeCourierShiftCmd{}, errs.NewValueIsRequiredError("courierID")
+	}
+	switch action {
+	case ShiftActionStartShift, ShiftActionEndShift, ShiftActionStartBreak, ShiftActionEndBreak:
+	default:
+		return ChangeCourierShiftCmd{}, errs.NewValueIsInvalidError("action")
+	}
+
+	return ChangeCourierShiftCmd{
+		courierID: courierID,
+		action:    action,
+		isSet:     true,
+	}, nil
+}
+
+func (cmd ChangeCourierShiftCmd) CourierID() uuid.UUID {
+	return cmd.courierID
+}
+
+func (cmd ChangeCourierShiftCmd) Action() ShiftAction {
+	return cmd.action
+}
+
+func (cmd ChangeCourierShiftCmd) IsEmpty() bool {
+	return !cmd.isSet
+}
+
+type ChangeCourierShiftCommandHandler interface {
+	Handle(ctx context.Context, cmd ChangeCourierShiftCmd) error
+}
+
+var _ ChangeCourierShiftCommandHandler = &changeCourierShiftCommandHandler{}
+
+type changeCourierShiftCommandHandler struct {
+	unitOfWork        ports.UnitOfWork
+	courierRepository ports.CourierRepository
+}
+
+func NewChangeCourierShiftCommandHandler(
+	unitOfWork ports.UnitOfWork,
+	courierRepository ports.CourierRepository,
+) (ChangeCourierShiftCommandHandler, error) {
+	if unitOfWork == nil {
+		return nil, errs.NewValueIsRequiredError("unitOfWork")
+	}
+	if courierRepository == nil {
+		return nil, errs.NewValueIsRequiredError("courierRepository")
+	}
+
+	return &changeCourierShiftCommandHandler{
+		unitOfWork:        unitOfWork,
+		courierRepository: courierRepository,
+	}, nil
+}
+
+func (ch *changeCourierShiftCommandHandler) Handle(ctx context.Context, cmd ChangeCourierShiftCmd) error {
+	if cmd.IsEmpty() {
+		return errs.NewValueIsRequiredError("cmd")
+	}
+
+	return updateCourier(ctx, ch.unitOfWork, ch.courierRepository, cmd.CourierID(), func(c *courier.Courier) error {
+		return applyShiftAction(c, cmd.Action())
+	})
+}
+
+func applyShiftAction(courierAggregate *courier.Courier, action ShiftAction) error {
+	switch action {
+	case ShiftActionStartShift:
+		return courierAggregate.StartShift()
+	case ShiftActionEndShift:
+		return courierAggregate.EndShift()
+	case ShiftActionStartBreak:
+		return courierAggregate.StartBreak()
+	case ShiftActionEndBreak:
+		return courierAggregate.EndBreak()
+	default:
+		return errs.NewValueIsInvalidError("action")
+	}
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/pkg/errs"
+	"delivery/mocks/core/ports"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ChangeCourierShift_NewCmd(t *testing.T) {
+	t.Run("Return err with nil courier id", func(t *testing.T) {
+		_, err := NewChangeCourierShiftCmd(uuid.Nil, ShiftActionStartShift)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+	t.Run("Return err with unknown action", func(t *testing.T) {
+		_, err := NewChangeCourierShiftCmd(uuid.New(), "Dance")
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+	})
+}
+
+func Test_ChangeCourierShift_Handle(t *testing.T) {
+	t.Run("Start shift of off-duty courier", func(t *testing.T) {
+		testCourier, _ := courier.NewCourier("test", createTestTransport(t, 1), createTestLocation(t, 1, 1))
+		_ = testCourier.EndShift()
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil)
+
+		handler, _ := NewChangeCourierShiftCommandHandler(uow, courierRepo)
+		cmd, _ := NewChangeCourierShiftCmd(testCourier.ID(), ShiftActionStartShift)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, courier.StatusOnDuty, testCourier.Status())
+	})
+
+	t.Run("Return domain error without saving", func(t *testing.T) {
+		testCourier, _ := courier.NewCourier("test", createTestTransport(t, 1), createTestLocation(t, 1, 1))
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+
+		handler, _ := NewChangeCourierShiftCommandHandler(uow, courierRepo)
+		cmd, _ := NewChangeCourierShiftCmd(testCourier.ID(), ShiftActionEndBreak)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, courier.ErrCourierIsNotOnBreak)
+	})
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// ChangeStoragePlaceCmd переименовывает и/или меняет объём места хранения. Пустые поля не меняются
+type ChangeStoragePlaceCmd struct {
+	courierID      uuid.UUID
+	storagePlaceID uuid.UUID
+	name           *string
+	totalVolume    *int
+
+	isSet bool
+}
+
+func NewChangeStoragePlaceCmd(
+	courierID uuid.UUID,
+	storagePlaceID uuid.UUID,
+	name *string,
+	totalVolume *int,
+) (ChangeStoragePlaceCmd, error) {
+	if courierID == uuid.Nil {
+		return ChangeStoragePlaceCmd{}, errs.NewValueIsRequiredError("courierID")
+	}
+	if storagePlaceID == uuid.Nil {
+		return ChangeStoragePlaceCmd{}, errs.NewValueIsRequiredError("storagePlaceID")
+	}
+	if name == nil && totalVolume == nil {
+		return ChangeStoragePlaceCmd{}, errs.NewValueIsRequiredError("name or totalVolume")
+	}
+	if name != nil && strings.TrimSpace(*name) == "" {
+		return ChangeStoragePlaceCmd{}, errs.NewValueIsRequiredError("name")
+	}
+	if totalVolume != nil && *totalVolume < courier.MinVolume {
+		return ChangeStoragePlaceCmd{}, errs.NewValueIsRequiredError("totalVolume")
+	}
+
+	return ChangeStoragePlaceCmd{
+		courierID:      courierID,
+		storagePlaceID: storagePlaceID,
+		name:           name,
+		totalVolume:    totalVolume,
+		isSet:          true,
+	}, nil
+}
+
+func (cmd ChangeStoragePlaceCmd) CourierID() uuid.UUID {
+	return cmd.courierID
+}
+
+func (cmd ChangeStoragePlaceCmd) StoragePlaceID() uuid.UUID {
+	return cmd.storagePlaceID
+}
+
+func (cmd ChangeStoragePlaceCmd) Name() *string {
+	return cmd.name
+}
+
+func (cmd ChangeStoragePlaceCmd) TotalVolume() *int {
+	return cmd.totalVolume
+}
+
+func (cmd ChangeStoragePlaceCmd) IsEmpty() bool {
+	return !cmd.isSet
+}
+
+type ChangeStoragePlaceCommandHandler interface {
+	Handle(ctx context.Context, cmd ChangeStoragePlaceCmd) error
+}
+
+var _ ChangeStoragePlaceCommandHandler = &changeStoragePlaceCommandHandler{}
+
+type changeStoragePlaceCommandHandler struct {
+	unitOfWork        ports.UnitOfWork
+	courierRepository ports.CourierRepository
+}
+
+func NewChangeStoragePlaceCommandHandler(
+	unitOfWork ports.UnitOfWork,
+	courierRepository ports.CourierRepository,
+) (ChangeStoragePlaceCommandHandler, error) {
+	if unitOfWork == nil {
+		return nil, errs.NewValueIsRequiredError("unitOfWork")
+	}
+	if courierRepository == nil {
+		return nil, errs.NewValueIsRequiredError("courierRepository")
+	}
+
+	return &changeStoragePlaceCommandHandler{
+		unitOfWork:        unitOfWork,
+		courierRepository: courierRepository,
+	}, nil
+}
+
+func (ch *changeStoragePlaceCommandHandler) Handle(ctx context.Context, cmd ChangeStoragePlaceCmd) error {
+	if cmd.IsEmpty() {
+		return errs.NewValueIsRequiredError("cmd")
+	}
+
+	return updateCourier(ctx, ch.unitOfWork, ch.courierRepository, cmd.CourierID(), func(c *courier.Courier) error {
+		if cmd.Name() != nil {
+			if err := c.RenameStoragePlace(cmd.StoragePlaceID(), *cmd.Name()); err != nil {
+				return err
+			}
+		}
+		if cmd.TotalVolume() != nil {
+			if err := c.ResizeStoragePlace(cmd.StoragePlaceID(), *cmd.TotalVolume()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/pkg/errs"
+	"delivery/mocks/core/ports"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ChangeStoragePlace_NewCmd(t *testing.T) {
+	t.Run("Return err without changes", func(t *testing.T) {
+		_, err := NewChangeStoragePlaceCmd(uuid.New(), uuid.New(), nil, nil)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func Test_ChangeStoragePlace_Handle(t *testing.T) {
+	t.Run("Rename and resize free storage place", func(t *testing.T) {
+		testCourier := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = testCourier.AddStoragePlace("Bag", 5)
+		storagePlaceID := testCourier.StoragePlaces()[0].ID()
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil)
+
+		handler, _ := NewChangeStoragePlaceCommandHandler(uow, courierRepo)
+		name, volume := "Backpack", 3
+		cmd, _ := NewChangeStoragePlaceCmd(testCourier.ID(), storagePlaceID, &name, &volume)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Backpack", testCourier.StoragePlaces()[0].Name())
+		assert.Equal(t, 3, testCourier.StoragePlaces()[0].TotalVolume())
+	})
+
+	t.Run("Refuse to shrink occupied storage place", func(t *testing.T) {
+		testCourier := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = testCourier.AddStoragePlace("Bag", 5)
+		_ = testCourier.TakeOrder(createTestOrder(t, uuid.New(), 2, createTestLocation(t, 5, 5)))
+		storagePlaceID := testCourier.StoragePlaces()[0].ID()
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+
+		handler, _ := NewChangeStoragePlaceCommandHandler(uow, courierRepo)
+		volume := 4
+		cmd, _ := NewChangeStoragePlaceCmd(testCourier.ID(), storagePlaceID, nil, &volume)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, courier.ErrStoragePlaceIsOccupied)
+	})
+}
@@ -6,39 +6,60 @@ import (
 	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
 )
 
 type CreateCourierCmd struct {
-	name  string
-	speed int
+	name        string
+	transportID uuid.UUID
+	status      courier.Status
 
 	isSet bool
 }
 
-func NewCreateCourierCmd(name string, speed int) (CreateCourierCmd, error) {
+func NewCreateCourierCmd(name string, transportID uuid.UUID) (CreateCourierCmd, error) {
 	if name == "" {
 		return CreateCourierCmd{}, errs.NewValueIsRequiredError("name")
 	}
-	if speed <= 0 {
-		return CreateCourierCmd{}, errs.NewValueIsRequiredError("speed")
+	if transportID == uuid.Nil {
+		return CreateCourierCmd{}, errs.NewValueIsRequiredError("transportID")
 	}
 
 	return CreateCourierCmd{
-		name:  name,
-		speed: speed,
+		name:        name,
+		transportID: transportID,
+		status:      courier.StatusOnDuty,
 
 		isSet: true,
 	}, nil
 }
 
-func (cmd CreateCourierCmd) Speed() int {
-	return cmd.speed
+// WithStatus возвращает копию команды с начальным статусом курьера: по умолчанию курьер сразу на смене,
+// OffDuty - курьер заведён заранее и не получает заказы, пока не начнёт смену
+func (cmd CreateCourierCmd) WithStatus(status courier.Status) (CreateCourierCmd, error) {
+	if cmd.IsEmpty() {
+		return CreateCourierCmd{}, errs.NewValueIsRequiredError("cmd")
+	}
+	if status != courier.StatusOnDuty && status != courier.StatusOffDuty {
+		return CreateCourierCmd{}, errs.NewValueIsInvalidError("status")
+	}
+
+	cmd.status = status
+	return cmd, nil
+}
+
+func (cmd CreateCourierCmd) TransportID() uuid.UUID {
+	return cmd.transportID
 }
 
 func (cmd CreateCourierCmd) Name() string {
 	return cmd.name
 }
 
+func (cmd CreateCourierCmd) Status() courier.Status {
+	return cmd.status
+}
+
 func (cmd CreateCourierCmd) IsEmpty() bool {
 	return !cmd.isSet
 }
@@ -50,11 +71,18 @@ type CreateCourierCommandHandler interface {
 var _ CreateCourierCommandHandler = &createCourierCommandHandler{}
 
 type createCourierCommandHandler struct {
-	unitOfWork       ports.UnitOfWork
-	courseRepository ports.CourierRepository
+	unitOfWork          ports.UnitOfWork
+	courseRepository    ports.CourierRepository
+	transportRepository ports.TransportRepository
+	grid                kernel.Grid
 }
 
-func NewCreateCourierCommandHandler(uow ports.UnitOfWork, repo ports.CourierRepository) (CreateCourierCommandHandler, error) {
+func NewCreateCourierCommandHandler(
+	uow ports.UnitOfWork,
+	repo ports.CourierRepository,
+	transportRepo ports.TransportRepository,
+	grid kernel.Grid,
+) (CreateCourierCommandHandler, error) {
 	if uow == nil {
 		return nil, errs.NewValueIsRequiredError("uow")
 	}
@@ -63,9 +91,19 @@ func NewCreateCourierCommandHandler(uow ports.UnitOfWork, repo ports.CourierRepo
 		return nil, errs.NewValueIsRequiredError("repo")
 	}
 
+	if transportRepo == nil {
+		return nil, errs.NewValueIsRequiredError("transportRepo")
+	}
+
+	if grid.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("grid")
+	}
+
 	return &createCourierCommandHandler{
-		unitOfWork:       uow,
-		courseRepository: repo,
+		unitOfWork:          uow,
+		courseRepository:    repo,
+		transportRepository: transportRepo,
+		grid:                grid,
 	}, nil
 }
 
@@ -74,15 +112,23 @@ func (ch *createCourierCommandHandler) Handle(ctx context.Context, cmd CreateCou
 		return errs.NewValueIsRequiredError("cmd")
 	}
 
-	location := kernel.CreateRandomLocation()
-	courierAggregate, err := courier.NewCourier(cmd.Name(), cmd.Speed(), location)
+	transport, err := ch.transportRepository.Get(ctx, cmd.TransportID())
 	if err != nil {
 		return err
 	}
-	err = courierAggregate.AddStoragePlace("bag", 8)
+
+	// Места хранения курьер получает вместе с транспортом, а появляется в случайной точке карты города
+	location := ch.grid.RandomLocation()
+	courierAggregate, err := courier.NewCourier(cmd.Name(), transport, location)
 	if err != nil {
 		return err
 	}
+	if cmd.Status() == courier.StatusOffDuty {
+		err = courierAggregate.EndShift()
+		if err != nil {
+			return err
+		}
+	}
 
 	err = ch.courseRepository.Add(ctx, courierAggregate)
 	if err != nil {
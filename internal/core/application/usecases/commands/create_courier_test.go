@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"delivery/mocks/core/ports"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_CreateCourier_Handle(t *testing.T) {
+	t.Run("Create courier with storage layout of transport", func(t *testing.T) {
+		backpack, _ := courier.NewStorageLayoutItem("backpack", 8)
+		walk, _ := courier.NewTransport(uuid.New(), "Пешком", 1, []courier.StorageLayoutItem{backpack})
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+		transportRepo := ports.NewMockTransportRepository(t)
+
+		var created *courier.Courier
+		transportRepo.On("Get", mock.Anything, walk.ID()).Return(walk, nil)
+		courierRepo.On("Add", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { created = args.Get(1).(*courier.Courier) }).
+			Return(nil)
+
+		handler, _ := NewCreateCourierCommandHandler(uow, courierRepo, transportRepo, kernel.DefaultGrid())
+		cmd, _ := NewCreateCourierCmd("Walker", walk.ID())
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, courier.StatusOnDuty, created.Status())
+		assert.Equal(t, 1, created.Speed())
+		assert.Len(t, created.StoragePlaces(), 1)
+		assert.Equal(t, "backpack", created.StoragePlaces()[0].Name())
+		assert.Equal(t, 8, created.StoragePlaces()[0].TotalVolume())
+	})
+
+	t.Run("Create off-duty courier on request", func(t *testing.T) {
+		walk, _ := courier.NewTransport(uuid.New(), "Пешком", 1, nil)
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+		transportRepo := ports.NewMockTransportRepository(t)
+
+		var created *courier.Courier
+		transportRepo.On("Get", mock.Anything, walk.ID()).Return(walk, nil)
+		courierRepo.On("Add", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { created = args.Get(1).(*courier.Courier) }).
+			Return(nil)
+
+		handler, _ := NewCreateCourierCommandHandler(uow, courierRepo, transportRepo, kernel.DefaultGrid())
+		cmd, _ := NewCreateCourierCmd("Walker", walk.ID())
+		cmd, err := cmd.WithStatus(courier.StatusOffDuty)
+		assert.NoError(t, err)
+
+		err = handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, courier.StatusOffDuty, created.Status())
+
+		_, err = cmd.WithStatus(courier.StatusOnBreak)
+		assert.ErrorIs(t, err, errs.ErrValueIsInvalid)
+	})
+
+	t.Run("Return not found for unknown transport", func(t *testing.T) {
+		transportID := uuid.New()
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+		transportRepo := ports.NewMockTransportRepository(t)
+
+		transportRepo.On("Get", mock.Anything, transportID).
+			Return(courier.Transport{}, errs.NewObjectNotFoundError("Transport by ID", transportID))
+
+		handler, _ := NewCreateCourierCommandHandler(uow, courierRepo, transportRepo, kernel.DefaultGrid())
+		cmd, _ := NewCreateCourierCmd("Walker", transportID)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, errs.ErrObjectNotFound)
+	})
+}
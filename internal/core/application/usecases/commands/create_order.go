@@ -2,28 +2,31 @@ package commands
 
 import (
 	"context"
+	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/core/domain/model/order"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/errs"
 	"github.com/google/uuid"
-	"strings"
+	"time"
 )
 
 type CreateOrderCmd struct {
-	orderID uuid.UUID
-	street  string
-	volume  int
+	orderID      uuid.UUID
+	address      kernel.Address
+	volume       int
+	deliveryFrom time.Time
+	deliveryTo   time.Time
 
 	isSet bool
 }
 
-func NewCreateOrderCmd(orderID uuid.UUID, street string, volume int) (CreateOrderCmd, error) {
+func NewCreateOrderCmd(orderID uuid.UUID, address kernel.Address, volume int) (CreateOrderCmd, error) {
 	if orderID == uuid.Nil {
 		return CreateOrderCmd{isSet: false}, errs.NewValueIsRequiredError("orderID")
 	}
 
-	if strings.TrimSpace(street) == "" {
-		return CreateOrderCmd{isSet: false}, errs.NewValueIsRequiredError("street")
+	if address.IsEmpty() {
+		return CreateOrderCmd{isSet: false}, errs.NewValueIsRequiredError("address")
 	}
 
 	if volume <= 0 {
@@ -32,24 +35,53 @@ func NewCreateOrderCmd(orderID uuid.UUID, street string, volume int) (CreateOrde
 
 	return CreateOrderCmd{
 		orderID: orderID,
-		street:  street,
+		address: address,
 		volume:  volume,
 		isSet:   true,
 	}, nil
 }
 
+// WithDeliveryPeriod возвращает копию команды с окном доставки
+func (cmd CreateOrderCmd) WithDeliveryPeriod(from time.Time, to time.Time) (CreateOrderCmd, error) {
+	if cmd.IsEmpty() {
+		return CreateOrderCmd{}, errs.NewValueIsRequiredError("cmd")
+	}
+	if from.IsZero() {
+		return CreateOrderCmd{}, errs.NewValueIsRequiredError("deliveryFrom")
+	}
+	if !to.After(from) {
+		return CreateOrderCmd{}, errs.NewValueIsOutOfRangeError("deliveryTo", to, from, nil)
+	}
+
+	cmd.deliveryFrom = from
+	cmd.deliveryTo = to
+	return cmd, nil
+}
+
 func (cmd CreateOrderCmd) OrderID() uuid.UUID {
 	return cmd.orderID
 }
 
-func (cmd CreateOrderCmd) Street() string {
-	return cmd.street
+func (cmd CreateOrderCmd) Address() kernel.Address {
+	return cmd.address
 }
 
 func (cmd CreateOrderCmd) Volume() int {
 	return cmd.volume
 }
 
+func (cmd CreateOrderCmd) DeliveryFrom() time.Time {
+	return cmd.deliveryFrom
+}
+
+func (cmd CreateOrderCmd) DeliveryTo() time.Time {
+	return cmd.deliveryTo
+}
+
+func (cmd CreateOrderCmd) HasDeliveryPeriod() bool {
+	return !cmd.deliveryFrom.IsZero() && !cmd.deliveryTo.IsZero()
+}
+
 func (cmd CreateOrderCmd) IsEmpty() bool {
 	return !cmd.isSet
 }
@@ -102,16 +134,12 @@ func (ch *createOrderCommandHandler) Handle(ctx context.Context, cmd CreateOrder
 		return nil
 	}
 
-	location, err := ch.geoLocationGateway.DefineLocation(ctx, cmd.Street())
+	location, err := ch.geoLocationGateway.DefineLocation(ctx, cmd.Address())
 	if err != nil {
 		return err
 	}
 
-	existingOrder, err = order.NewOrder(
-		cmd.OrderID(),
-		location,
-		cmd.Volume(),
-	)
+	existingOrder, err = ch.newOrder(cmd, location)
 	if err != nil {
 		return err
 	}
@@ -123,3 +151,15 @@ func (ch *createOrderCommandHandler) Handle(ctx context.Context, cmd CreateOrder
 
 	return nil
 }
+
+func (ch *createOrderCommandHandler) newOrder(cmd CreateOrderCmd, location kernel.Location) (*order.Order, error) {
+	if !cmd.HasDeliveryPeriod() {
+		return order.NewOrder(cmd.OrderID(), cmd.Address(), location, cmd.Volume())
+	}
+
+	deliveryPeriod, err := order.NewDeliveryPeriod(cmd.DeliveryFrom(), cmd.DeliveryTo())
+	if err != nil {
+		return nil, err
+	}
+	return order.NewOrderWithDeliveryPeriod(cmd.OrderID(), cmd.Address(), location, cmd.Volume(), deliveryPeriod)
+}
@@ -2,9 +2,13 @@ package commands
 
 import (
 	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/domain/services"
 	"delivery/internal/core/ports"
 	"delivery/internal/pkg/errs"
 	"errors"
+	"log"
 )
 
 type MoveCouriersCmd struct {
@@ -32,12 +36,14 @@ type moveCouriersCommandHandler struct {
 	unitOfWork       ports.UnitOfWork
 	orderRepository  ports.OrderRepository
 	courseRepository ports.CourierRepository
+	navigator        courier.Navigator
 }
 
 func NewMoveCouriersCommandHandler(
 	unitOfWork ports.UnitOfWork,
 	orderRepository ports.OrderRepository,
 	courierRepository ports.CourierRepository,
+	navigator courier.Navigator,
 ) (MoveCouriersCommandHandler, error) {
 
 	if unitOfWork == nil {
@@ -52,10 +58,15 @@ func NewMoveCouriersCommandHandler(
 		return nil, errs.NewValueIsRequiredError("courierRepository")
 	}
 
+	if navigator == nil {
+		return nil, errs.NewValueIsRequiredError("navigator")
+	}
+
 	return &moveCouriersCommandHandler{
 		unitOfWork:       unitOfWork,
 		orderRepository:  orderRepository,
-		courseRepository: courierRepository}, nil
+		courseRepository: courierRepository,
+		navigator:        navigator}, nil
 }
 
 func (ch *moveCouriersCommandHandler) Handle(ctx context.Context, cmd MoveCouriersCmd) error {
@@ -63,7 +74,7 @@ func (ch *moveCouriersCommandHandler) Handle(ctx context.Context, cmd MoveCourie
 		return errs.NewValueIsRequiredError("cmd")
 	}
 
-	assignedOrders, err := ch.orderRepository.GetAllInAssignedStatus(ctx)
+	couriers, err := ch.courseRepository.GetAllOnRoute(ctx)
 	if err != nil {
 		if errors.Is(err, errs.ErrObjectNotFound) {
 			return nil
@@ -71,42 +82,112 @@ func (ch *moveCouriersCommandHandler) Handle(ctx context.Context, cmd MoveCourie
 		return err
 	}
 
-	ch.unitOfWork.Begin(ctx)
-	for _, assignedOrder := range assignedOrders {
-		courier, err := ch.courseRepository.Get(ctx, *assignedOrder.CourierID())
+	for _, courier := range couriers {
+		err = ch.move(ctx, courier)
 		if err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
 
-		err = courier.Move(assignedOrder.Location())
+// move сохраняет каждого курьера в своей транзакции: конфликт версий одного курьера
+// не отменяет перемещение остальных
+func (ch *moveCouriersCommandHandler) move(ctx context.Context, courierAggregate *courier.Courier) error {
+	// За тик курьер проходит по пути в объезд препятствий столько клеток, сколько позволяет скорость
+	err := courierAggregate.MoveAlongRoute(ch.navigator)
+	if errors.Is(err, services.ErrPathNotFound) {
+		return ch.unassignUnreachable(ctx, courierAggregate)
+	}
+	if err != nil {
+		return err
+	}
+
+	ch.unitOfWork.Begin(ctx)
+	defer ch.unitOfWork.Rollback(ctx)
+
+	for _, stop := range courierAggregate.ReachedStops() {
+		err = ch.deliver(ctx, courierAggregate, stop)
 		if err != nil {
-			return err
+			return skipOnVersionConflict(err)
 		}
+	}
 
-		if courier.Location().Equals(assignedOrder.Location()) {
-			err := assignedOrder.Complete()
-			if err != nil {
-				return err
-			}
-			err = courier.CompleteOrder(assignedOrder)
-			if err != nil {
-				return err
-			}
-		}
+	err = ch.courseRepository.Update(ctx, courierAggregate)
+	if err != nil {
+		return skipOnVersionConflict(err)
+	}
+	return ch.unitOfWork.Commit(ctx)
+}
+
+// unassignUnreachable - точку отрезали препятствия: курьер отдаёт заказ, и тот возвращается в распределение,
+// чтобы его забрал курьер, который может доехать. Остальные курьеры едут дальше
+func (ch *moveCouriersCommandHandler) unassignUnreachable(ctx context.Context, courierAggregate *courier.Courier) error {
+	stop, ok := courierAggregate.NextStop()
+	if !ok {
+		return nil
+	}
+	log.Printf("Courier %s cannot reach order %s at (%d, %d), returning the order to dispatch",
+		courierAggregate.ID(), stop.OrderID(), stop.Location().X(), stop.Location().Y())
 
-		err = ch.orderRepository.Update(ctx, assignedOrder)
+	ch.unitOfWork.Begin(ctx)
+	defer ch.unitOfWork.Rollback(ctx)
+
+	unreachableOrder, err := ch.orderRepository.Get(ctx, stop.OrderID())
+	if err != nil {
+		return err
+	}
+	if unreachableOrder == nil {
+		return errs.NewObjectNotFoundError("orderID", stop.OrderID())
+	}
+
+	err = courierAggregate.ReleaseOrder(unreachableOrder)
+	if err != nil {
+		return err
+	}
+	// Отменённый заказ просто убирается из маршрута
+	if unreachableOrder.Status() == order.StatusAssigned {
+		err = unreachableOrder.Unassign()
 		if err != nil {
 			return err
 		}
-		err = ch.courseRepository.Update(ctx, courier)
+		err = ch.orderRepository.Update(ctx, unreachableOrder)
 		if err != nil {
-			return err
+			return skipOnVersionConflict(err)
 		}
 	}
-	err = ch.unitOfWork.Commit(ctx)
+
+	err = ch.courseRepository.Update(ctx, courierAggregate)
+	if err != nil {
+		return skipOnVersionConflict(err)
+	}
+	return ch.unitOfWork.Commit(ctx)
+}
+
+// deliver завершает заказ в точке маршрута, до которой доехал курьер
+func (ch *moveCouriersCommandHandler) deliver(ctx context.Context, courierAggregate *courier.Courier, stop courier.RouteStop) error {
+	assignedOrder, err := ch.orderRepository.Get(ctx, stop.OrderID())
+	if err != nil {
+		return err
+	}
+	if assignedOrder == nil {
+		return errs.NewObjectNotFoundError("orderID", stop.OrderID())
+	}
+
+	// Заказ уже не у курьера (например, отменён) - просто убираем точку из маршрута
+	if assignedOrder.Status() != order.StatusAssigned {
+		return courierAggregate.ReleaseOrder(assignedOrder)
+	}
+
+	err = assignedOrder.Complete()
+	if err != nil {
+		return err
+	}
+	err = courierAggregate.CompleteOrder(assignedOrder)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return ch.orderRepository.Update(ctx, assignedOrder)
 }
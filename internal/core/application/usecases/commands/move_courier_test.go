@@ -5,6 +5,7 @@ import (
 	"delivery/internal/core/domain/model/courier"
 	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/domain/services"
 	"delivery/internal/pkg/errs"
 	"delivery/mocks/core/ports"
 	"github.com/stretchr/testify/mock"
@@ -18,21 +19,28 @@ func Test_CreateNewHandler(t *testing.T) {
 	t.Run("Return err with nil UOW", func(t *testing.T) {
 		orderRepo := ports.NewMockOrderRepository(t)
 		courierRepo := ports.NewMockCourierRepository(t)
-		_, err := NewMoveCouriersCommandHandler(nil, orderRepo, courierRepo)
+		_, err := NewMoveCouriersCommandHandler(nil, orderRepo, courierRepo, createTestNavigator(t))
 		assert.Errorf(t, err, errs.NewValueIsRequiredError("unitOfWork").Error())
 	})
 	t.Run("Return err with nil order repository", func(t *testing.T) {
 		uow := ports.NewMockUnitOfWork(t)
 		courierRepo := ports.NewMockCourierRepository(t)
-		_, err := NewMoveCouriersCommandHandler(uow, nil, courierRepo)
+		_, err := NewMoveCouriersCommandHandler(uow, nil, courierRepo, createTestNavigator(t))
 		assert.Errorf(t, err, errs.NewValueIsRequiredError("unitOfWork").Error())
 	})
 	t.Run("Return err with nil courier repository", func(t *testing.T) {
 		uow := ports.NewMockUnitOfWork(t)
 		orderRepo := ports.NewMockOrderRepository(t)
-		_, err := NewMoveCouriersCommandHandler(uow, orderRepo, nil)
+		_, err := NewMoveCouriersCommandHandler(uow, orderRepo, nil, createTestNavigator(t))
 		assert.Errorf(t, err, errs.NewValueIsRequiredError("unitOfWork").Error())
 	})
+	t.Run("Return err with nil navigator", func(t *testing.T) {
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+		_, err := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, nil)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
 
 }
 
@@ -41,7 +49,7 @@ func Test_Handle_WithInvalidArgs(t *testing.T) {
 		uow := ports.NewMockUnitOfWork(t)
 		orderRepo := ports.NewMockOrderRepository(t)
 		courierRepo := ports.NewMockCourierRepository(t)
-		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo)
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
 
 		err := handler.Handle(context.Background(), MoveCouriersCmd{})
 		assert.Errorf(t, err, errs.NewValueIsRequiredError("cmd").Error())
@@ -49,36 +57,35 @@ func Test_Handle_WithInvalidArgs(t *testing.T) {
 }
 
 func Test_Handle_NegativeScenarios(t *testing.T) {
-	t.Run("If order not found - return nil", func(t *testing.T) {
+	t.Run("If no courier is on route - return nil", func(t *testing.T) {
 		uow := ports.NewMockUnitOfWork(t)
 		orderRepo := ports.NewMockOrderRepository(t)
 		courierRepo := ports.NewMockCourierRepository(t)
 
-		orderRepo.On("GetAllInAssignedStatus", mock.Anything).Return([]*order.Order{}, errs.ErrObjectNotFound)
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return(nil, errs.NewObjectNotFoundError("Couriers on route", nil))
 
-		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo)
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
 		cmd, _ := NewMoveCouriersCmd()
 
 		err := handler.Handle(context.Background(), cmd)
 		assert.NoError(t, err)
 	})
 
-	t.Run("If courier not found - return err", func(t *testing.T) {
-		orderId := uuid.New()
-		courierId := uuid.New()
-
-		testOrder := createAssignedTestOrder(t, orderId, courierId, 1)
+	t.Run("If reached order not found - return err", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 2, 1))
+		testCourier := createCourierWithOrders(t, createTestLocation(t, 1, 1), 1, testOrder)
 
 		uow := ports.NewMockUnitOfWork(t)
 		orderRepo := ports.NewMockOrderRepository(t)
 		courierRepo := ports.NewMockCourierRepository(t)
 
 		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
 
-		orderRepo.On("GetAllInAssignedStatus", mock.Anything).Return([]*order.Order{testOrder}, nil)
-		courierRepo.On("Get", mock.Anything, courierId).Return(nil, errs.ErrObjectNotFound)
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{testCourier}, nil)
+		orderRepo.On("Get", mock.Anything, testOrder.ID()).Return(nil, nil)
 
-		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo)
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
 		cmd, _ := NewMoveCouriersCmd()
 
 		err := handler.Handle(context.Background(), cmd)
@@ -87,37 +94,201 @@ func Test_Handle_NegativeScenarios(t *testing.T) {
 }
 
 func Test_Handle_PositiveScenarios(t *testing.T) {
-	t.Run("Move courier if ok", func(t *testing.T) {
-		orderId := uuid.New()
+	t.Run("Move courier one step toward the next stop", func(t *testing.T) {
+		first := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 10, 1))
+		second := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 1, 10))
+		testCourier := createCourierWithOrders(t, createTestLocation(t, 1, 1), 5, first, second)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
 
-		testOrder := createTestOrder(t, orderId, 5, createTestLocation(t, 10, 10))
-		testCourier := createTestCourier(t, createTestLocation(t, 1, 1), 5)
-		_ = testOrder.Assign(testCourier.ID())
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{testCourier}, nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil).Once()
+
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
+		cmd, _ := NewMoveCouriersCmd()
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+
+		// Курьер идёт только к первой точке маршрута, а не ко всем заказам сразу
+		assert.Equal(t, 6, testCourier.Location().X())
+		assert.Equal(t, 1, testCourier.Location().Y())
+		assert.Len(t, testCourier.Route(), 2)
+	})
+
+	t.Run("Complete orders at reached stop and keep the rest of the route", func(t *testing.T) {
+		first := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 2, 1))
+		second := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 2, 1))
+		third := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 9, 9))
+		testCourier := createCourierWithOrders(t, createTestLocation(t, 1, 1), 1, first, second, third)
 
 		uow := ports.NewMockUnitOfWork(t)
 		orderRepo := ports.NewMockOrderRepository(t)
 		courierRepo := ports.NewMockCourierRepository(t)
 
 		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
 		uow.On("Commit", mock.Anything).Return(nil)
 
-		orderRepo.On("GetAllInAssignedStatus", mock.Anything).Return([]*order.Order{testOrder}, nil)
-		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{testCourier}, nil)
+		orderRepo.On("Get", mock.Anything, first.ID()).Return(first, nil)
+		orderRepo.On("Get", mock.Anything, second.ID()).Return(second, nil)
+		orderRepo.On("Update", mock.Anything, first).Return(nil)
+		orderRepo.On("Update", mock.Anything, second).Return(nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil)
+
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
+		cmd, _ := NewMoveCouriersCmd()
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+
+		assert.Equal(t, order.StatusCompleted, first.Status())
+		assert.Equal(t, order.StatusCompleted, second.Status())
+		assert.Equal(t, order.StatusAssigned, third.Status())
+		route := testCourier.Route()
+		assert.Len(t, route, 1)
+		assert.Equal(t, third.ID(), route[0].OrderID())
+	})
+}
+
+func Test_Handle_WithObstacles(t *testing.T) {
+	t.Run("Go around blocked cells", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 3, 1))
+		testCourier := createCourierWithOrders(t, createTestLocation(t, 1, 1), 2, testOrder)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
 
-		orderRepo.On("Update", mock.Anything, testOrder).Return(nil)
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{testCourier}, nil)
 		courierRepo.On("Update", mock.Anything, testCourier).Return(nil)
 
-		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo)
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo,
+			createTestNavigator(t, createTestLocation(t, 2, 1)))
 		cmd, _ := NewMoveCouriersCmd()
 
 		err := handler.Handle(context.Background(), cmd)
 		assert.NoError(t, err)
 
-		assert.Equal(t, uint8(6), testCourier.Location().X())
-		assert.Equal(t, uint8(1), testCourier.Location().Y())
+		// Напрямую мешает препятствие в (2,1), поэтому курьер сначала поднимается на строку выше
+		assert.Equal(t, 2, testCourier.Location().X())
+		assert.Equal(t, 2, testCourier.Location().Y())
+	})
+
+	t.Run("Unreachable order is returned to dispatch", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 1, 1))
+		testCourier := createCourierWithOrders(t, createTestLocation(t, 3, 3), 1, testOrder)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
+
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{testCourier}, nil)
+		orderRepo.On("Get", mock.Anything, testOrder.ID()).Return(testOrder, nil)
+		orderRepo.On("Update", mock.Anything, testOrder).Return(nil).Once()
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil).Once()
+
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo,
+			createTestNavigator(t, createTestLocation(t, 2, 1), createTestLocation(t, 1, 2)))
+		cmd, _ := NewMoveCouriersCmd()
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+
+		// Курьер остаётся на месте, а заказ снова ждёт назначения
+		assert.Equal(t, 3, testCourier.Location().X())
+		assert.Equal(t, 3, testCourier.Location().Y())
+		assert.Empty(t, testCourier.Route())
+		assert.Equal(t, order.StatusCreated, testOrder.Status())
+		assert.Nil(t, testOrder.CourierID())
 	})
 }
 
+func Test_Handle_VersionConflict(t *testing.T) {
+	t.Run("Skip tick if courier was changed concurrently", func(t *testing.T) {
+		testOrder := createTestOrder(t, uuid.New(), 1, createTestLocation(t, 10, 10))
+		testCourier := createCourierWithOrders(t, createTestLocation(t, 1, 1), 5, testOrder)
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{testCourier}, nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(errs.NewVersionIsInvalidError("Courier"))
+
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
+		cmd, _ := NewMoveCouriersCmd()
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		uow.AssertNotCalled(t, "Commit", mock.Anything)
+	})
+
+	t.Run("Conflict of one courier does not discard moves of the others", func(t *testing.T) {
+		conflicting := createCourierWithOrders(t, createTestLocation(t, 1, 1), 1,
+			createTestOrder(t, uuid.New(), 1, createTestLocation(t, 5, 1)))
+		moving := createCourierWithOrders(t, createTestLocation(t, 1, 5), 1,
+			createTestOrder(t, uuid.New(), 1, createTestLocation(t, 5, 5)))
+
+		uow := ports.NewMockUnitOfWork(t)
+		orderRepo := ports.NewMockOrderRepository(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return().Twice()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil).Once()
+
+		courierRepo.On("GetAllOnRoute", mock.Anything).Return([]*courier.Courier{conflicting, moving}, nil)
+		courierRepo.On("Update", mock.Anything, conflicting).Return(errs.NewVersionIsInvalidError("Courier")).Once()
+		courierRepo.On("Update", mock.Anything, moving).Return(nil).Once()
+
+		handler, _ := NewMoveCouriersCommandHandler(uow, orderRepo, courierRepo, createTestNavigator(t))
+		cmd, _ := NewMoveCouriersCmd()
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, moving.Location().X())
+	})
+}
+
+// createCourierWithOrders создаёт курьера, который уже везёт заказы в указанном порядке
+func createCourierWithOrders(t *testing.T, location kernel.Location, speed int, orders ...*order.Order) *courier.Courier {
+	res := createTestCourier(t, location, speed)
+	for _, o := range orders {
+		assert.NoError(t, res.AddStoragePlace("bag", 10))
+		assert.NoError(t, o.Assign(res.ID()))
+		assert.NoError(t, res.TakeOrder(o))
+	}
+	return res
+}
+
+// createTestNavigator строит навигатор по карте 10x10 с заданными непроходимыми клетками
+func createTestNavigator(t *testing.T, blocked ...kernel.Location) courier.Navigator {
+	cityMap, err := kernel.NewCityMap(kernel.DefaultGrid(), blocked)
+	assert.NoError(t, err)
+	navigator, err := services.NewPathfinder(cityMap)
+	assert.NoError(t, err)
+	return navigator
+}
+
 func createAssignedTestOrder(
 	t *testing.T,
 	id uuid.UUID,
@@ -125,7 +296,7 @@ func createAssignedTestOrder(
 	volume int,
 ) *order.Order {
 	location := createTestLocation(t, 5, 5)
-	order, err := order.NewOrder(id, location, volume)
+	order, err := order.NewOrder(id, createTestAddress(t), location, volume)
 	assert.NoError(t, err)
 
 	err = order.Assign(courierID)
@@ -134,20 +305,32 @@ func createAssignedTestOrder(
 	return order
 }
 
+func createTestTransport(t *testing.T, speed int) courier.Transport {
+	res, err := courier.NewTransport(uuid.New(), "test", speed, nil)
+	assert.NoError(t, err)
+	return res
+}
+
 func createTestCourier(t *testing.T, location kernel.Location, speed int) *courier.Courier {
-	res, err := courier.NewCourier("test", speed, location)
+	res, err := courier.NewCourier("test", createTestTransport(t, speed), location)
 	assert.NoError(t, err)
 	return res
 }
 
 func createTestOrder(t *testing.T, id uuid.UUID, volume int, location kernel.Location) *order.Order {
-	res, err := order.NewOrder(id, location, volume)
+	res, err := order.NewOrder(id, createTestAddress(t), location, volume)
 	assert.NoError(t, err)
 	return res
 }
 
+func createTestAddress(t *testing.T) kernel.Address {
+	address, err := kernel.NewAddress("", "", "Тестовая", "", "")
+	assert.NoError(t, err)
+	return address
+}
+
 func createTestLocation(t *testing.T, x int, y int) kernel.Location {
-	location, err := kernel.NewLocation(uint8(x), uint8(y))
+	location, err := kernel.NewLocation(kernel.DefaultGrid(), x, y)
 	assert.NoError(t, err)
 	return location
 }
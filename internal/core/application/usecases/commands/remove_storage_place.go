@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+)
+
+type RemoveStoragePlaceCmd struct {
+	courierID      uuid.UUID
+	storagePlaceID uuid.UUID
+
+	isSet bool
+}
+
+func NewRemoveStoragePlaceCmd(courierID uuid.UUID, storagePlaceID uuid.UUID) (RemoveStoragePlaceCmd, error) {
+	if courierID == uuid.Nil {
+		return RemoveStoragePlaceCmd{}, errs.NewValueIsRequiredError("courierID")
+	}
+	if storagePlaceID == uuid.Nil {
+		return RemoveStoragePlaceCmd{}, errs.NewValueIsRequiredError("storagePlaceID")
+	}
+
+	return RemoveStoragePlaceCmd{
+		courierID:      courierID,
+		storagePlaceID: storagePlaceID,
+		isSet:          true,
+	}, nil
+}
+
+func (cmd RemoveStoragePlaceCmd) CourierID() uuid.UUID {
+	return cmd.courierID
+}
+
+func (cmd RemoveStoragePlaceCmd) StoragePlaceID() uuid.UUID {
+	return cmd.storagePlaceID
+}
+
+func (cmd RemoveStoragePlaceCmd) IsEmpty() bool {
+	return !cmd.isSet
+}
+
+type RemoveStoragePlaceCommandHandler interface {
+	Handle(ctx context.Context, cmd RemoveStoragePlaceCmd) error
+}
+
+var _ RemoveStoragePlaceCommandHandler = &removeStoragePlaceCommandHandler{}
+
+type removeStoragePlaceCommandHandler struct {
+	unitOfWork        ports.UnitOfWork
+	courierRepository ports.CourierRepository
+}
+
+func NewRemoveStoragePlaceCommandHandler(
+	unitOfWork ports.UnitOfWork,
+	courierRepository ports.CourierRepository,
+) (RemoveStoragePlaceCommandHandler, error) {
+	if unitOfWork == nil {
+		return nil, errs.NewValueIsRequiredError("unitOfWork")
+	}
+	if courierRepository == nil {
+		return nil, errs.NewValueIsRequiredError("courierRepository")
+	}
+
+	return &removeStoragePlaceCommandHandler{
+		unitOfWork:        unitOfWork,
+		courierRepository: courierRepository,
+	}, nil
+}
+
+func (ch *removeStoragePlaceCommandHandler) Handle(ctx context.Context, cmd RemoveStoragePlaceCmd) error {
+	if cmd.IsEmpty() {
+		return errs.NewValueIsRequiredError("cmd")
+	}
+
+	return updateCourier(ctx, ch.unitOfWork, ch.courierRepository, cmd.CourierID(), func(c *courier.Courier) error {
+		return c.RemoveStoragePlace(cmd.StoragePlaceID())
+	})
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/mocks/core/ports"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_RemoveStoragePlace_Handle(t *testing.T) {
+	t.Run("Remove free storage place", func(t *testing.T) {
+		testCourier := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = testCourier.AddStoragePlace("Bag", 5)
+		storagePlaceID := testCourier.StoragePlaces()[0].ID()
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		uow.On("Begin", mock.Anything).Return()
+		uow.On("Rollback", mock.Anything).Return()
+		uow.On("Commit", mock.Anything).Return(nil)
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+		courierRepo.On("Update", mock.Anything, testCourier).Return(nil)
+
+		handler, _ := NewRemoveStoragePlaceCommandHandler(uow, courierRepo)
+		cmd, _ := NewRemoveStoragePlaceCmd(testCourier.ID(), storagePlaceID)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.NoError(t, err)
+		assert.Empty(t, testCourier.StoragePlaces())
+	})
+
+	t.Run("Refuse to remove occupied storage place", func(t *testing.T) {
+		testCourier := createTestCourier(t, createTestLocation(t, 1, 1), 1)
+		_ = testCourier.AddStoragePlace("Bag", 5)
+		_ = testCourier.TakeOrder(createTestOrder(t, uuid.New(), 2, createTestLocation(t, 5, 5)))
+		storagePlaceID := testCourier.StoragePlaces()[0].ID()
+
+		uow := ports.NewMockUnitOfWork(t)
+		courierRepo := ports.NewMockCourierRepository(t)
+
+		courierRepo.On("Get", mock.Anything, testCourier.ID()).Return(testCourier, nil)
+
+		handler, _ := NewRemoveStoragePlaceCommandHandler(uow, courierRepo)
+		cmd, _ := NewRemoveStoragePlaceCmd(testCourier.ID(), storagePlaceID)
+
+		err := handler.Handle(context.Background(), cmd)
+		assert.ErrorIs(t, err, courier.ErrStoragePlaceIsOccupied)
+		assert.Len(t, testCourier.StoragePlaces(), 1)
+	})
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// updateCourier читает курьера, применяет к нему изменение и сохраняет в отдельной транзакции.
+// Курьера параллельно двигает джоба, поэтому при конфликте версий всё повторяется с перечитанным агрегатом
+func updateCourier(
+	ctx context.Context,
+	unitOfWork ports.UnitOfWork,
+	courierRepository ports.CourierRepository,
+	courierID uuid.UUID,
+	change func(*courier.Courier) error,
+) error {
+	return retryOnVersionConflict(ctx, func(ctx context.Context) error {
+		courierAggregate, err := courierRepository.Get(ctx, courierID)
+		if err != nil {
+			return err
+		}
+
+		err = change(courierAggregate)
+		if err != nil {
+			return err
+		}
+
+		unitOfWork.Begin(ctx)
+		defer unitOfWork.Rollback(ctx)
+
+		err = courierRepository.Update(ctx, courierAggregate)
+		if err != nil {
+			return err
+		}
+
+		return unitOfWork.Commit(ctx)
+	})
+}
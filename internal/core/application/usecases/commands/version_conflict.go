@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"errors"
+)
+
+// maxVersionConflictAttempts - сколько раз повторяем команду, если агрегат изменили параллельно
+const maxVersionConflictAttempts = 3
+
+// retryOnVersionConflict повторяет действие целиком (чтение, изменение, сохранение),
+// пока агрегаты меняются конкурентно, но не больше maxVersionConflictAttempts раз
+func retryOnVersionConflict(ctx context.Context, action func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < maxVersionConflictAttempts; attempt++ {
+		err = action(ctx)
+		if !errors.Is(err, errs.ErrVersionIsInvalid) {
+			return err
+		}
+	}
+	return err
+}
+
+// skipOnVersionConflict пропускает конфликт версий: агрегаты уже изменил другой экземпляр,
+// а фоновая задача повторит попытку на следующем тике
+func skipOnVersionConflict(err error) error {
+	if errors.Is(err, errs.ErrVersionIsInvalid) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,9 @@
+package queries
+
+type AddressResponse struct {
+	Country   string
+	City      string
+	Street    string
+	House     string
+	Apartment string
+}
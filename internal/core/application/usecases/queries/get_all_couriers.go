@@ -28,6 +28,7 @@ type CourierResponse struct {
 	ID       uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Name     string
 	Location LocationResponse `gorm:"embedded;embeddedPrefix:location_"`
+	Status   string
 }
 
 func (CourierResponse) TableName() string {
@@ -55,7 +56,7 @@ func (q *getAllCouriersQueryHandler) Handle(query GetAllCouriersQuery) (GetAllCo
 	}
 
 	var couriers []CourierResponse
-	result := q.db.Raw("SELECT id,name, location_x, location_y FROM couriers").Scan(&couriers)
+	result := q.db.Raw("SELECT id,name, location_x, location_y, status FROM couriers").Scan(&couriers)
 
 	if result.Error != nil {
 		return GetAllCouriersResponse{}, result.Error
@@ -0,0 +1,82 @@
+package queries
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GetAllTransportsQuery struct {
+	isSet bool
+}
+
+func NewGetAllTransportsQuery() (GetAllTransportsQuery, error) {
+	return GetAllTransportsQuery{
+		isSet: true,
+	}, nil
+}
+
+func (q GetAllTransportsQuery) IsEmpty() bool {
+	return !q.isSet
+}
+
+type GetAllTransportsResponse struct {
+	Transports []TransportResponse
+}
+
+type TransportResponse struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name          string
+	Speed         int
+	StorageLayout []TransportStoragePlaceResponse `gorm:"-"`
+}
+
+type TransportStoragePlaceResponse struct {
+	TransportID uuid.UUID `gorm:"type:uuid"`
+	Name        string
+	TotalVolume int
+}
+
+type GetAllTransportsQueryHandler interface {
+	Handle(GetAllTransportsQuery) (GetAllTransportsResponse, error)
+}
+
+type getAllTransportsQueryHandler struct {
+	db *gorm.DB
+}
+
+func NewGetAllTransportsQueryHandler(db *gorm.DB) (GetAllTransportsQueryHandler, error) {
+	if db == nil {
+		return &getAllTransportsQueryHandler{}, errs.NewValueIsRequiredError("db")
+	}
+	return &getAllTransportsQueryHandler{db: db}, nil
+}
+
+func (q *getAllTransportsQueryHandler) Handle(query GetAllTransportsQuery) (GetAllTransportsResponse, error) {
+	if query.IsEmpty() {
+		return GetAllTransportsResponse{}, errs.NewValueIsRequiredError("query")
+	}
+
+	var transports []TransportResponse
+	result := q.db.Raw("SELECT id, name, speed FROM transports ORDER BY speed, name").Scan(&transports)
+	if result.Error != nil {
+		return GetAllTransportsResponse{}, result.Error
+	}
+
+	var storagePlaces []TransportStoragePlaceResponse
+	result = q.db.Raw("SELECT transport_id, name, total_volume FROM transport_storage_places ORDER BY transport_id, position").
+		Scan(&storagePlaces)
+	if result.Error != nil {
+		return GetAllTransportsResponse{}, result.Error
+	}
+
+	layouts := make(map[uuid.UUID][]TransportStoragePlaceResponse)
+	for _, storagePlace := range storagePlaces {
+		layouts[storagePlace.TransportID] = append(layouts[storagePlace.TransportID], storagePlace)
+	}
+	for i := range transports {
+		transports[i].StorageLayout = layouts[transports[i].ID]
+	}
+
+	return GetAllTransportsResponse{Transports: transports}, nil
+}
@@ -0,0 +1,130 @@
+package queries
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GetCourierQuery struct {
+	courierID uuid.UUID
+
+	isSet bool
+}
+
+func NewGetCourierQuery(courierID uuid.UUID) (GetCourierQuery, error) {
+	if courierID == uuid.Nil {
+		return GetCourierQuery{}, errs.NewValueIsRequiredError("courierID")
+	}
+
+	return GetCourierQuery{
+		courierID: courierID,
+		isSet:     true,
+	}, nil
+}
+
+func (q GetCourierQuery) CourierID() uuid.UUID {
+	return q.courierID
+}
+
+func (q GetCourierQuery) IsEmpty() bool {
+	return !q.isSet
+}
+
+type GetCourierResponse struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name          string
+	Speed         int
+	TransportID   uuid.UUID `gorm:"type:uuid"`
+	TransportName string
+	Location      LocationResponse `gorm:"embedded;embeddedPrefix:location_"`
+	Status        string
+	StoragePlaces []StoragePlaceResponse `gorm:"-"`
+	// OrderIDs - все заказы курьера в порядке объезда, CurrentOrderID - первый из них
+	OrderIDs       []uuid.UUID `gorm:"-"`
+	CurrentOrderID *uuid.UUID  `gorm:"-"`
+}
+
+type StoragePlaceResponse struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name        string
+	TotalVolume int
+	OrderID     *uuid.UUID
+}
+
+func (r StoragePlaceResponse) IsOccupied() bool {
+	return r.OrderID != nil
+}
+
+type GetCourierQueryHandler interface {
+	Handle(GetCourierQuery) (GetCourierResponse, error)
+}
+
+type getCourierQueryHandler struct {
+	db *gorm.DB
+}
+
+func NewGetCourierQueryHandler(db *gorm.DB) (GetCourierQueryHandler, error) {
+	if db == nil {
+		return &getCourierQueryHandler{}, errs.NewValueIsRequiredError("db")
+	}
+	return &getCourierQueryHandler{db: db}, nil
+}
+
+func (q *getCourierQueryHandler) Handle(query GetCourierQuery) (GetCourierResponse, error) {
+	if query.IsEmpty() {
+		return GetCourierResponse{}, errs.NewValueIsRequiredError("query")
+	}
+
+	var courier GetCourierResponse
+	result := q.db.Raw(`SELECT c.id, c.name, t.speed, t.id AS transport_id, t.name AS transport_name,
+			c.location_x, c.location_y, c.status
+		FROM couriers c JOIN transports t ON t.id = c.transport_id
+		WHERE c.id = ?`, query.CourierID()).Scan(&courier)
+	if result.Error != nil {
+		return GetCourierResponse{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return GetCourierResponse{}, errs.NewObjectNotFoundError("courierID", query.CourierID())
+	}
+
+	var storagePlaces []StoragePlaceResponse
+	result = q.db.Raw("SELECT id, name, total_volume, order_id FROM storage_places WHERE courier_id = ? ORDER BY name",
+		query.CourierID()).Scan(&storagePlaces)
+	if result.Error != nil {
+		return GetCourierResponse{}, result.Error
+	}
+	courier.StoragePlaces = storagePlaces
+
+	var route []uuid.UUID
+	result = q.db.Raw("SELECT order_id FROM courier_route_stops WHERE courier_id = ? ORDER BY position",
+		query.CourierID()).Scan(&route)
+	if result.Error != nil {
+		return GetCourierResponse{}, result.Error
+	}
+	courier.OrderIDs = carriedOrders(route, storagePlaces)
+	if len(courier.OrderIDs) > 0 {
+		courier.CurrentOrderID = &courier.OrderIDs[0]
+	}
+
+	return courier, nil
+}
+
+// carriedOrders упорядочивает заказы по маршруту. Заказ, которого почему-то нет в маршруте, идёт последним
+func carriedOrders(route []uuid.UUID, storagePlaces []StoragePlaceResponse) []uuid.UUID {
+	orderIDs := make([]uuid.UUID, 0, len(storagePlaces))
+	seen := make(map[uuid.UUID]struct{}, len(route))
+	for _, orderID := range route {
+		orderIDs = append(orderIDs, orderID)
+		seen[orderID] = struct{}{}
+	}
+	for _, storagePlace := range storagePlaces {
+		if !storagePlace.IsOccupied() {
+			continue
+		}
+		if _, ok := seen[*storagePlace.OrderID]; !ok {
+			orderIDs = append(orderIDs, *storagePlace.OrderID)
+		}
+	}
+	return orderIDs
+}
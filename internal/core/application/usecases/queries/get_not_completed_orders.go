@@ -28,6 +28,8 @@ type GetNotCompletedOrdersResponse struct {
 type OrderResponse struct {
 	ID       uuid.UUID        `gorm:"type:uuid;primaryKey"`
 	Location LocationResponse `gorm:"embedded;embeddedPrefix:location_"`
+	Address  AddressResponse  `gorm:"embedded;embeddedPrefix:address_"`
+	IsLate   bool
 }
 
 func (OrderResponse) TableName() string {
@@ -55,8 +57,11 @@ func (q *getNotCompletedOrdersQueryHandler) Handle(query GetNotCompletedOrdersQu
 	}
 
 	var orders []OrderResponse
-	result := q.db.Raw("SELECT id, courier_id, location_x, location_y, status FROM orders where status!=?",
-		order.StatusCompleted).Scan(&orders)
+	result := q.db.Raw(`SELECT id, courier_id, location_x, location_y,
+			address_country, address_city, address_street, address_house, address_apartment, status,
+			(delivery_to IS NOT NULL AND delivery_to < now()) AS is_late
+		FROM orders where status NOT IN ?`,
+		[]order.Status{order.StatusCompleted, order.StatusCancelled}).Scan(&orders)
 
 	if result.Error != nil {
 		return GetNotCompletedOrdersResponse{}, result.Error
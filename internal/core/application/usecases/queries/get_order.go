@@ -0,0 +1,79 @@
+package queries
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GetOrderQuery struct {
+	orderID uuid.UUID
+
+	isSet bool
+}
+
+func NewGetOrderQuery(orderID uuid.UUID) (GetOrderQuery, error) {
+	if orderID == uuid.Nil {
+		return GetOrderQuery{}, errs.NewValueIsRequiredError("orderID")
+	}
+
+	return GetOrderQuery{
+		orderID: orderID,
+		isSet:   true,
+	}, nil
+}
+
+func (q GetOrderQuery) OrderID() uuid.UUID {
+	return q.orderID
+}
+
+func (q GetOrderQuery) IsEmpty() bool {
+	return !q.isSet
+}
+
+type GetOrderResponse struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CourierID    *uuid.UUID
+	Location     LocationResponse `gorm:"embedded;embeddedPrefix:location_"`
+	Address      AddressResponse  `gorm:"embedded;embeddedPrefix:address_"`
+	Volume       int
+	Status       string
+	CancelReason string
+	IsLate       bool
+}
+
+type GetOrderQueryHandler interface {
+	Handle(GetOrderQuery) (GetOrderResponse, error)
+}
+
+type getOrderQueryHandler struct {
+	db *gorm.DB
+}
+
+func NewGetOrderQueryHandler(db *gorm.DB) (GetOrderQueryHandler, error) {
+	if db == nil {
+		return &getOrderQueryHandler{}, errs.NewValueIsRequiredError("db")
+	}
+	return &getOrderQueryHandler{db: db}, nil
+}
+
+func (q *getOrderQueryHandler) Handle(query GetOrderQuery) (GetOrderResponse, error) {
+	if query.IsEmpty() {
+		return GetOrderResponse{}, errs.NewValueIsRequiredError("query")
+	}
+
+	var order GetOrderResponse
+	result := q.db.Raw(`SELECT id, courier_id, location_x, location_y,
+			address_country, address_city, address_street, address_house, address_apartment, volume, status, cancel_reason,
+			(delivery_to IS NOT NULL AND delivery_to < now() AND status IN ('Created', 'Assigned')) AS is_late
+		FROM orders WHERE id = ?`, query.OrderID()).Scan(&order)
+
+	if result.Error != nil {
+		return GetOrderResponse{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return GetOrderResponse{}, errs.NewObjectNotFoundError("orderID", query.OrderID())
+	}
+
+	return order, nil
+}
@@ -7,7 +7,6 @@ import (
 	"delivery/internal/pkg/errs"
 	"errors"
 	"github.com/google/uuid"
-	"math"
 	"strings"
 )
 
@@ -18,24 +17,37 @@ const (
 var (
 	ErrNoStoragePlace       = errors.New("no storage place")
 	ErrOrderStorageNotFound = errors.New("order storage not found")
+	ErrStoragePlaceNotFound = errors.New("storage place not found")
+	ErrRouteIsEmpty         = errors.New("route is empty")
+	ErrRouteDoesNotMatch    = errors.New("route does not match orders taken by courier")
+	ErrPathIsInvalid        = errors.New("path must be a sequence of adjacent cells starting next to courier")
+	ErrShiftAlreadyStarted  = errors.New("courier shift has already been started")
+	ErrShiftIsNotStarted    = errors.New("courier shift is not started")
+	ErrCourierIsNotOnDuty   = errors.New("courier is not on duty")
+	ErrCourierIsNotOnBreak  = errors.New("courier is not on break")
+	ErrCourierHoldsOrders   = errors.New("courier still holds orders")
 )
 
 type Courier struct {
 	id            uuid.UUID
 	name          string
-	speed         int
+	transport     Transport
 	location      kernel.Location
+	status        Status
 	storagePlaces []*StoragePlace
+	route         []RouteStop
 
 	*ddd.BaseAggregate
 }
 
-func NewCourier(name string, speed int, location kernel.Location) (*Courier, error) {
+// NewCourier создаёт курьера на заданном транспорте и выдаёт ему места хранения этого транспорта.
+// Новый курьер сразу на смене и получает заказы
+func NewCourier(name string, transport Transport, location kernel.Location) (*Courier, error) {
 	if strings.TrimSpace(name) == "" {
 		return nil, errs.NewValueIsRequiredError("name")
 	}
-	if speed < MinSpeed {
-		return nil, errs.NewValueIsRequiredError("speed")
+	if transport.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("transport")
 	}
 
 	if location.IsEmpty() {
@@ -43,12 +55,22 @@ func NewCourier(name string, speed int, location kernel.Location) (*Courier, err
 	}
 
 	storagePlaces := make([]*StoragePlace, 0)
+	for _, item := range transport.StorageLayout() {
+		sp, err := NewStoragePlace(item.Name(), item.Volume())
+		if err != nil {
+			return nil, err
+		}
+		storagePlaces = append(storagePlaces, sp)
+	}
+
 	return &Courier{
 		id:            uuid.New(),
 		name:          name,
-		speed:         speed,
+		transport:     transport,
 		location:      location,
+		status:        StatusOnDuty,
 		storagePlaces: storagePlaces,
+		route:         make([]RouteStop, 0),
 		BaseAggregate: ddd.NewBaseAggregate(),
 	}, nil
 }
@@ -70,11 +92,53 @@ func (c *Courier) AddStoragePlace(name string, volume int) error {
 	return nil
 }
 
+func (c *Courier) RenameStoragePlace(storagePlaceID uuid.UUID, name string) error {
+	storagePlace, err := c.findStoragePlace(storagePlaceID)
+	if err != nil {
+		return err
+	}
+
+	return storagePlace.Rename(name)
+}
+
+func (c *Courier) ResizeStoragePlace(storagePlaceID uuid.UUID, totalVolume int) error {
+	storagePlace, err := c.findStoragePlace(storagePlaceID)
+	if err != nil {
+		return err
+	}
+
+	return storagePlace.Resize(totalVolume)
+}
+
+// RemoveStoragePlace убирает место хранения. Место с заказом убрать нельзя
+func (c *Courier) RemoveStoragePlace(storagePlaceID uuid.UUID) error {
+	storagePlace, err := c.findStoragePlace(storagePlaceID)
+	if err != nil {
+		return err
+	}
+	if storagePlace.isOccupied() {
+		return ErrStoragePlaceIsOccupied
+	}
+
+	for i, sp := range c.storagePlaces {
+		if sp.Equals(storagePlace) {
+			c.storagePlaces = append(c.storagePlaces[:i:i], c.storagePlaces[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
 func (c *Courier) CanTakeOrder(order *order.Order) (bool, error) {
 	if order == nil {
 		return false, errs.NewValueIsRequiredError("order")
 	}
 
+	// Заказы получают только курьеры на смене и не на перерыве
+	if c.status != StatusOnDuty {
+		return false, nil
+	}
+
 	if c.storagePlaces == nil {
 		return false, nil
 	}
@@ -114,10 +178,17 @@ func (c *Courier) TakeOrder(order *order.Order) error {
 		return ErrNoStoragePlace
 	}
 
+	stop, err := NewRouteStop(order.ID(), order.Location())
+	if err != nil {
+		return err
+	}
+
 	if err = freeStorage.Store(order.ID(), order.Volume()); err != nil {
 		return err
 	}
 
+	// Новая точка встаёт в конец маршрута, порядок потом пересчитывает планировщик
+	c.route = append(c.route, stop)
 	return nil
 }
 
@@ -126,7 +197,20 @@ func (c *Courier) CompleteOrder(order *order.Order) error {
 		return errs.NewValueIsRequiredError("order")
 	}
 
-	storage, err := c.findOrderStorage(order.ID())
+	return c.clearOrderStorage(order.ID())
+}
+
+// ReleaseOrder освобождает место хранения заказа, который курьер больше не доставляет (например, заказ отменён)
+func (c *Courier) ReleaseOrder(order *order.Order) error {
+	if order == nil {
+		return errs.NewValueIsRequiredError("order")
+	}
+
+	return c.clearOrderStorage(order.ID())
+}
+
+func (c *Courier) clearOrderStorage(orderID uuid.UUID) error {
+	storage, err := c.findOrderStorage(orderID)
 	if err != nil {
 		return err
 	}
@@ -135,55 +219,219 @@ func (c *Courier) CompleteOrder(order *order.Order) error {
 		return ErrOrderStorageNotFound
 	}
 
-	if err = storage.Clear(order.ID()); err != nil {
+	if err = storage.Clear(orderID); err != nil {
 		return err
 	}
 
+	c.removeRouteStop(orderID)
+	return nil
+}
+
+// StartShift выводит курьера на смену
+func (c *Courier) StartShift() error {
+	if c.status != StatusOffDuty {
+		return ErrShiftAlreadyStarted
+	}
+
+	c.status = StatusOnDuty
+	return nil
+}
+
+// EndShift завершает смену. Нельзя уйти со смены, пока у курьера есть заказы
+func (c *Courier) EndShift() error {
+	if c.status == StatusOffDuty {
+		return ErrShiftIsNotStarted
+	}
+	if c.holdsOrders() {
+		return ErrCourierHoldsOrders
+	}
+
+	c.status = StatusOffDuty
+	return nil
+}
+
+// StartBreak отправляет курьера на перерыв. Сначала нужно развезти все заказы
+func (c *Courier) StartBreak() error {
+	if c.status != StatusOnDuty {
+		return ErrCourierIsNotOnDuty
+	}
+	if c.holdsOrders() {
+		return ErrCourierHoldsOrders
+	}
+
+	c.status = StatusOnBreak
+	return nil
+}
+
+// EndBreak возвращает курьера с перерыва на смену
+func (c *Courier) EndBreak() error {
+	if c.status != StatusOnBreak {
+		return ErrCourierIsNotOnBreak
+	}
+
+	c.status = StatusOnDuty
+	return nil
+}
+
+func (c *Courier) holdsOrders() bool {
+	for _, storagePlace := range c.storagePlaces {
+		if storagePlace.isOccupied() {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplanRoute задаёт новый порядок объезда. Набор точек должен совпадать с текущим маршрутом
+func (c *Courier) ReplanRoute(stops []RouteStop) error {
+	if len(stops) != len(c.route) {
+		return ErrRouteDoesNotMatch
+	}
+
+	current := make(map[uuid.UUID]RouteStop, len(c.route))
+	for _, stop := range c.route {
+		current[stop.OrderID()] = stop
+	}
+	for _, stop := range stops {
+		existing, ok := current[stop.OrderID()]
+		if !ok || !existing.Equals(stop) {
+			return ErrRouteDoesNotMatch
+		}
+		delete(current, stop.OrderID())
+	}
+
+	c.route = append(make([]RouteStop, 0, len(stops)), stops...)
 	return nil
 }
 
-func (c *Courier) CalculateTimeToLocation(target kernel.Location) (float64, error) {
+// NextStop возвращает ближайшую по маршруту точку
+func (c *Courier) NextStop() (RouteStop, bool) {
+	if len(c.route) == 0 {
+		return RouteStop{}, false
+	}
+	return c.route[0], true
+}
+
+// MoveAlongRoute делает один шаг к следующей точке маршрута в объезд препятствий
+func (c *Courier) MoveAlongRoute(navigator Navigator) error {
+	if navigator == nil {
+		return errs.NewValueIsRequiredError("navigator")
+	}
+
+	stop, ok := c.NextStop()
+	if !ok {
+		return ErrRouteIsEmpty
+	}
+
+	path, err := navigator.FindPath(c.location, stop.Location())
+	if err != nil {
+		return err
+	}
+	return c.MoveAlongPath(path)
+}
+
+// ReachedStops возвращает идущие подряд точки маршрута, в которых курьер уже находится
+func (c *Courier) ReachedStops() []RouteStop {
+	var reached []RouteStop
+	for _, stop := range c.route {
+		if !stop.Location().Equals(c.location) {
+			break
+		}
+		reached = append(reached, stop)
+	}
+	return reached
+}
+
+func (c *Courier) removeRouteStop(orderID uuid.UUID) {
+	for i, stop := range c.route {
+		if stop.OrderID() == orderID {
+			c.route = append(c.route[:i:i], c.route[i+1:]...)
+			return
+		}
+	}
+}
+
+// EstimateTimeToLocation - нижняя оценка времени по манхэттенскому расстоянию: объезд препятствий её только увеличивает
+func (c *Courier) EstimateTimeToLocation(target kernel.Location) (float64, error) {
 	if target.IsEmpty() {
 		return 0, errs.NewValueIsRequiredError("target")
 	}
+	if c.Speed() < MinSpeed {
+		return 0, errs.NewValueIsOutOfRangeError("speed", c.Speed(), MinSpeed, nil)
+	}
+
 	distance, err := c.location.CountDistanceTo(target)
 	if err != nil {
 		return 0, err
 	}
-
-	time := float64(distance) / float64(c.speed)
-	return time, err
+	return float64(distance) / float64(c.Speed()), nil
 }
 
-func (c *Courier) Move(target kernel.Location) error {
+// CalculateTimeToLocation считает, за сколько шагов курьер доберётся до точки по реальному пути
+func (c *Courier) CalculateTimeToLocation(target kernel.Location, navigator Navigator) (float64, error) {
 	if target.IsEmpty() {
-		return errs.NewValueIsRequiredError("target")
+		return 0, errs.NewValueIsRequiredError("target")
+	}
+	if navigator == nil {
+		return 0, errs.NewValueIsRequiredError("navigator")
 	}
 
-	dx := float64(target.X()) - float64(c.location.X())
-	dy := float64(target.Y()) - float64(c.location.Y())
-	remainingRange := float64(c.speed)
+	path, err := navigator.FindPath(c.location, target)
+	if err != nil {
+		return 0, err
+	}
 
-	if math.Abs(dx) > remainingRange {
-		dx = math.Copysign(remainingRange, dx)
+	if c.Speed() < MinSpeed {
+		return 0, errs.NewValueIsOutOfRangeError("speed", c.Speed(), MinSpeed, nil)
 	}
-	remainingRange -= math.Abs(dx)
 
-	if math.Abs(dy) > remainingRange {
-		dy = math.Copysign(remainingRange, dy)
+	time := float64(len(path)) / float64(c.Speed())
+	return time, nil
+}
+
+// MoveAlongPath проходит по пути столько клеток, сколько позволяет скорость
+func (c *Courier) MoveAlongPath(path []kernel.Location) error {
+	if c.Speed() < MinSpeed {
+		return errs.NewValueIsOutOfRangeError("speed", c.Speed(), MinSpeed, nil)
 	}
 
-	newX := float64(c.location.X()) + dx
-	newY := float64(c.location.Y()) + dy
+	previous := c.location
+	for _, cell := range path {
+		if cell.IsEmpty() {
+			return ErrPathIsInvalid
+		}
+		distance, err := previous.CountDistanceTo(cell)
+		if err != nil {
+			return err
+		}
+		if distance != 1 {
+			return ErrPathIsInvalid
+		}
+		previous = cell
+	}
 
-	newLocation, err := kernel.NewLocation(uint8(newX), uint8(newY))
-	if err != nil {
-		return err
+	if len(path) == 0 {
+		return nil
 	}
-	c.location = newLocation
+	steps := min(c.Speed(), len(path))
+	c.location = path[steps-1]
 	return nil
 }
 
+func (c *Courier) findStoragePlace(storagePlaceID uuid.UUID) (*StoragePlace, error) {
+	if storagePlaceID == uuid.Nil {
+		return nil, errs.NewValueIsRequiredError("storagePlaceID")
+	}
+
+	for _, storagePlace := range c.storagePlaces {
+		if storagePlace.ID() == storagePlaceID {
+			return storagePlace, nil
+		}
+	}
+
+	return nil, ErrStoragePlaceNotFound
+}
+
 func (c *Courier) findOrderStorage(orderID uuid.UUID) (*StoragePlace, error) {
 	if orderID == uuid.Nil {
 		return nil, errs.NewValueIsRequiredError("order")
@@ -223,13 +471,21 @@ func (c *Courier) Name() string {
 }
 
 func (c *Courier) Speed() int {
-	return c.speed
+	return c.transport.Speed()
+}
+
+func (c *Courier) Transport() Transport {
+	return c.transport
 }
 
 func (c *Courier) Location() kernel.Location {
 	return c.location
 }
 
+func (c *Courier) Status() Status {
+	return c.status
+}
+
 func (c *Courier) StoragePlaces() []StoragePlace {
 	res := make([]StoragePlace, len(c.storagePlaces))
 	for i, storagePlace := range c.storagePlaces {
@@ -238,6 +494,12 @@ func (c *Courier) StoragePlaces() []StoragePlace {
 	return res
 }
 
+func (c *Courier) Route() []RouteStop {
+	res := make([]RouteStop, len(c.route))
+	copy(res, c.route)
+	return res
+}
+
 func (c *Courier) Equals(other *Courier) bool {
 	if other == nil {
 		return false
@@ -250,16 +512,21 @@ func (c *Courier) Equals(other *Courier) bool {
 func RestoreCourier(
 	id uuid.UUID,
 	name string,
-	speed int,
+	transport Transport,
 	location kernel.Location,
+	status Status,
 	storagePlaces []*StoragePlace,
+	route []RouteStop,
+	version int64,
 ) *Courier {
 	return &Courier{
 		id:            id,
 		name:          name,
-		speed:         speed,
+		transport:     transport,
 		location:      location,
+		status:        status,
 		storagePlaces: storagePlaces,
-		BaseAggregate: ddd.NewBaseAggregate(),
+		route:         route,
+		BaseAggregate: ddd.NewBaseAggregateWithVersion(version),
 	}
 }
@@ -0,0 +1,22 @@
+package courier
+
+const (
+	StatusEmpty   Status = ""
+	StatusOffDuty Status = "OffDuty"
+	StatusOnDuty  Status = "OnDuty"
+	StatusOnBreak Status = "OnBreak"
+)
+
+type Status string
+
+func (s Status) Equals(other Status) bool {
+	return s == other
+}
+
+func (s Status) IsEmpty() bool {
+	return s == StatusEmpty
+}
+
+func (s Status) String() string {
+	return string(s)
+}
@@ -4,6 +4,7 @@ import (
 	"delivery/internal/core/domain/model/kernel"
 	"delivery/internal/core/domain/model/order"
 	"delivery/internal/pkg/errs"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -12,43 +13,61 @@ import (
 func Test_createNewCourier(t *testing.T) {
 	t.Run("given valid parameters when create courier then success", func(t *testing.T) {
 		name := "Test"
-		speed := 5
+		transport := createTestTransport(t, 5)
 		loc := createTestLocation(t)
 
-		c, err := NewCourier(name, speed, loc)
+		c, err := NewCourier(name, transport, loc)
 
 		assert.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, c.ID())
 		assert.Equal(t, name, c.Name())
-		assert.Equal(t, speed, c.Speed())
+		assert.Equal(t, 5, c.Speed())
+		assert.True(t, transport.Equals(c.Transport()))
 		assert.Equal(t, loc, c.Location())
 		assert.NotNil(t, c.StoragePlaces())
 		assert.Empty(t, c.StoragePlaces())
 		assert.NotNil(t, c.BaseAggregate)
 	})
 
+	t.Run("given transport with storage layout when create courier then storage places are added", func(t *testing.T) {
+		trunk, _ := NewStorageLayoutItem("Trunk", 20)
+		bag, _ := NewStorageLayoutItem("Bag", 5)
+		transport, err := NewTransport(uuid.New(), "Car", 3, []StorageLayoutItem{trunk, bag})
+		assert.NoError(t, err)
+
+		c, err := NewCourier("Test", transport, createTestLocation(t))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, c.Speed())
+		assert.Len(t, c.StoragePlaces(), 2)
+		assert.Equal(t, "Trunk", c.StoragePlaces()[0].Name())
+		assert.Equal(t, 20, c.StoragePlaces()[0].TotalVolume())
+		assert.Equal(t, "Bag", c.StoragePlaces()[1].Name())
+		assert.Equal(t, 5, c.StoragePlaces()[1].TotalVolume())
+	})
+
 	t.Run("given invalid parameters when create new courier then return error", func(t *testing.T) {
 		validName := "Test Courier"
-		validSpeed := 5
+		validTransport := createTestTransport(t, 5)
 		validLoc := createTestLocation(t)
 
 		tests := map[string]struct {
-			name     string
-			speed    int
-			location kernel.Location
-			expected error
+			name      string
+			transport Transport
+			location  kernel.Location
+			expected  error
 		}{
-			"empty_name":     {"", validSpeed, validLoc, errs.NewValueIsRequiredError("name")},
-			"blank_name":     {"   ", validSpeed, validLoc, errs.NewValueIsRequiredError("name")},
-			"zero_speed":     {validName, 0, validLoc, errs.NewValueIsRequiredError("speed")},
-			"negative_speed": {validName, -1, validLoc, errs.NewValueIsRequiredError("speed")},
-			"empty_location": {validName, validSpeed, kernel.Location{}, errs.NewValueIsRequiredError("location")},
+			"empty_name":      {"", validTransport, validLoc, errs.NewValueIsRequiredError("name")},
+			"blank_name":      {"   ", validTransport, validLoc, errs.NewValueIsRequiredError("name")},
+			"empty_transport": {validName, Transport{}, validLoc, errs.NewValueIsRequiredError("transport")},
+			"empty_location":  {validName, validTransport, kernel.Location{}, errs.NewValueIsRequiredError("location")},
 		}
 
 		for name, test := range tests {
 			t.Run(name, func(t *testing.T) {
-				_, err := NewCourier(test.name, test.speed, test.location)
-				assert.Errorf(t, err, test.expected.Error())
+				_, err := NewCourier(test.name, test.transport, test.location)
+				assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+				assert.EqualError(t, err, test.expected.Error())
 			})
 		}
 	})
@@ -94,6 +113,54 @@ func Test_addStoragePlace(t *testing.T) {
 	})
 }
 
+func TestCourier_ManageStoragePlaces(t *testing.T) {
+	t.Run("given free storage place when rename and resize then success", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 5)
+		id := c.StoragePlaces()[0].ID()
+
+		assert.NoError(t, c.RenameStoragePlace(id, "Backpack"))
+		assert.NoError(t, c.ResizeStoragePlace(id, 2))
+
+		assert.Equal(t, "Backpack", c.StoragePlaces()[0].Name())
+		assert.Equal(t, 2, c.StoragePlaces()[0].TotalVolume())
+	})
+
+	t.Run("given occupied storage place when shrink or remove then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 5)
+		_ = c.TakeOrder(createTestOrderWithVolume(t, 3))
+		id := c.StoragePlaces()[0].ID()
+
+		assert.ErrorIs(t, c.ResizeStoragePlace(id, 4), ErrStoragePlaceIsOccupied)
+		assert.ErrorIs(t, c.RemoveStoragePlace(id), ErrStoragePlaceIsOccupied)
+		assert.Len(t, c.StoragePlaces(), 1)
+		assert.Equal(t, 5, c.StoragePlaces()[0].TotalVolume())
+	})
+
+	t.Run("given free storage place when remove then other places are kept", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 5)
+		_ = c.AddStoragePlace("Trunk", 20)
+		bag := c.StoragePlaces()[0]
+
+		err := c.RemoveStoragePlace(bag.ID())
+
+		assert.NoError(t, err)
+		assert.Len(t, c.StoragePlaces(), 1)
+		assert.Equal(t, "Trunk", c.StoragePlaces()[0].Name())
+	})
+
+	t.Run("given unknown storage place when change then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+
+		assert.ErrorIs(t, c.RenameStoragePlace(uuid.New(), "Bag"), ErrStoragePlaceNotFound)
+		assert.ErrorIs(t, c.ResizeStoragePlace(uuid.New(), 5), ErrStoragePlaceNotFound)
+		assert.ErrorIs(t, c.RemoveStoragePlace(uuid.New()), ErrStoragePlaceNotFound)
+		assert.ErrorIs(t, c.RemoveStoragePlace(uuid.Nil), errs.ErrValueIsRequired)
+	})
+}
+
 func Test_canTakeOrder(t *testing.T) {
 	t.Run("given no storage places when check can take order then return false", func(t *testing.T) {
 		c := createTestCourier(t)
@@ -150,18 +217,301 @@ func TestCourier_TakeOrder(t *testing.T) {
 	})
 }
 
+func TestCourier_ReleaseOrder(t *testing.T) {
+	t.Run("given taken order when release order then storage place is free", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		o := createTestOrderWithVolume(t, 5)
+		_ = c.TakeOrder(o)
+
+		err := c.ReleaseOrder(o)
+
+		assert.NoError(t, err)
+		assert.False(t, c.StoragePlaces()[0].isOccupied())
+		canTake, _ := c.CanTakeOrder(createTestOrderWithVolume(t, 5))
+		assert.True(t, canTake)
+	})
+
+	t.Run("given order not held by courier when release order then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		o := createTestOrder(t)
+
+		err := c.ReleaseOrder(o)
+		assert.ErrorIs(t, err, ErrOrderStorageNotFound)
+	})
+
+	t.Run("given nil order when release order then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+
+		err := c.ReleaseOrder(nil)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func TestCourier_Route(t *testing.T) {
+	t.Run("given two taken orders when take order then stops are appended to route", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.AddStoragePlace("Trunk", 10)
+		first := createTestOrderAt(t, createLocation(t, 1, 1))
+		second := createTestOrderAt(t, createLocation(t, 9, 9))
+
+		assert.NoError(t, c.TakeOrder(first))
+		assert.NoError(t, c.TakeOrder(second))
+
+		route := c.Route()
+		assert.Len(t, route, 2)
+		assert.Equal(t, first.ID(), route[0].OrderID())
+		assert.Equal(t, second.ID(), route[1].OrderID())
+		next, ok := c.NextStop()
+		assert.True(t, ok)
+		assert.Equal(t, first.ID(), next.OrderID())
+	})
+
+	t.Run("given completed order when complete order then its stop leaves the route", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.AddStoragePlace("Trunk", 10)
+		first := createTestOrderAt(t, createLocation(t, 1, 1))
+		second := createTestOrderAt(t, createLocation(t, 9, 9))
+		_ = c.TakeOrder(first)
+		_ = c.TakeOrder(second)
+
+		assert.NoError(t, c.CompleteOrder(first))
+
+		route := c.Route()
+		assert.Len(t, route, 1)
+		assert.Equal(t, second.ID(), route[0].OrderID())
+	})
+
+	t.Run("given reordered stops when replan route then route follows new order", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.AddStoragePlace("Trunk", 10)
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 1, 1)))
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 9, 9)))
+		route := c.Route()
+
+		err := c.ReplanRoute([]RouteStop{route[1], route[0]})
+
+		assert.NoError(t, err)
+		assert.Equal(t, route[1], c.Route()[0])
+	})
+
+	t.Run("given foreign stop when replan route then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 1, 1)))
+		foreign, _ := NewRouteStop(uuid.New(), createLocation(t, 1, 1))
+
+		err := c.ReplanRoute([]RouteStop{foreign})
+
+		assert.ErrorIs(t, err, ErrRouteDoesNotMatch)
+	})
+
+	t.Run("given empty route when move along route then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+
+		err := c.MoveAlongRoute(straightNavigator{})
+
+		assert.ErrorIs(t, err, ErrRouteIsEmpty)
+	})
+
+	t.Run("given stops at the same place when reach it then all of them are reached", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 1), createLocation(t, 1, 1))
+		_ = c.StartShift()
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.AddStoragePlace("Trunk", 10)
+		_ = c.AddStoragePlace("Box", 10)
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 2, 1)))
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 2, 1)))
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 3, 1)))
+
+		assert.Empty(t, c.ReachedStops())
+		assert.NoError(t, c.MoveAlongRoute(straightNavigator{}))
+
+		assert.Equal(t, createLocation(t, 2, 1), c.Location())
+		assert.Len(t, c.ReachedStops(), 2)
+	})
+}
+
+func TestCourier_Shift(t *testing.T) {
+	t.Run("given new courier when created then courier is on duty and can take orders", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 1), createLocation(t, 1, 1))
+		_ = c.AddStoragePlace("Bag", 10)
+
+		canTake, err := c.CanTakeOrder(createTestOrder(t))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusOnDuty, c.Status())
+		assert.True(t, canTake)
+	})
+
+	t.Run("given off-duty courier when check can take order then return false", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 1), createLocation(t, 1, 1))
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.EndShift()
+
+		canTake, err := c.CanTakeOrder(createTestOrder(t))
+
+		assert.NoError(t, err)
+		assert.False(t, canTake)
+	})
+
+	t.Run("given full shift cycle when change status then transitions succeed", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 1), createLocation(t, 1, 1))
+		assert.NoError(t, c.EndShift())
+
+		assert.NoError(t, c.StartShift())
+		assert.Equal(t, StatusOnDuty, c.Status())
+		assert.NoError(t, c.StartBreak())
+		assert.Equal(t, StatusOnBreak, c.Status())
+		assert.NoError(t, c.EndBreak())
+		assert.Equal(t, StatusOnDuty, c.Status())
+		assert.NoError(t, c.EndShift())
+		assert.Equal(t, StatusOffDuty, c.Status())
+	})
+
+	t.Run("given courier on break when check can take order then return false", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.StartBreak()
+
+		canTake, err := c.CanTakeOrder(createTestOrder(t))
+
+		assert.NoError(t, err)
+		assert.False(t, canTake)
+	})
+
+	t.Run("given courier with order when end shift or start break then return error", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.TakeOrder(createTestOrder(t))
+
+		assert.ErrorIs(t, c.EndShift(), ErrCourierHoldsOrders)
+		assert.ErrorIs(t, c.StartBreak(), ErrCourierHoldsOrders)
+		assert.Equal(t, StatusOnDuty, c.Status())
+	})
+
+	t.Run("given wrong status when change status then return error", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 1), createLocation(t, 1, 1))
+		_ = c.EndShift()
+
+		assert.ErrorIs(t, c.EndShift(), ErrShiftIsNotStarted)
+		assert.ErrorIs(t, c.StartBreak(), ErrCourierIsNotOnDuty)
+		assert.ErrorIs(t, c.EndBreak(), ErrCourierIsNotOnBreak)
+		_ = c.StartShift()
+		assert.ErrorIs(t, c.StartShift(), ErrShiftAlreadyStarted)
+	})
+}
+
 func Test_calculateTimeToLocation(t *testing.T) {
 	t.Run("given valid target when calculate time to location then return correct value", func(t *testing.T) {
 		startLoc := createLocation(t, 1, 1)
-		c, _ := NewCourier("test", 2, startLoc)
+		c, _ := NewCourier("test", createTestTransport(t, 2), startLoc)
 		c.location = startLoc
 		targetLoc := createLocation(t, 4, 5)
 
-		time, err := c.CalculateTimeToLocation(targetLoc)
+		time, err := c.CalculateTimeToLocation(targetLoc, straightNavigator{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, 3.5, time)
 	})
+
+	t.Run("given detour when calculate time to location then use real path length", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 2), createLocation(t, 1, 1))
+		detour := fixedNavigator{path: []kernel.Location{
+			createLocation(t, 1, 2), createLocation(t, 2, 2), createLocation(t, 3, 2), createLocation(t, 3, 1),
+		}}
+
+		time, err := c.CalculateTimeToLocation(createLocation(t, 3, 1), detour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, time)
+	})
+
+	t.Run("given nil navigator when calculate time to location then return error", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 2), createLocation(t, 1, 1))
+
+		_, err := c.CalculateTimeToLocation(createLocation(t, 3, 1), nil)
+
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func TestCourier_MoveAlongPath(t *testing.T) {
+	t.Run("given path longer than speed when move then stop after speed cells", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 2), createLocation(t, 1, 1))
+		path := []kernel.Location{createLocation(t, 1, 2), createLocation(t, 2, 2), createLocation(t, 3, 2)}
+
+		err := c.MoveAlongPath(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, createLocation(t, 2, 2), c.Location())
+	})
+
+	t.Run("given courier without speed when move then return error", func(t *testing.T) {
+		c := RestoreCourier(uuid.New(), "test", Transport{}, createLocation(t, 1, 1), StatusOnDuty, nil, nil, 0)
+
+		err := c.MoveAlongPath([]kernel.Location{createLocation(t, 1, 2)})
+
+		assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+		assert.Equal(t, createLocation(t, 1, 1), c.Location())
+	})
+
+	t.Run("given path with a jump when move then return error", func(t *testing.T) {
+		c, _ := NewCourier("test", createTestTransport(t, 2), createLocation(t, 1, 1))
+		path := []kernel.Location{createLocation(t, 1, 2), createLocation(t, 3, 2)}
+
+		err := c.MoveAlongPath(path)
+
+		assert.ErrorIs(t, err, ErrPathIsInvalid)
+		assert.Equal(t, createLocation(t, 1, 1), c.Location())
+	})
+
+	t.Run("given navigator error when move along route then return it", func(t *testing.T) {
+		c := createTestCourier(t)
+		_ = c.AddStoragePlace("Bag", 10)
+		_ = c.TakeOrder(createTestOrderAt(t, createLocation(t, 3, 3)))
+		errNoPath := errors.New("no path")
+
+		err := c.MoveAlongRoute(fixedNavigator{err: errNoPath})
+
+		assert.ErrorIs(t, err, errNoPath)
+	})
+}
+
+// straightNavigator ведёт курьера сначала по X, затем по Y, как по карте без препятствий
+type straightNavigator struct{}
+
+func (straightNavigator) FindPath(from kernel.Location, to kernel.Location) ([]kernel.Location, error) {
+	var path []kernel.Location
+	x, y := from.X(), from.Y()
+	for x != to.X() || y != to.Y() {
+		switch {
+		case x < to.X():
+			x++
+		case x > to.X():
+			x--
+		case y < to.Y():
+			y++
+		default:
+			y--
+		}
+		path = append(path, kernel.RestoreLocation(x, y))
+	}
+	return path, nil
+}
+
+// fixedNavigator всегда возвращает заранее заданный путь или ошибку
+type fixedNavigator struct {
+	path []kernel.Location
+	err  error
+}
+
+func (n fixedNavigator) FindPath(kernel.Location, kernel.Location) ([]kernel.Location, error) {
+	return n.path, n.err
 }
 
 func Test_moveToTargetLocation(t *testing.T) {
@@ -179,8 +529,10 @@ func Test_moveToTargetLocation(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run("when "+name+" then reach expected location", func(t *testing.T) {
-			c, _ := NewCourier("test", test.speed, test.startLocation)
-			err := c.Move(test.targetLocation)
+			c, _ := NewCourier("test", createTestTransport(t, test.speed), test.startLocation)
+			path, err := straightNavigator{}.FindPath(test.startLocation, test.targetLocation)
+			assert.NoError(t, err)
+			err = c.MoveAlongPath(path)
 
 			assert.NoError(t, err)
 			assert.Equal(t, test.expectedLocation, c.Location())
@@ -195,7 +547,7 @@ func Test_equals(t *testing.T) {
 		c1Copy := &Courier{
 			id:            c1.id,
 			name:          "Copy",
-			speed:         100,
+			transport:     createTestTransport(t, 100),
 			location:      createTestLocation(t),
 			storagePlaces: []*StoragePlace{},
 		}
@@ -225,6 +577,7 @@ func Test_RestoreCourier(t *testing.T) {
 		expectedID := uuid.New()
 		expectedName := "Name"
 		expectedSpeed := 5
+		expectedTransport := createTestTransport(t, expectedSpeed)
 		expectedLocation := createLocation(t, 1, 1)
 		expectedSP := make([]*StoragePlace, 0)
 		for i := 1; i <= 5; i++ {
@@ -235,45 +588,69 @@ func Test_RestoreCourier(t *testing.T) {
 		result := RestoreCourier(
 			expectedID,
 			expectedName,
-			expectedSpeed,
+			expectedTransport,
 			expectedLocation,
+			StatusOnBreak,
 			expectedSP,
+			nil,
+			3,
 		)
 
 		assert.Equal(t, result.ID(), expectedID)
 		assert.Equal(t, result.Name(), expectedName)
 		assert.Equal(t, result.Speed(), expectedSpeed)
+		assert.True(t, result.Transport().Equals(expectedTransport))
 		assert.Equal(t, result.Location(), expectedLocation)
 		assert.Equal(t, len(result.StoragePlaces()), len(expectedSP))
+		assert.Equal(t, StatusOnBreak, result.Status())
+		assert.Equal(t, int64(3), result.Version())
 	})
 }
 
 func createTestOrder(t *testing.T) *order.Order {
-	o, err := order.NewOrder(uuid.New(), createTestLocation(t), 5)
+	o, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t), 5)
+	assert.NoError(t, err)
+	return o
+}
+
+func createTestOrderAt(t *testing.T, location kernel.Location) *order.Order {
+	o, err := order.NewOrder(uuid.New(), createTestAddress(t), location, 5)
 	assert.NoError(t, err)
 	return o
 }
 
 func createTestOrderWithVolume(t *testing.T, volume int) *order.Order {
-	o, err := order.NewOrder(uuid.New(), createTestLocation(t), volume)
+	o, err := order.NewOrder(uuid.New(), createTestAddress(t), createTestLocation(t), volume)
 	assert.NoError(t, err)
 	return o
 }
 
 func createTestCourier(t *testing.T) *Courier {
-	c, err := NewCourier("Test Courier", 5, createLocation(t, 5, 5))
+	c, err := NewCourier("Test Courier", createTestTransport(t, 5), createLocation(t, 5, 5))
 	assert.NoError(t, err)
 	return c
 }
 
+func createTestTransport(t *testing.T, speed int) Transport {
+	transport, err := NewTransport(uuid.New(), "Test Transport", speed, nil)
+	assert.NoError(t, err)
+	return transport
+}
+
+func createTestAddress(t *testing.T) kernel.Address {
+	address, err := kernel.NewAddress("", "", "Тестовая", "", "")
+	assert.NoError(t, err)
+	return address
+}
+
 func createTestLocation(t *testing.T) kernel.Location {
-	loc, err := kernel.NewLocation(5, 5) // центральное положение
+	loc, err := kernel.NewLocation(kernel.DefaultGrid(), 5, 5) // центральное положение
 	assert.NoError(t, err)
 	return loc
 }
 
-func createLocation(t *testing.T, x, y uint8) kernel.Location {
-	loc, err := kernel.NewLocation(x, y)
+func createLocation(t *testing.T, x, y int) kernel.Location {
+	loc, err := kernel.NewLocation(kernel.DefaultGrid(), x, y)
 	assert.NoError(t, err)
 	return loc
 }
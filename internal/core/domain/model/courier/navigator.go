@@ -0,0 +1,11 @@
+package courier
+
+import (
+	"delivery/internal/core/domain/model/kernel"
+)
+
+// Navigator прокладывает путь по карте города с учётом непроходимых клеток
+type Navigator interface {
+	// FindPath возвращает клетки пути от from (не включая) до to (включая). Если from == to, путь пустой
+	FindPath(from kernel.Location, to kernel.Location) ([]kernel.Location, error)
+}
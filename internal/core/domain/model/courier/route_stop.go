@@ -0,0 +1,55 @@
+package courier
+
+import (
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+)
+
+// RouteStop - точка маршрута курьера: куда доставить заказ
+type RouteStop struct {
+	orderID  uuid.UUID
+	location kernel.Location
+
+	isSet bool
+}
+
+func NewRouteStop(orderID uuid.UUID, location kernel.Location) (RouteStop, error) {
+	if orderID == uuid.Nil {
+		return RouteStop{}, errs.NewValueIsRequiredError("orderID")
+	}
+	if location.IsEmpty() {
+		return RouteStop{}, errs.NewValueIsRequiredError("location")
+	}
+
+	return RouteStop{
+		orderID:  orderID,
+		location: location,
+		isSet:    true,
+	}, nil
+}
+
+func (s RouteStop) OrderID() uuid.UUID {
+	return s.orderID
+}
+
+func (s RouteStop) Location() kernel.Location {
+	return s.location
+}
+
+func (s RouteStop) Equals(other RouteStop) bool {
+	return s.orderID == other.orderID && s.location.Equals(other.location)
+}
+
+func (s RouteStop) IsEmpty() bool {
+	return !s.isSet
+}
+
+// RestoreRouteStop restore RouteStop from db. DO NOT USE IN DOMAIN!
+func RestoreRouteStop(orderID uuid.UUID, location kernel.Location) RouteStop {
+	return RouteStop{
+		orderID:  orderID,
+		location: location,
+		isSet:    true,
+	}
+}
@@ -14,6 +14,7 @@ const (
 var (
 	ErrCannotStoreOrderInThisStoragePlace = errors.New("cannot store order in this storage place")
 	ErrOrderNotStoredInThisPlace          = errors.New("order is not stored in this place")
+	ErrStoragePlaceIsOccupied             = errors.New("storage place is occupied")
 )
 
 type StoragePlace struct {
@@ -83,6 +84,28 @@ func (s *StoragePlace) Clear(orderID uuid.UUID) error {
 	return nil
 }
 
+func (s *StoragePlace) Rename(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errs.NewValueIsRequiredError("name")
+	}
+
+	s.name = name
+	return nil
+}
+
+// Resize меняет объём места хранения. Занятое место уменьшать нельзя, иначе заказ может перестать в нём помещаться
+func (s *StoragePlace) Resize(totalVolume int) error {
+	if totalVolume < MinVolume {
+		return errs.NewValueIsRequiredError("totalVolume")
+	}
+	if s.isOccupied() && totalVolume < s.totalVolume {
+		return ErrStoragePlaceIsOccupied
+	}
+
+	s.totalVolume = totalVolume
+	return nil
+}
+
 func (s *StoragePlace) ID() uuid.UUID {
 	return s.id
 }
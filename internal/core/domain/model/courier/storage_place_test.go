@@ -194,6 +194,28 @@ func Test_givenInvalidParams_whenClear_thenReturnError(t *testing.T) {
 	}
 }
 
+func Test_givenOccupiedStorage_whenResize_thenOnlyGrowingIsAllowed(t *testing.T) {
+	storagePlace, _ := NewStoragePlace("Bag", 5)
+	_ = storagePlace.Store(uuid.New(), 3)
+
+	err := storagePlace.Resize(4)
+	assert.ErrorIs(t, err, ErrStoragePlaceIsOccupied)
+	assert.Equal(t, 5, storagePlace.TotalVolume())
+
+	err = storagePlace.Resize(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, storagePlace.TotalVolume())
+}
+
+func Test_givenInvalidParams_whenRenameOrResize_thenReturnError(t *testing.T) {
+	storagePlace, _ := NewStoragePlace("Bag", 5)
+
+	assert.ErrorIs(t, storagePlace.Rename("  "), errs.ErrValueIsRequired)
+	assert.ErrorIs(t, storagePlace.Resize(0), errs.ErrValueIsRequired)
+	assert.Equal(t, "Bag", storagePlace.Name())
+	assert.Equal(t, 5, storagePlace.TotalVolume())
+}
+
 func Test_RestoreStoragePlace(t *testing.T) {
 	t.Run("Must correctly resotre", func(t *testing.T) {
 		expectedID := uuid.New()
@@ -0,0 +1,116 @@
+package courier
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// StorageLayoutItem - место хранения, которое выдаётся курьеру вместе с транспортом
+type StorageLayoutItem struct {
+	name   string
+	volume int
+
+	isSet bool
+}
+
+func NewStorageLayoutItem(name string, volume int) (StorageLayoutItem, error) {
+	if strings.TrimSpace(name) == "" {
+		return StorageLayoutItem{}, errs.NewValueIsRequiredError("name")
+	}
+	if volume < MinVolume {
+		return StorageLayoutItem{}, errs.NewValueIsRequiredError("volume")
+	}
+
+	return StorageLayoutItem{
+		name:   name,
+		volume: volume,
+		isSet:  true,
+	}, nil
+}
+
+func (i StorageLayoutItem) Name() string {
+	return i.name
+}
+
+func (i StorageLayoutItem) Volume() int {
+	return i.volume
+}
+
+func (i StorageLayoutItem) Equals(other StorageLayoutItem) bool {
+	return i.name == other.name && i.volume == other.volume
+}
+
+func (i StorageLayoutItem) IsEmpty() bool {
+	return !i.isSet
+}
+
+// Transport - вид транспорта из справочника: скорость и набор мест хранения по умолчанию
+type Transport struct {
+	id            uuid.UUID
+	name          string
+	speed         int
+	storageLayout []StorageLayoutItem
+
+	isSet bool
+}
+
+func NewTransport(id uuid.UUID, name string, speed int, storageLayout []StorageLayoutItem) (Transport, error) {
+	if id == uuid.Nil {
+		return Transport{}, errs.NewValueIsRequiredError("id")
+	}
+	if strings.TrimSpace(name) == "" {
+		return Transport{}, errs.NewValueIsRequiredError("name")
+	}
+	if speed < MinSpeed {
+		return Transport{}, errs.NewValueIsRequiredError("speed")
+	}
+	for _, item := range storageLayout {
+		if item.IsEmpty() {
+			return Transport{}, errs.NewValueIsRequiredError("storageLayout")
+		}
+	}
+
+	return Transport{
+		id:            id,
+		name:          name,
+		speed:         speed,
+		storageLayout: append(make([]StorageLayoutItem, 0, len(storageLayout)), storageLayout...),
+		isSet:         true,
+	}, nil
+}
+
+func (t Transport) ID() uuid.UUID {
+	return t.id
+}
+
+func (t Transport) Name() string {
+	return t.name
+}
+
+func (t Transport) Speed() int {
+	return t.speed
+}
+
+func (t Transport) StorageLayout() []StorageLayoutItem {
+	res := make([]StorageLayoutItem, len(t.storageLayout))
+	copy(res, t.storageLayout)
+	return res
+}
+
+func (t Transport) Equals(other Transport) bool {
+	if t.id != other.id || t.name != other.name || t.speed != other.speed ||
+		len(t.storageLayout) != len(other.storageLayout) {
+		return false
+	}
+	for i := range t.storageLayout {
+		if !t.storageLayout[i].Equals(other.storageLayout[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t Transport) IsEmpty() bool {
+	return !t.isSet
+}
@@ -0,0 +1,66 @@
+package courier
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NewTransport(t *testing.T) {
+	t.Run("given valid parameters when create transport then success", func(t *testing.T) {
+		id := uuid.New()
+		backpack, err := NewStorageLayoutItem("Backpack", 5)
+		assert.NoError(t, err)
+
+		transport, err := NewTransport(id, "Walk", 1, []StorageLayoutItem{backpack})
+
+		assert.NoError(t, err)
+		assert.Equal(t, id, transport.ID())
+		assert.Equal(t, "Walk", transport.Name())
+		assert.Equal(t, 1, transport.Speed())
+		assert.Equal(t, []StorageLayoutItem{backpack}, transport.StorageLayout())
+		assert.False(t, transport.IsEmpty())
+	})
+
+	t.Run("given invalid parameters when create transport then return error", func(t *testing.T) {
+		tests := map[string]struct {
+			id     uuid.UUID
+			name   string
+			speed  int
+			layout []StorageLayoutItem
+		}{
+			"empty_id":     {uuid.Nil, "Walk", 1, nil},
+			"blank_name":   {uuid.New(), "  ", 1, nil},
+			"zero_speed":   {uuid.New(), "Walk", 0, nil},
+			"empty_layout": {uuid.New(), "Walk", 1, []StorageLayoutItem{{}}},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := NewTransport(test.id, test.name, test.speed, test.layout)
+				assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+			})
+		}
+	})
+
+	t.Run("given invalid parameters when create storage layout item then return error", func(t *testing.T) {
+		_, err := NewStorageLayoutItem("", 5)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+
+		_, err = NewStorageLayoutItem("Bag", 0)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+
+	t.Run("given transports when compare then equal by all fields", func(t *testing.T) {
+		id := uuid.New()
+		bag, _ := NewStorageLayoutItem("Bag", 5)
+		trunk, _ := NewStorageLayoutItem("Trunk", 20)
+		first, _ := NewTransport(id, "Car", 3, []StorageLayoutItem{bag})
+		same, _ := NewTransport(id, "Car", 3, []StorageLayoutItem{bag})
+		other, _ := NewTransport(id, "Car", 3, []StorageLayoutItem{trunk})
+
+		assert.True(t, first.Equals(same))
+		assert.False(t, first.Equals(other))
+	})
+}
@@ -0,0 +1,88 @@
+package kernel
+
+import (
+	"delivery/internal/pkg/errs"
+	"strings"
+)
+
+// Address - адрес доставки. Улица обязательна, остальные части уточняют её:
+// одноимённые улицы в разных городах - разные адреса
+type Address struct {
+	country   string
+	city      string
+	street    string
+	house     string
+	apartment string
+
+	isSet bool
+}
+
+func NewAddress(country string, city string, street string, house string, apartment string) (Address, error) {
+	if strings.TrimSpace(street) == "" {
+		return Address{}, errs.NewValueIsRequiredError("street")
+	}
+
+	return Address{
+		country:   strings.TrimSpace(country),
+		city:      strings.TrimSpace(city),
+		street:    strings.TrimSpace(street),
+		house:     strings.TrimSpace(house),
+		apartment: strings.TrimSpace(apartment),
+		isSet:     true,
+	}, nil
+}
+
+// RestoreAddress восстанавливает адрес из хранилища, у заказов из старых версий адреса нет
+// DO NOT USE IN DOMAIN!
+func RestoreAddress(country string, city string, street string, house string, apartment string) Address {
+	if street == "" {
+		return Address{}
+	}
+	return Address{
+		country:   country,
+		city:      city,
+		street:    street,
+		house:     house,
+		apartment: apartment,
+		isSet:     true,
+	}
+}
+
+func (a Address) Country() string {
+	return a.country
+}
+
+func (a Address) City() string {
+	return a.city
+}
+
+func (a Address) Street() string {
+	return a.street
+}
+
+func (a Address) House() string {
+	return a.house
+}
+
+func (a Address) Apartment() string {
+	return a.apartment
+}
+
+func (a Address) Equals(other Address) bool {
+	return a == other
+}
+
+// String - адрес одной строкой от страны к квартире, пустые части пропускаются
+func (a Address) String() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{a.country, a.city, a.street, a.house, a.apartment} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (a Address) IsEmpty() bool {
+	return !a.isSet
+}
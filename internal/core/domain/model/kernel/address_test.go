@@ -0,0 +1,37 @@
+package kernel
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_givenValidParams_whenCreateAddress_thenSuccess(t *testing.T) {
+	address, err := NewAddress(" Россия ", "Москва", "Ленина", "1", "")
+
+	assert.NoError(t, err)
+	assert.False(t, address.IsEmpty())
+	assert.Equal(t, "Россия", address.Country())
+	assert.Equal(t, "Ленина", address.Street())
+	assert.Equal(t, "Россия, Москва, Ленина, 1", address.String())
+}
+
+func Test_givenEmptyStreet_whenCreateAddress_thenReturnError(t *testing.T) {
+	_, err := NewAddress("Россия", "Москва", " ", "1", "2")
+
+	assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+}
+
+func Test_givenSameStreetInDifferentCities_whenCompare_thenNotEqual(t *testing.T) {
+	moscow, _ := NewAddress("Россия", "Москва", "Ленина", "1", "")
+	kazan, _ := NewAddress("Россия", "Казань", "Ленина", "1", "")
+	moscowAgain, _ := NewAddress("Россия", "Москва", "Ленина", "1", "")
+
+	assert.False(t, moscow.Equals(kazan))
+	assert.True(t, moscow.Equals(moscowAgain))
+}
+
+func Test_givenStoredOrderWithoutStreet_whenRestoreAddress_thenAddressIsEmpty(t *testing.T) {
+	assert.True(t, RestoreAddress("", "", "", "", "").IsEmpty())
+	assert.False(t, RestoreAddress("", "", "Ленина", "", "").IsEmpty())
+}
@@ -0,0 +1,77 @@
+package kernel
+
+import (
+	"delivery/internal/pkg/errs"
+	"fmt"
+)
+
+// CityMap - карта города: границы и непроходимые клетки (реки, пешеходные зоны)
+type CityMap struct {
+	grid    Grid
+	blocked map[Location]struct{}
+
+	isSet bool
+}
+
+func NewCityMap(grid Grid, blocked []Location) (CityMap, error) {
+	if grid.IsEmpty() {
+		return CityMap{}, errs.NewValueIsRequiredError("grid")
+	}
+
+	blockedSet := make(map[Location]struct{}, len(blocked))
+	for _, cell := range blocked {
+		if cell.IsEmpty() {
+			return CityMap{}, errs.NewValueIsRequiredError("blocked")
+		}
+		if !grid.Contains(cell.X(), cell.Y()) {
+			return CityMap{}, errs.NewValueIsOutOfRangeError("blocked", fmt.Sprintf("(%d, %d)", cell.X(), cell.Y()),
+				fmt.Sprintf("(%d, %d)", minCoordinate, minCoordinate), fmt.Sprintf("(%d, %d)", grid.Width(), grid.Height()))
+		}
+		blockedSet[cell] = struct{}{}
+	}
+
+	return CityMap{
+		grid:    grid,
+		blocked: blockedSet,
+		isSet:   true,
+	}, nil
+}
+
+func (m CityMap) Grid() Grid {
+	return m.grid
+}
+
+// IsPassable - можно ли пройти через клетку
+func (m CityMap) IsPassable(location Location) bool {
+	if location.IsEmpty() || !m.grid.Contains(location.x, location.y) {
+		return false
+	}
+	_, isBlocked := m.blocked[location]
+	return !isBlocked
+}
+
+func (m CityMap) HasObstacles() bool {
+	return len(m.blocked) > 0
+}
+
+// Neighbours возвращает проходимые клетки по горизонтали и вертикали от заданной
+func (m CityMap) Neighbours(location Location) []Location {
+	candidates := [4]Location{
+		{location.x + 1, location.y, true},
+		{location.x - 1, location.y, true},
+		{location.x, location.y + 1, true},
+		{location.x, location.y - 1, true},
+	}
+
+	neighbours := make([]Location, 0, len(candidates))
+	for _, candidate := range candidates {
+		if m.IsPassable(candidate) {
+			neighbours = append(neighbours, candidate)
+		}
+	}
+	return neighbours
+}
+
+func (m CityMap) IsEmpty() bool {
+	return !m.isSet
+}
@@ -0,0 +1,39 @@
+package kernel
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_givenBlockedCells_whenCreateCityMap_thenCellsAreImpassable(t *testing.T) {
+	blocked := RestoreLocation(2, 2)
+
+	cityMap, err := NewCityMap(DefaultGrid(), []Location{blocked})
+
+	assert.NoError(t, err)
+	assert.True(t, cityMap.HasObstacles())
+	assert.False(t, cityMap.IsPassable(blocked))
+	assert.True(t, cityMap.IsPassable(RestoreLocation(1, 1)))
+	assert.False(t, cityMap.IsPassable(RestoreLocation(11, 1)))
+}
+
+func Test_givenCellNearBorderAndObstacle_whenGetNeighbours_thenReturnOnlyPassable(t *testing.T) {
+	cityMap, err := NewCityMap(DefaultGrid(), []Location{RestoreLocation(2, 1)})
+	assert.NoError(t, err)
+
+	neighbours := cityMap.Neighbours(RestoreLocation(1, 1))
+
+	assert.Equal(t, []Location{RestoreLocation(1, 2)}, neighbours)
+}
+
+func Test_givenInvalidParams_whenCreateCityMap_thenReturnError(t *testing.T) {
+	_, err := NewCityMap(Grid{}, nil)
+	assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+
+	_, err = NewCityMap(DefaultGrid(), []Location{{}})
+	assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+
+	_, err = NewCityMap(DefaultGrid(), []Location{RestoreLocation(11, 1)})
+	assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+}
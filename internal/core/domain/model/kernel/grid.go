@@ -0,0 +1,77 @@
+package kernel
+
+import (
+	"delivery/internal/pkg/errs"
+	"math/rand"
+)
+
+const (
+	minCoordinate = 1
+
+	// MaxGridSide - предельный размер стороны карты, координаты хранятся в integer-колонках
+	MaxGridSide = 1_000_000
+
+	defaultGridWidth  = 10
+	defaultGridHeight = 10
+)
+
+// Grid - границы карты города: X от 1 до width, Y от 1 до height.
+// Карта задаётся конфигурацией и передаётся в конструкторы и сервисы, которым нужны границы
+type Grid struct {
+	width  int
+	height int
+
+	isSet bool
+}
+
+func NewGrid(width int, height int) (Grid, error) {
+	if width < minCoordinate || width > MaxGridSide {
+		return Grid{}, errs.NewValueIsOutOfRangeError("width", width, minCoordinate, MaxGridSide)
+	}
+	if height < minCoordinate || height > MaxGridSide {
+		return Grid{}, errs.NewValueIsOutOfRangeError("height", height, minCoordinate, MaxGridSide)
+	}
+
+	return Grid{
+		width:  width,
+		height: height,
+		isSet:  true,
+	}, nil
+}
+
+// DefaultGrid - доска 10x10, с которой сервис работал изначально
+func DefaultGrid() Grid {
+	return Grid{
+		width:  defaultGridWidth,
+		height: defaultGridHeight,
+		isSet:  true,
+	}
+}
+
+func (g Grid) Width() int {
+	return g.width
+}
+
+func (g Grid) Height() int {
+	return g.height
+}
+
+func (g Grid) Contains(x int, y int) bool {
+	return x >= minCoordinate && x <= g.width && y >= minCoordinate && y <= g.height
+}
+
+func (g Grid) RandomLocation() Location {
+	return Location{
+		x:     rand.Intn(g.width) + minCoordinate,
+		y:     rand.Intn(g.height) + minCoordinate,
+		isSet: true,
+	}
+}
+
+func (g Grid) Equals(other Grid) bool {
+	return g == other
+}
+
+func (g Grid) IsEmpty() bool {
+	return !g.isSet
+}
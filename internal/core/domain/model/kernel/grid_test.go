@@ -0,0 +1,58 @@
+package kernel
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_givenInvalidParams_whenCreateGrid_thenReturnError(t *testing.T) {
+	tests := map[string]struct {
+		width  int
+		height int
+	}{
+		"zero_width":      {0, 10},
+		"zero_height":     {10, 0},
+		"too_wide":        {MaxGridSide + 1, 10},
+		"negative_height": {10, -1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewGrid(test.width, test.height)
+			assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+		})
+	}
+}
+
+func Test_givenLargeGrid_whenCreateLocations_thenValidateAgainstGridAndCountDistance(t *testing.T) {
+	grid, err := NewGrid(10_000, 5_000)
+	assert.NoError(t, err)
+
+	far, err := NewLocation(grid, 10_000, 5_000)
+	assert.NoError(t, err)
+	near, err := NewLocation(grid, 1, 1)
+	assert.NoError(t, err)
+
+	distance, err := near.CountDistanceTo(far)
+	assert.NoError(t, err)
+	assert.Equal(t, 14_998, distance)
+
+	_, err = NewLocation(grid, 10_001, 1)
+	assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+	_, err = NewLocation(grid, 1, 5_001)
+	assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+
+	_, err = NewLocation(DefaultGrid(), 11, 1)
+	assert.ErrorIs(t, err, errs.ErrValueIsOutOfRange)
+}
+
+func Test_givenGrid_whenCreateRandomLocation_thenLocationIsInsideGrid(t *testing.T) {
+	grid, err := NewGrid(3, 2)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		location := grid.RandomLocation()
+		assert.True(t, grid.Contains(location.X(), location.Y()))
+	}
+}
@@ -2,48 +2,35 @@ package kernel
 
 import (
 	"delivery/internal/pkg/errs"
-	"math"
-	"math/rand"
 )
 
-const (
-	minX uint8 = 1
-	maxX uint8 = 10
-	minY uint8 = 1
-	maxY uint8 = 10
-)
-
-// Location - Координата на доске, она состоит из X (горизонталь) и Y (вертикаль)
+// Location - Координата на карте города, она состоит из X (горизонталь) и Y (вертикаль)
 type Location struct {
-	x     uint8
-	y     uint8
+	x     int
+	y     int
 	isSet bool
 }
 
-func NewLocation(x uint8, y uint8) (Location, error) {
-	if !isXWithinAllowedRange(x) {
-		return Location{}, errs.NewValueIsOutOfRangeError("x", x, minX, maxX)
+// NewLocation создаёт координату в границах карты города
+func NewLocation(grid Grid, x int, y int) (Location, error) {
+	if grid.IsEmpty() {
+		return Location{}, errs.NewValueIsRequiredError("grid")
+	}
+	if x < minCoordinate || x > grid.Width() {
+		return Location{}, errs.NewValueIsOutOfRangeError("x", x, minCoordinate, grid.Width())
 	}
-	if !isYWithinAllowedRange(y) {
-		return Location{}, errs.NewValueIsOutOfRangeError("y", y, minY, maxY)
+	if y < minCoordinate || y > grid.Height() {
+		return Location{}, errs.NewValueIsOutOfRangeError("y", y, minCoordinate, grid.Height())
 	}
 
 	return Location{x, y, true}, nil
 }
 
-func isXWithinAllowedRange(x uint8) bool {
-	return x >= minX && x <= maxX
-}
-
-func isYWithinAllowedRange(y uint8) bool {
-	return y >= minY && y <= maxY
-}
-
-func (l Location) X() uint8 {
+func (l Location) X() int {
 	return l.x
 }
 
-func (l Location) Y() uint8 {
+func (l Location) Y() int {
 	return l.y
 }
 
@@ -51,25 +38,28 @@ func (l Location) Equals(x Location) bool {
 	return l == x
 }
 
-func (l Location) CountDistanceTo(target Location) (uint8, error) {
-
+// CountDistanceTo считает манхэттенское расстояние между координатами
+func (l Location) CountDistanceTo(target Location) (int, error) {
 	if target.IsEmpty() {
 		return 0, errs.NewValueIsRequiredError("target")
 	}
 
-	dx := math.Abs(float64(int(l.x) - int(target.x)))
-	dy := math.Abs(float64(int(l.y) - int(target.y)))
-	return uint8(dx + dy), nil
+	return abs(l.x-target.x) + abs(l.y-target.y), nil
 }
 
 func (l Location) IsEmpty() bool {
 	return !l.isSet
 }
 
-func CreateRandomLocation() Location {
-	return Location{
-		x:     uint8(rand.Intn(10) + 1),
-		y:     uint8(rand.Intn(10) + 1),
-		isSet: true,
+func abs(v int) int {
+	if v < 0 {
+		return -v
 	}
+	return v
+}
+
+// RestoreLocation restore Location from db. DO NOT USE IN DOMAIN!
+// Границы карты не проверяются: сохранённые координаты остаются валидными, даже если карту уменьшили
+func RestoreLocation(x int, y int) Location {
+	return Location{x, y, true}
 }
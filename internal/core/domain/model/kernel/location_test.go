@@ -9,8 +9,8 @@ import (
 func Test_givenInvalidParams_whenCreateLocation_thenReturnError(t *testing.T) {
 	// Arrange
 	tests := map[string]struct {
-		x        uint8
-		y        uint8
+		x        int
+		y        int
 		expected error
 	}{
 		"x_is_zero":  {0, 9, errs.NewValueIsOutOfRangeError("x", 0, 1, 10)},
@@ -21,17 +21,17 @@ func Test_givenInvalidParams_whenCreateLocation_thenReturnError(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			_, err := NewLocation(test.x, test.y)
+			_, err := NewLocation(DefaultGrid(), test.x, test.y)
 			assert.Errorf(t, err, test.expected.Error())
 		})
 	}
 }
 
 func Test_givenValidParams_whenCreateLocation_thenSuccess(t *testing.T) {
-	var x uint8 = 5
-	var y uint8 = 6
+	x := 5
+	y := 6
 
-	res, err := NewLocation(x, y)
+	res, err := NewLocation(DefaultGrid(), x, y)
 
 	assert.NoError(t, err)
 	assert.Equal(t, x, res.X())
@@ -40,29 +40,29 @@ func Test_givenValidParams_whenCreateLocation_thenSuccess(t *testing.T) {
 }
 
 func Test_givenTwoEqualsLocation_whenCompareThem_thenReturnTrue(t *testing.T) {
-	first, _ := NewLocation(4, 5)
-	second, _ := NewLocation(4, 5)
+	first, _ := NewLocation(DefaultGrid(), 4, 5)
+	second, _ := NewLocation(DefaultGrid(), 4, 5)
 
 	assert.True(t, first.Equals(second))
 }
 
 func Test_givenTwoNotEqualsLocation_whenCompareThem_thenReturnFalse(t *testing.T) {
-	first, _ := NewLocation(4, 5)
-	second, _ := NewLocation(4, 6)
+	first, _ := NewLocation(DefaultGrid(), 4, 5)
+	second, _ := NewLocation(DefaultGrid(), 4, 6)
 
 	assert.False(t, first.Equals(second))
 }
 
 func Test_givenTwoValidLocations_thenCountDistanceTo_thenReturnCorrectDistance(t *testing.T) {
-	first, _ := NewLocation(2, 6)
-	second, _ := NewLocation(4, 9)
-	third, _ := NewLocation(4, 9)
-	thour, _ := NewLocation(10, 5)
+	first, _ := NewLocation(DefaultGrid(), 2, 6)
+	second, _ := NewLocation(DefaultGrid(), 4, 9)
+	third, _ := NewLocation(DefaultGrid(), 4, 9)
+	thour, _ := NewLocation(DefaultGrid(), 10, 5)
 
 	tests := map[string]struct {
 		first    Location
 		second   Location
-		expected uint8
+		expected int
 	}{
 		"less_to_high":  {first, second, 5},
 		"high_to_less":  {second, first, 5},
@@ -81,7 +81,7 @@ func Test_givenTwoValidLocations_thenCountDistanceTo_thenReturnCorrectDistance(t
 
 func Test_givenEmptyLocation_whenCountDistance_thenReturnError(t *testing.T) {
 	emptyLocation := Location{}
-	validLocation, _ := NewLocation(5, 4)
+	validLocation, _ := NewLocation(DefaultGrid(), 5, 4)
 	expected := errs.NewValueIsRequiredError("target")
 
 	_, err := validLocation.CountDistanceTo(emptyLocation)
@@ -89,8 +89,8 @@ func Test_givenEmptyLocation_whenCountDistance_thenReturnError(t *testing.T) {
 	assert.Errorf(t, err, expected.Error())
 }
 
-func Test_whenCreateRandomLocation_thenSuccess(t *testing.T) {
-	assert.NotPanics(t, func() {
-		CreateRandomLocation()
-	}, "expected not panic when creating random location")
+func Test_givenEmptyGrid_whenCreateLocation_thenReturnError(t *testing.T) {
+	_, err := NewLocation(Grid{}, 5, 5)
+
+	assert.ErrorIs(t, err, errs.ErrValueIsRequired)
 }
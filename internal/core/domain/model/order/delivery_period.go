@@ -0,0 +1,52 @@
+package order
+
+import (
+	"delivery/internal/pkg/errs"
+	"time"
+)
+
+// DeliveryPeriod - Окно доставки заказа [from, to]
+type DeliveryPeriod struct {
+	from  time.Time
+	to    time.Time
+	isSet bool
+}
+
+func NewDeliveryPeriod(from time.Time, to time.Time) (DeliveryPeriod, error) {
+	if from.IsZero() {
+		return DeliveryPeriod{}, errs.NewValueIsRequiredError("from")
+	}
+	if to.IsZero() {
+		return DeliveryPeriod{}, errs.NewValueIsRequiredError("to")
+	}
+	if !to.After(from) {
+		return DeliveryPeriod{}, errs.NewValueIsOutOfRangeError("to", to, from, nil)
+	}
+
+	return DeliveryPeriod{
+		from:  from.UTC(),
+		to:    to.UTC(),
+		isSet: true,
+	}, nil
+}
+
+func (p DeliveryPeriod) From() time.Time {
+	return p.from
+}
+
+func (p DeliveryPeriod) To() time.Time {
+	return p.to
+}
+
+// IsClosedAt - окно доставки уже закрылось к моменту moment
+func (p DeliveryPeriod) IsClosedAt(moment time.Time) bool {
+	return p.isSet && moment.After(p.to)
+}
+
+func (p DeliveryPeriod) Equals(other DeliveryPeriod) bool {
+	return p.isSet == other.isSet && p.from.Equal(other.from) && p.to.Equal(other.to)
+}
+
+func (p DeliveryPeriod) IsEmpty() bool {
+	return !p.isSet
+}
@@ -0,0 +1,86 @@
+package order
+
+import (
+	"delivery/internal/pkg/errs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_createDeliveryPeriod(t *testing.T) {
+	from := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	to := from.Add(4 * time.Hour)
+
+	t.Run("given valid bounds when NewDeliveryPeriod then success", func(t *testing.T) {
+		period, err := NewDeliveryPeriod(from, to)
+
+		assert.NoError(t, err)
+		assert.Equal(t, from, period.From())
+		assert.Equal(t, to, period.To())
+		assert.False(t, period.IsEmpty())
+	})
+
+	t.Run("given invalid bounds when NewDeliveryPeriod then return error", func(t *testing.T) {
+		tests := map[string]struct {
+			from     time.Time
+			to       time.Time
+			expected error
+		}{
+			"empty_from":    {time.Time{}, to, errs.ErrValueIsRequired},
+			"empty_to":      {from, time.Time{}, errs.ErrValueIsRequired},
+			"to_before":     {to, from, errs.ErrValueIsOutOfRange},
+			"to_equal_from": {from, from, errs.ErrValueIsOutOfRange},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := NewDeliveryPeriod(test.from, test.to)
+				assert.ErrorIs(t, err, test.expected)
+			})
+		}
+	})
+
+	t.Run("given period when IsClosedAt then compare with upper bound", func(t *testing.T) {
+		period, _ := NewDeliveryPeriod(from, to)
+
+		assert.False(t, period.IsClosedAt(from.Add(time.Hour)))
+		assert.False(t, period.IsClosedAt(to))
+		assert.True(t, period.IsClosedAt(to.Add(time.Second)))
+		assert.False(t, DeliveryPeriod{}.IsClosedAt(to.Add(time.Hour)))
+	})
+}
+
+func Test_orderIsLate(t *testing.T) {
+	from := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	to := from.Add(4 * time.Hour)
+	period, _ := NewDeliveryPeriod(from, to)
+	afterWindow := to.Add(time.Minute)
+
+	t.Run("given order without delivery period then never late", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		assert.False(t, order.IsLate(afterWindow))
+	})
+
+	t.Run("given not delivered order after window then late", func(t *testing.T) {
+		order, err := NewOrderWithDeliveryPeriod(uuid.New(), createTestAddress(t), createTestLocation(t), 5, period)
+		assert.NoError(t, err)
+
+		assert.False(t, order.IsLate(from))
+		assert.True(t, order.IsLate(afterWindow))
+	})
+
+	t.Run("given completed order after window then not late", func(t *testing.T) {
+		order, _ := NewOrderWithDeliveryPeriod(uuid.New(), createTestAddress(t), createTestLocation(t), 5, period)
+		_ = order.Assign(uuid.New())
+		_ = order.Complete()
+
+		assert.False(t, order.IsLate(afterWindow))
+	})
+
+	t.Run("given empty period when NewOrderWithDeliveryPeriod then return error", func(t *testing.T) {
+		_, err := NewOrderWithDeliveryPeriod(uuid.New(), createTestAddress(t), createTestLocation(t), 5, DeliveryPeriod{})
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
@@ -6,27 +6,38 @@ import (
 	"delivery/internal/pkg/errs"
 	"errors"
 	"github.com/google/uuid"
+	"strings"
+	"time"
 )
 
 var (
-	ErrOrderHasAlreadyBeenAssigned = errors.New("order has already been assigned")
-	ErrOrderHasNotBeenAssigned     = errors.New("order has not been assigned")
+	ErrOrderHasAlreadyBeenAssigned  = errors.New("order has already been assigned")
+	ErrOrderHasNotBeenAssigned      = errors.New("order has not been assigned")
+	ErrOrderHasAlreadyBeenCompleted = errors.New("order has already been completed")
+	ErrOrderHasAlreadyBeenCancelled = errors.New("order has already been cancelled")
 )
 
 type Order struct {
-	id        uuid.UUID
-	courierID *uuid.UUID
-	location  kernel.Location
-	volume    int
-	status    Status
+	id             uuid.UUID
+	courierID      *uuid.UUID
+	address        kernel.Address
+	location       kernel.Location
+	volume         int
+	status         Status
+	cancelReason   string
+	deliveryPeriod DeliveryPeriod
 
 	*ddd.BaseAggregate
 }
 
-func NewOrder(orderID uuid.UUID, location kernel.Location, volume int) (*Order, error) {
+// NewOrder создаёт заказ по адресу доставки, location - координаты этого адреса на карте города
+func NewOrder(orderID uuid.UUID, address kernel.Address, location kernel.Location, volume int) (*Order, error) {
 	if orderID == uuid.Nil {
 		return nil, errs.NewValueIsRequiredError("orderID")
 	}
+	if address.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("address")
+	}
 	if location.IsEmpty() {
 		return nil, errs.NewValueIsRequiredError("location")
 	}
@@ -36,6 +47,7 @@ func NewOrder(orderID uuid.UUID, location kernel.Location, volume int) (*Order,
 
 	return &Order{
 		id:            orderID,
+		address:       address,
 		location:      location,
 		volume:        volume,
 		status:        StatusCreated,
@@ -43,6 +55,21 @@ func NewOrder(orderID uuid.UUID, location kernel.Location, volume int) (*Order,
 	}, nil
 }
 
+// NewOrderWithDeliveryPeriod создаёт заказ, который должен быть доставлен в заданное окно
+func NewOrderWithDeliveryPeriod(orderID uuid.UUID, address kernel.Address, location kernel.Location, volume int,
+	deliveryPeriod DeliveryPeriod) (*Order, error) {
+	if deliveryPeriod.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("deliveryPeriod")
+	}
+
+	order, err := NewOrder(orderID, address, location, volume)
+	if err != nil {
+		return nil, err
+	}
+	order.deliveryPeriod = deliveryPeriod
+	return order, nil
+}
+
 func (o *Order) Assign(courierID uuid.UUID) error {
 	if courierID == uuid.Nil {
 		return errs.NewValueIsRequiredError("courierID")
@@ -54,6 +81,8 @@ func (o *Order) Assign(courierID uuid.UUID) error {
 
 	o.courierID = &courierID
 	o.status = StatusAssigned
+
+	o.RaiseDomainEvent(NewStatusChangedDomainEvent(o))
 	return nil
 }
 
@@ -63,9 +92,51 @@ func (o *Order) Complete() error {
 	}
 
 	o.status = StatusCompleted
+
+	o.RaiseDomainEvent(NewStatusChangedDomainEvent(o))
 	return nil
 }
 
+// Unassign возвращает назначенный заказ в распределение, например если курьер не может до него добраться
+func (o *Order) Unassign() error {
+	if !o.isAssigned() {
+		return ErrOrderHasNotBeenAssigned
+	}
+
+	o.courierID = nil
+	o.status = StatusCreated
+
+	o.RaiseDomainEvent(NewStatusChangedDomainEvent(o))
+	return nil
+}
+
+func (o *Order) Cancel(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errs.NewValueIsRequiredError("reason")
+	}
+
+	switch o.status {
+	case StatusCompleted:
+		return ErrOrderHasAlreadyBeenCompleted
+	case StatusCancelled:
+		return ErrOrderHasAlreadyBeenCancelled
+	}
+
+	o.status = StatusCancelled
+	o.cancelReason = reason
+
+	o.RaiseDomainEvent(NewStatusChangedDomainEvent(o))
+	return nil
+}
+
+// IsLate - окно доставки закрылось, а заказ всё ещё не доставлен
+func (o *Order) IsLate(now time.Time) bool {
+	if o.status == StatusCompleted || o.status == StatusCancelled {
+		return false
+	}
+	return o.deliveryPeriod.IsClosedAt(now)
+}
+
 func (o *Order) isAssigned() bool {
 	return o.courierID != nil && o.status == StatusAssigned
 }
@@ -78,6 +149,11 @@ func (o *Order) CourierID() *uuid.UUID {
 	return o.courierID
 }
 
+// Address - адрес доставки, у заказов, созданных до появления адресов, он пустой
+func (o *Order) Address() kernel.Address {
+	return o.address
+}
+
 func (o *Order) Location() kernel.Location {
 	return o.location
 }
@@ -90,6 +166,14 @@ func (o *Order) Status() Status {
 	return o.status
 }
 
+func (o *Order) CancelReason() string {
+	return o.cancelReason
+}
+
+func (o *Order) DeliveryPeriod() DeliveryPeriod {
+	return o.deliveryPeriod
+}
+
 func (o *Order) Equals(other *Order) bool {
 	if other == nil {
 		return false
@@ -102,16 +186,23 @@ func (o *Order) Equals(other *Order) bool {
 func RestoreOrder(
 	id uuid.UUID,
 	courierID *uuid.UUID,
+	address kernel.Address,
 	location kernel.Location,
 	volume int,
 	status Status,
+	cancelReason string,
+	deliveryPeriod DeliveryPeriod,
+	version int64,
 ) *Order {
 	return &Order{
-		id:            id,
-		courierID:     courierID,
-		location:      location,
-		volume:        volume,
-		status:        status,
-		BaseAggregate: ddd.NewBaseAggregate(),
+		id:             id,
+		courierID:      courierID,
+		address:        address,
+		location:       location,
+		volume:         volume,
+		status:         status,
+		cancelReason:   cancelReason,
+		deliveryPeriod: deliveryPeriod,
+		BaseAggregate:  ddd.NewBaseAggregateWithVersion(version),
 	}
 }
@@ -12,12 +12,14 @@ func Test_createOrder(t *testing.T) {
 	t.Run("given valid parameters when NewOrder then success", func(t *testing.T) {
 		orderID := uuid.New()
 		location := createTestLocation(t)
+		address := createTestAddress(t)
 		volume := 5
 
-		order, err := NewOrder(orderID, location, volume)
+		order, err := NewOrder(orderID, address, location, volume)
 
 		assert.NoError(t, err)
 		assert.Equal(t, orderID, order.ID())
+		assert.Equal(t, address, order.Address())
 		assert.Equal(t, location, order.Location())
 		assert.Equal(t, volume, order.Volume())
 		assert.Equal(t, StatusCreated, order.Status())
@@ -28,24 +30,27 @@ func Test_createOrder(t *testing.T) {
 func Test_givenInvalidParams_whenCreateNewOrder_thenFail(t *testing.T) {
 	t.Run("given invalid parameters when NewOrder then return error", func(t *testing.T) {
 		validID := uuid.New()
+		validAddress := createTestAddress(t)
 		validLocation := createTestLocation(t)
 		validVolume := 5
 
 		tests := map[string]struct {
 			id       uuid.UUID
+			address  kernel.Address
 			location kernel.Location
 			volume   int
 			expected error
 		}{
-			"nil_order_id":    {uuid.Nil, validLocation, validVolume, errs.NewValueIsRequiredError("orderID")},
-			"empty_location":  {validID, kernel.Location{}, validVolume, errs.NewValueIsRequiredError("location")},
-			"zero_volume":     {validID, validLocation, 0, errs.NewValueIsRequiredError("volume")},
-			"negative_volume": {validID, validLocation, -1, errs.NewValueIsRequiredError("volume")},
+			"nil_order_id":    {uuid.Nil, validAddress, validLocation, validVolume, errs.NewValueIsRequiredError("orderID")},
+			"empty_address":   {validID, kernel.Address{}, validLocation, validVolume, errs.NewValueIsRequiredError("address")},
+			"empty_location":  {validID, validAddress, kernel.Location{}, validVolume, errs.NewValueIsRequiredError("location")},
+			"zero_volume":     {validID, validAddress, validLocation, 0, errs.NewValueIsRequiredError("volume")},
+			"negative_volume": {validID, validAddress, validLocation, -1, errs.NewValueIsRequiredError("volume")},
 		}
 
 		for name, test := range tests {
 			t.Run(name, func(t *testing.T) {
-				_, err := NewOrder(test.id, test.location, test.volume)
+				_, err := NewOrder(test.id, test.address, test.location, test.volume)
 				assert.Error(t, err)
 				assert.Errorf(t, err, test.expected.Error())
 			})
@@ -113,6 +118,141 @@ func Test_completeOrder(t *testing.T) {
 	})
 }
 
+func Test_unassignOrder(t *testing.T) {
+	t.Run("given assigned order when Unassign then order is created again", func(t *testing.T) {
+		order := createTestOrder(t)
+		_ = order.Assign(uuid.New())
+		order.ClearDomainEvents()
+
+		err := order.Unassign()
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCreated, order.Status())
+		assert.Nil(t, order.CourierID())
+		assert.Len(t, order.GetDomainEvents(), 1)
+	})
+
+	t.Run("given unassigned order when Unassign then return error", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		err := order.Unassign()
+		assert.ErrorIs(t, err, ErrOrderHasNotBeenAssigned)
+	})
+}
+
+func Test_cancelOrder(t *testing.T) {
+	t.Run("given created order when Cancel then success", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		err := order.Cancel("customer changed mind")
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCancelled, order.Status())
+		assert.Equal(t, "customer changed mind", order.CancelReason())
+	})
+
+	t.Run("given assigned order when Cancel then success", func(t *testing.T) {
+		order := createTestOrder(t)
+		courierID := uuid.New()
+		_ = order.Assign(courierID)
+
+		err := order.Cancel("basket cancelled")
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCancelled, order.Status())
+		assert.Equal(t, courierID, *order.CourierID())
+	})
+
+	t.Run("given empty reason when Cancel then return error", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		err := order.Cancel("  ")
+
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+		assert.Equal(t, StatusCreated, order.Status())
+	})
+
+	t.Run("given completed order when Cancel then return error", func(t *testing.T) {
+		order := createTestOrder(t)
+		_ = order.Assign(uuid.New())
+		_ = order.Complete()
+
+		err := order.Cancel("too late")
+
+		assert.ErrorIs(t, err, ErrOrderHasAlreadyBeenCompleted)
+		assert.Equal(t, StatusCompleted, order.Status())
+	})
+
+	t.Run("given cancelled order when Cancel then return error", func(t *testing.T) {
+		order := createTestOrder(t)
+		_ = order.Cancel("first")
+
+		err := order.Cancel("second")
+
+		assert.ErrorIs(t, err, ErrOrderHasAlreadyBeenCancelled)
+		assert.Equal(t, "first", order.CancelReason())
+	})
+
+	t.Run("given cancelled order when Assign or Complete then return error", func(t *testing.T) {
+		order := createTestOrder(t)
+		_ = order.Cancel("reason")
+
+		assert.ErrorIs(t, order.Assign(uuid.New()), ErrOrderHasAlreadyBeenAssigned)
+		assert.ErrorIs(t, order.Complete(), ErrOrderHasNotBeenAssigned)
+	})
+
+	t.Run("given order when Cancel then raise status changed event", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		_ = order.Cancel("reason")
+
+		events := order.GetDomainEvents()
+		assert.Len(t, events, 1)
+		assert.Equal(t, StatusCancelled, events[0].(StatusChangedDomainEvent).OrderStatus)
+	})
+}
+
+func Test_statusChangedDomainEvent(t *testing.T) {
+	t.Run("given new order when created then no domain events raised", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		assert.Empty(t, order.GetDomainEvents())
+	})
+
+	t.Run("given order when Assign then raise status changed event", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		_ = order.Assign(uuid.New())
+
+		events := order.GetDomainEvents()
+		assert.Len(t, events, 1)
+		event, ok := events[0].(StatusChangedDomainEvent)
+		assert.True(t, ok)
+		assert.NotEqual(t, uuid.Nil, event.GetID())
+		assert.Equal(t, StatusChangedDomainEventName, event.GetName())
+		assert.Equal(t, order.ID(), event.OrderID)
+		assert.Equal(t, StatusAssigned, event.OrderStatus)
+	})
+
+	t.Run("given assigned order when Complete then raise status changed event", func(t *testing.T) {
+		order := createTestOrder(t)
+		_ = order.Assign(uuid.New())
+
+		_ = order.Complete()
+
+		events := order.GetDomainEvents()
+		assert.Len(t, events, 2)
+		assert.Equal(t, StatusCompleted, events[1].(StatusChangedDomainEvent).OrderStatus)
+	})
+
+	t.Run("given failed transition then no domain events raised", func(t *testing.T) {
+		order := createTestOrder(t)
+
+		_ = order.Complete()
+
+		assert.Empty(t, order.GetDomainEvents())
+	})
+}
+
 func Test_equals(t *testing.T) {
 	t.Run("given two orders when Equals then return correct result", func(t *testing.T) {
 		order1 := createTestOrder(t)
@@ -147,13 +287,19 @@ func Test_equals(t *testing.T) {
 
 func createTestOrder(t *testing.T) *Order {
 	location := createTestLocation(t)
-	order, err := NewOrder(uuid.New(), location, 5)
+	order, err := NewOrder(uuid.New(), createTestAddress(t), location, 5)
 	assert.NoError(t, err)
 	return order
 }
 
+func createTestAddress(t *testing.T) kernel.Address {
+	address, err := kernel.NewAddress("Россия", "Москва", "Тестовая", "1", "10")
+	assert.NoError(t, err)
+	return address
+}
+
 func createTestLocation(t *testing.T) kernel.Location {
-	location, err := kernel.NewLocation(7, 10)
+	location, err := kernel.NewLocation(kernel.DefaultGrid(), 7, 10)
 	assert.NoError(t, err)
 	return location
 }
@@ -0,0 +1,39 @@
+package order
+
+import (
+	"delivery/internal/pkg/ddd"
+	"github.com/google/uuid"
+)
+
+const StatusChangedDomainEventName = "order.status.changed"
+
+var _ ddd.DomainEvent = StatusChangedDomainEvent{}
+
+// StatusChangedDomainEvent - Статус заказа изменился
+type StatusChangedDomainEvent struct {
+	// base
+	ID   uuid.UUID
+	Name string
+
+	// payload
+	OrderID     uuid.UUID
+	OrderStatus Status
+}
+
+func NewStatusChangedDomainEvent(aggregate *Order) StatusChangedDomainEvent {
+	return StatusChangedDomainEvent{
+		ID:   uuid.New(),
+		Name: StatusChangedDomainEventName,
+
+		OrderID:     aggregate.ID(),
+		OrderStatus: aggregate.Status(),
+	}
+}
+
+func (e StatusChangedDomainEvent) GetID() uuid.UUID {
+	return e.ID
+}
+
+func (e StatusChangedDomainEvent) GetName() string {
+	return e.Name
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"slices"
+	"time"
+)
+
+// infeasibleCost - стоимость недопустимой пары заказ-курьер. Она заведомо больше суммы любых
+// реальных времён, поэтому алгоритм сначала максимизирует число назначений, а затем минимизирует время
+const infeasibleCost = 1e9
+
+// Assignment - назначение заказа курьеру
+type Assignment struct {
+	Order   *order.Order
+	Courier *courier.Courier
+}
+
+// BatchDispatcher распределяет сразу пачку заказов между курьерами
+type BatchDispatcher interface {
+	Dispatch(orders []*order.Order, couriers []*courier.Courier) ([]Assignment, error)
+}
+
+var _ BatchDispatcher = &batchDispatcher{}
+
+// batchDispatcher находит назначение с минимальным суммарным временем доставки (венгерский алгоритм)
+type batchDispatcher struct {
+	tickDuration time.Duration
+	now          func() time.Time
+	navigator    courier.Navigator
+}
+
+func NewBatchDispatcher() BatchDispatcher {
+	return &batchDispatcher{
+		tickDuration: DefaultTickDuration,
+		now:          time.Now,
+		navigator:    newOpenPathfinder(),
+	}
+}
+
+// NewBatchDispatcherWithNavigator создаёт диспетчер, который оценивает время по реальному пути в объезд препятствий
+func NewBatchDispatcherWithNavigator(navigator courier.Navigator) (BatchDispatcher, error) {
+	if navigator == nil {
+		return nil, errs.NewValueIsRequiredError("navigator")
+	}
+
+	return &batchDispatcher{
+		tickDuration: DefaultTickDuration,
+		now:          time.Now,
+		navigator:    navigator,
+	}, nil
+}
+
+// NewBatchDispatcherWithClock создаёт диспетчер с заданной длительностью тика и источником текущего времени
+func NewBatchDispatcherWithClock(tickDuration time.Duration, now func() time.Time) (BatchDispatcher, error) {
+	if tickDuration <= 0 {
+		return nil, errs.NewValueIsRequiredError("tickDuration")
+	}
+	if now == nil {
+		return nil, errs.NewValueIsRequiredError("now")
+	}
+
+	return &batchDispatcher{
+		tickDuration: tickDuration,
+		now:          now,
+		navigator:    newOpenPathfinder(),
+	}, nil
+}
+
+func (p *batchDispatcher) Dispatch(orders []*order.Order, couriers []*courier.Courier) ([]Assignment, error) {
+	if len(orders) == 0 {
+		return nil, errs.NewValueIsRequiredError("orders")
+	}
+	if len(couriers) == 0 {
+		return nil, errs.NewValueIsRequiredError("couriers")
+	}
+
+	createdOrders := make([]*order.Order, 0, len(orders))
+	for _, o := range orders {
+		if o != nil && o.Status() == order.StatusCreated {
+			createdOrders = append(createdOrders, o)
+		}
+	}
+	if len(createdOrders) == 0 {
+		return nil, order.ErrOrderHasAlreadyBeenAssigned
+	}
+
+	cost, err := p.solve(createdOrders, couriers)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []Assignment
+	for i, s := range cost.assignment {
+		if s < 0 || cost.slotTime(i, s) >= infeasibleCost {
+			continue
+		}
+		assignments = append(assignments, Assignment{Order: createdOrders[i], Courier: couriers[cost.slots[s].courier]})
+	}
+	if len(assignments) == 0 {
+		return nil, SuitableCourierNotFound
+	}
+
+	// Курьер кладёт заказ в первое подходящее место. Если раскладывать заказы от больших к меньшим,
+	// каждому найдётся место: подходящие большому заказу места подходят и меньшим
+	slices.SortStableFunc(assignments, func(a, b Assignment) int {
+		return b.Order.Volume() - a.Order.Volume()
+	})
+	for _, assignment := range assignments {
+		if err := assignment.Order.Assign(assignment.Courier.ID()); err != nil {
+			return nil, err
+		}
+		if err := assignment.Courier.TakeOrder(assignment.Order); err != nil {
+			return nil, err
+		}
+	}
+	return assignments, nil
+}
+
+// slot - свободное место хранения курьера. Столбцы задачи о назначениях - места, а не курьеры,
+// поэтому за один запуск курьер получает столько заказов, сколько у него свободных мест
+type slot struct {
+	courier     int
+	totalVolume int
+}
+
+func freeSlots(couriers []*courier.Courier) []slot {
+	var slots []slot
+	for j, c := range couriers {
+		for _, storagePlace := range c.StoragePlaces() {
+			if storagePlace.OrderID() == nil {
+				slots = append(slots, slot{courier: j, totalVolume: storagePlace.TotalVolume()})
+			}
+		}
+	}
+	return slots
+}
+
+// costMatrix - времена доставки: строки - заказы, столбцы - курьеры. Пока путь не найден, в ячейке оценка по прямой.
+// Задача о назначениях решается для мест: время заказа в месте курьера - время этого курьера
+type costMatrix struct {
+	times      [][]float64
+	exact      [][]bool
+	volumes    []int
+	slots      []slot
+	assignment []int
+}
+
+// slotTime - время доставки заказа курьером, если заказ помещается в это место
+func (m costMatrix) slotTime(order int, s int) float64 {
+	if m.volumes[order] > m.slots[s].totalVolume {
+		return infeasibleCost
+	}
+	return m.times[order][m.slots[s].courier]
+}
+
+func (m costMatrix) slotTimes() [][]float64 {
+	res := make([][]float64, len(m.times))
+	for i := range m.times {
+		res[i] = make([]float64, len(m.slots))
+		for s := range m.slots {
+			res[i][s] = m.slotTime(i, s)
+		}
+	}
+	return res
+}
+
+// solve сначала распределяет заказы по оценкам, а путь ищет только для выбранных пар. Если реальное время
+// хуже оценки, распределение пересчитывается. Оценка не больше реального времени, поэтому, когда все
+// выбранные пары посчитаны точно, распределение оптимально и для реальных времён
+func (p *batchDispatcher) solve(orders []*order.Order, couriers []*courier.Courier) (costMatrix, error) {
+	now := p.now()
+	cost, err := estimateCostMatrix(orders, couriers, now, p.tickDuration)
+	if err != nil {
+		return costMatrix{}, err
+	}
+	if len(cost.slots) == 0 {
+		cost.assignment = make([]int, len(orders))
+		for i := range cost.assignment {
+			cost.assignment[i] = -1
+		}
+		return cost, nil
+	}
+
+	for {
+		cost.assignment = solveAssignment(cost.slotTimes())
+		refined := false
+		for i, s := range cost.assignment {
+			if s < 0 {
+				continue
+			}
+			j := cost.slots[s].courier
+			if cost.exact[i][j] {
+				continue
+			}
+			cost.times[i][j], err = p.exactTime(orders[i], couriers[j], now)
+			if err != nil {
+				return costMatrix{}, err
+			}
+			cost.exact[i][j] = true
+			refined = true
+		}
+		if !refined {
+			return cost, nil
+		}
+	}
+}
+
+func estimateCostMatrix(orders []*order.Order, couriers []*courier.Courier, now time.Time,
+	tickDuration time.Duration) (costMatrix, error) {
+	cost := costMatrix{
+		times:   make([][]float64, len(orders)),
+		exact:   make([][]bool, len(orders)),
+		volumes: make([]int, len(orders)),
+		slots:   freeSlots(couriers),
+	}
+	for i, o := range orders {
+		cost.volumes[i] = o.Volume()
+		cost.times[i] = make([]float64, len(couriers))
+		cost.exact[i] = make([]bool, len(couriers))
+		for j, c := range couriers {
+			// Недопустимая пара уже не изменится, искать для неё путь не нужно
+			cost.times[i][j] = infeasibleCost
+			cost.exact[i][j] = true
+
+			canTake, err := c.CanTakeOrder(o)
+			if err != nil {
+				return costMatrix{}, err
+			}
+			if !canTake {
+				continue
+			}
+
+			estimate, err := c.EstimateTimeToLocation(o.Location())
+			if err != nil {
+				return costMatrix{}, err
+			}
+			if !canArriveInTime(o, estimate, now, tickDuration) {
+				continue
+			}
+			cost.times[i][j] = estimate
+			cost.exact[i][j] = false
+		}
+	}
+	return cost, nil
+}
+
+// exactTime - время по реальному пути в объезд препятствий
+func (p *batchDispatcher) exactTime(o *order.Order, c *courier.Courier, now time.Time) (float64, error) {
+	timeToLocation, err := c.CalculateTimeToLocation(o.Location(), p.navigator)
+	if errors.Is(err, ErrPathNotFound) {
+		return infeasibleCost, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !canArriveInTime(o, timeToLocation, now, p.tickDuration) {
+		return infeasibleCost, nil
+	}
+	return timeToLocation, nil
+}
+
+var _ BatchDispatcher = &greedyBatchDispatcher{}
+
+// greedyBatchDispatcher раздаёт заказы по очереди самому быстрому свободному курьеру
+type greedyBatchDispatcher struct {
+	orderDispatcher OrderDispatcher
+}
+
+func NewGreedyBatchDispatcher(orderDispatcher OrderDispatcher) (BatchDispatcher, error) {
+	if orderDispatcher == nil {
+		return nil, errs.NewValueIsRequiredError("orderDispatcher")
+	}
+
+	return &greedyBatchDispatcher{orderDispatcher: orderDispatcher}, nil
+}
+
+func (p *greedyBatchDispatcher) Dispatch(orders []*order.Order, couriers []*courier.Courier) ([]Assignment, error) {
+	if len(orders) == 0 {
+		return nil, errs.NewValueIsRequiredError("orders")
+	}
+	if len(couriers) == 0 {
+		return nil, errs.NewValueIsRequiredError("couriers")
+	}
+
+	var assignments []Assignment
+	for _, o := range orders {
+		if o == nil || o.Status() != order.StatusCreated {
+			continue
+		}
+
+		c, err := p.orderDispatcher.Dispatch(o, couriers)
+		if err != nil {
+			if errors.Is(err, SuitableCourierNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		assignments = append(assignments, Assignment{Order: o, Courier: c})
+	}
+
+	if len(assignments) == 0 {
+		return nil, SuitableCourierNotFound
+	}
+	return assignments, nil
+}
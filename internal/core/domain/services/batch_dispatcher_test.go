@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/domain/services"
+	"delivery/internal/pkg/errs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BatchDispatchErrors(t *testing.T) {
+	svc := services.NewBatchDispatcher()
+
+	t.Run("empty orders", func(t *testing.T) {
+		c := createCourier(t, 1, createLoc(t, 1, 1), 10)
+		_, err := svc.Dispatch(nil, []*courier.Courier{c})
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+
+	t.Run("empty couriers", func(t *testing.T) {
+		o := createOrder(t, 1, createLoc(t, 1, 1))
+		_, err := svc.Dispatch([]*order.Order{o}, nil)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+
+	t.Run("No suitable courier", func(t *testing.T) {
+		c := createCourier(t, 1, createLoc(t, 1, 1), 2)
+		o := createOrder(t, 5, createLoc(t, 2, 2))
+
+		_, err := svc.Dispatch([]*order.Order{o}, []*courier.Courier{c})
+		assert.ErrorIs(t, err, services.SuitableCourierNotFound)
+		assert.Equal(t, order.StatusCreated, o.Status())
+	})
+}
+
+func Test_BatchDispatch(t *testing.T) {
+	t.Run("Minimize total time where greedy does not", func(t *testing.T) {
+		// Жадно: первый заказ забирает ближний курьер (1), второй достаётся дальнему (5) - итого 6.
+		// Оптимально: 3 + 1 = 4
+		near := createCourier(t, 1, createLoc(t, 5, 1), 10)
+		far := createCourier(t, 1, createLoc(t, 1, 1), 10)
+		first := createOrder(t, 1, createLoc(t, 4, 1))
+		second := createOrder(t, 1, createLoc(t, 6, 1))
+
+		assignments, err := services.NewBatchDispatcher().Dispatch(
+			[]*order.Order{first, second}, []*courier.Courier{near, far})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 2)
+		assert.Equal(t, far.ID(), *first.CourierID())
+		assert.Equal(t, near.ID(), *second.CourierID())
+		assert.Equal(t, order.StatusAssigned, first.Status())
+		assert.Equal(t, order.StatusAssigned, second.Status())
+	})
+
+	t.Run("Greedy strategy keeps fastest-first behaviour", func(t *testing.T) {
+		near := createCourier(t, 1, createLoc(t, 5, 1), 10)
+		far := createCourier(t, 1, createLoc(t, 1, 1), 10)
+		first := createOrder(t, 1, createLoc(t, 4, 1))
+		second := createOrder(t, 1, createLoc(t, 6, 1))
+
+		svc, err := services.NewGreedyBatchDispatcher(services.NewOrderDispatcher())
+		assert.NoError(t, err)
+		assignments, err := svc.Dispatch([]*order.Order{first, second}, []*courier.Courier{near, far})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 2)
+		assert.Equal(t, near.ID(), *first.CourierID())
+		assert.Equal(t, far.ID(), *second.CourierID())
+	})
+
+	t.Run("Assign as many orders as there are free storage places", func(t *testing.T) {
+		c := createCourier(t, 1, createLoc(t, 1, 1), 10)
+		orders := []*order.Order{
+			createOrder(t, 1, createLoc(t, 9, 9)),
+			createOrder(t, 1, createLoc(t, 2, 2)),
+			createOrder(t, 1, createLoc(t, 5, 5)),
+		}
+
+		assignments, err := services.NewBatchDispatcher().Dispatch(orders, []*courier.Courier{c})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 1)
+		assert.Equal(t, orders[1], assignments[0].Order)
+		assert.Equal(t, order.StatusCreated, orders[0].Status())
+		assert.Equal(t, order.StatusCreated, orders[2].Status())
+	})
+
+	t.Run("Give a courier one order per free storage place", func(t *testing.T) {
+		// У ближнего курьера два места: оба заказа достаются ему, дальний остаётся свободным
+		near := createCourier(t, 1, createLoc(t, 1, 1), 10)
+		assert.NoError(t, near.AddStoragePlace("Trunk", 10))
+		far := createCourier(t, 1, createLoc(t, 10, 10), 10)
+		first := createOrder(t, 1, createLoc(t, 2, 1))
+		second := createOrder(t, 1, createLoc(t, 1, 2))
+
+		assignments, err := services.NewBatchDispatcher().Dispatch(
+			[]*order.Order{first, second}, []*courier.Courier{near, far})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 2)
+		assert.Equal(t, near.ID(), *first.CourierID())
+		assert.Equal(t, near.ID(), *second.CourierID())
+		assert.Len(t, near.Route(), 2)
+		assert.Empty(t, far.Route())
+	})
+
+	t.Run("Fit orders into storage places by volume", func(t *testing.T) {
+		// Лёгкий заказ идёт первым, но не должен занять багажник, нужный тяжёлому
+		c := createCourier(t, 1, createLoc(t, 1, 1), 20)
+		assert.NoError(t, c.AddStoragePlace("Bag", 5))
+		light := createOrder(t, 3, createLoc(t, 2, 1))
+		heavy := createOrder(t, 10, createLoc(t, 1, 2))
+
+		assignments, err := services.NewBatchDispatcher().Dispatch(
+			[]*order.Order{light, heavy}, []*courier.Courier{c})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 2)
+		assert.Equal(t, order.StatusAssigned, light.Status())
+		assert.Equal(t, order.StatusAssigned, heavy.Status())
+	})
+
+	t.Run("Prefer more assignments over shorter time", func(t *testing.T) {
+		// Маленький рюкзак есть только у ближнего курьера, поэтому большой заказ должен уйти дальнему
+		small := createCourier(t, 1, createLoc(t, 2, 1), 1)
+		big := createCourier(t, 1, createLoc(t, 10, 10), 10)
+		light := createOrder(t, 1, createLoc(t, 1, 1))
+		heavy := createOrder(t, 5, createLoc(t, 1, 2))
+
+		assignments, err := services.NewBatchDispatcher().Dispatch(
+			[]*order.Order{heavy, light}, []*courier.Courier{small, big})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 2)
+		assert.Equal(t, small.ID(), *light.CourierID())
+		assert.Equal(t, big.ID(), *heavy.CourierID())
+	})
+
+	t.Run("Skip courier that cannot arrive before window closes", func(t *testing.T) {
+		now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+		svc, err := services.NewBatchDispatcherWithClock(time.Minute, func() time.Time { return now })
+		assert.NoError(t, err)
+
+		period, _ := order.NewDeliveryPeriod(now.Add(-time.Hour), now.Add(3*time.Minute))
+		o := createOrderWithPeriod(t, 5, createLoc(t, 5, 5), period)
+		slow := createCourier(t, 1, createLoc(t, 5, 1), 5)
+		fast := createCourier(t, 5, createLoc(t, 1, 1), 5)
+
+		assignments, err := svc.Dispatch([]*order.Order{o}, []*courier.Courier{slow, fast})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 1)
+		assert.Equal(t, fast, assignments[0].Courier)
+	})
+}
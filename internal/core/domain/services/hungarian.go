@@ -0,0 +1,107 @@
+package services
+
+import "math"
+
+// solveAssignment решает задачу о назначениях венгерским алгоритмом за O(n^2*m).
+// cost[i][j] - стоимость назначения строки i на столбец j, матрица может быть прямоугольной.
+// Возвращает для каждой строки индекс назначенного столбца или -1, если столбцов не хватило.
+func solveAssignment(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return []int{}
+	}
+	cols := len(cost[0])
+
+	// Алгоритм требует, чтобы строк было не больше, чем столбцов
+	if rows > cols {
+		transposed := make([][]float64, cols)
+		for j := range transposed {
+			transposed[j] = make([]float64, rows)
+			for i := 0; i < rows; i++ {
+				transposed[j][i] = cost[i][j]
+			}
+		}
+
+		result := make([]int, rows)
+		for i := range result {
+			result[i] = -1
+		}
+		for j, i := range solveAssignment(transposed) {
+			if i >= 0 {
+				result[i] = j
+			}
+		}
+		return result
+	}
+
+	// Потенциалы строк (u) и столбцов (v), p[j] - строка, назначенная на столбец j.
+	// Индексация с единицы, нулевой столбец - фиктивный
+	u := make([]float64, rows+1)
+	v := make([]float64, cols+1)
+	p := make([]int, cols+1)
+	way := make([]int, cols+1)
+
+	for i := 1; i <= rows; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, cols+1)
+		used := make([]bool, cols+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= cols; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= cols; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		// Разворачиваем увеличивающую цепочку
+		for {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+			if j0 == 0 {
+				break
+			}
+		}
+	}
+
+	result := make([]int, rows)
+	for i := range result {
+		result[i] = -1
+	}
+	for j := 1; j <= cols; j++ {
+		if p[j] != 0 {
+			result[p[j]-1] = j - 1
+		}
+	}
+	return result
+}
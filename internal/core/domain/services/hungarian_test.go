@@ -0,0 +1,79 @@
+package services
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SolveAssignment(t *testing.T) {
+	t.Run("Empty matrix", func(t *testing.T) {
+		assert.Empty(t, solveAssignment(nil))
+	})
+
+	t.Run("Match brute force on random matrices", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(42))
+		for iteration := 0; iteration < 200; iteration++ {
+			rows, cols := 1+rnd.Intn(5), 1+rnd.Intn(5)
+			cost := make([][]float64, rows)
+			for i := range cost {
+				cost[i] = make([]float64, cols)
+				for j := range cost[i] {
+					cost[i][j] = float64(rnd.Intn(20))
+				}
+			}
+
+			result := solveAssignment(cost)
+
+			assert.Len(t, result, rows)
+			usedCols := make(map[int]bool)
+			total, assigned := 0.0, 0
+			for i, j := range result {
+				if j < 0 {
+					continue
+				}
+				assert.False(t, usedCols[j])
+				usedCols[j] = true
+				total += cost[i][j]
+				assigned++
+			}
+			assert.Equal(t, min(rows, cols), assigned)
+			assert.Equal(t, bruteForceAssignment(cost, 0, make([]bool, cols)), total)
+		}
+	})
+}
+
+// bruteForceAssignment перебирает все назначения максимального размера
+func bruteForceAssignment(cost [][]float64, row int, usedCols []bool) float64 {
+	rows, cols := len(cost), len(cost[0])
+	if row == rows {
+		return 0
+	}
+
+	best := math.Inf(1)
+	// Если строк больше, чем столбцов, часть строк остаётся без назначения
+	if rows-row > cols-countUsed(usedCols) {
+		best = bruteForceAssignment(cost, row+1, usedCols)
+	}
+	for j := 0; j < cols; j++ {
+		if usedCols[j] {
+			continue
+		}
+		usedCols[j] = true
+		best = math.Min(best, cost[row][j]+bruteForceAssignment(cost, row+1, usedCols))
+		usedCols[j] = false
+	}
+	return best
+}
+
+func countUsed(usedCols []bool) int {
+	count := 0
+	for _, used := range usedCols {
+		if used {
+			count++
+		}
+	}
+	return count
+}
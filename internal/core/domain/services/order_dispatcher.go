@@ -6,8 +6,13 @@ import (
 	"delivery/internal/pkg/errs"
 	"errors"
 	"math"
+	"sort"
+	"time"
 )
 
+// DefaultTickDuration - реальное время одного шага курьера (job перемещения запускается раз в секунду)
+const DefaultTickDuration = time.Second
+
 var (
 	SuitableCourierNotFound = errors.New("suitable courier not found")
 )
@@ -19,10 +24,46 @@ type OrderDispatcher interface {
 var _ OrderDispatcher = &orderDispatcher{}
 
 type orderDispatcher struct {
+	tickDuration time.Duration
+	now          func() time.Time
+	navigator    courier.Navigator
 }
 
 func NewOrderDispatcher() OrderDispatcher {
-	return &orderDispatcher{}
+	return &orderDispatcher{
+		tickDuration: DefaultTickDuration,
+		now:          time.Now,
+		navigator:    newOpenPathfinder(),
+	}
+}
+
+// NewOrderDispatcherWithNavigator создаёт диспетчер, который оценивает время по реальному пути в объезд препятствий
+func NewOrderDispatcherWithNavigator(navigator courier.Navigator) (OrderDispatcher, error) {
+	if navigator == nil {
+		return nil, errs.NewValueIsRequiredError("navigator")
+	}
+
+	return &orderDispatcher{
+		tickDuration: DefaultTickDuration,
+		now:          time.Now,
+		navigator:    navigator,
+	}, nil
+}
+
+// NewOrderDispatcherWithClock создаёт диспетчер с заданной длительностью тика и источником текущего времени
+func NewOrderDispatcherWithClock(tickDuration time.Duration, now func() time.Time) (OrderDispatcher, error) {
+	if tickDuration <= 0 {
+		return nil, errs.NewValueIsRequiredError("tickDuration")
+	}
+	if now == nil {
+		return nil, errs.NewValueIsRequiredError("now")
+	}
+
+	return &orderDispatcher{
+		tickDuration: tickDuration,
+		now:          now,
+		navigator:    newOpenPathfinder(),
+	}, nil
 }
 
 func (p *orderDispatcher) Dispatch(currentOrder *order.Order, couriers []*courier.Courier) (*courier.Courier, error) {
@@ -51,26 +92,41 @@ func (p *orderDispatcher) Dispatch(currentOrder *order.Order, couriers []*courie
 	return bestCourier, nil
 }
 
+// findBestCourier ищет путь только для тех курьеров, чья оценка по прямой лучше уже найденного времени
 func (p *orderDispatcher) findBestCourier(order *order.Order, couriers []*courier.Courier) (*courier.Courier, error) {
+	candidates, err := estimateCandidates(order, couriers)
+	if err != nil {
+		return nil, err
+	}
+
 	var bestCourier *courier.Courier
 	minTime := math.MaxFloat64
+	now := p.now()
 
-	for _, c := range couriers {
-		canTake, err := c.CanTakeOrder(order)
-		if err != nil {
-			return nil, err
+	for _, candidate := range candidates {
+		// Кандидаты отсортированы по оценке, а реальный путь не короче оценки: дальше быстрее не будет
+		if candidate.estimate >= minTime {
+			break
 		}
-		if !canTake {
+		if !canArriveInTime(order, candidate.estimate, now, p.tickDuration) {
 			continue
 		}
 
-		time, err := c.CalculateTimeToLocation(order.Location())
+		c := candidate.courier
+		timeToLocation, err := c.CalculateTimeToLocation(order.Location(), p.navigator)
+		if errors.Is(err, ErrPathNotFound) {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		if time < minTime {
-			minTime = time
+		if !canArriveInTime(order, timeToLocation, now, p.tickDuration) {
+			continue
+		}
+
+		if timeToLocation < minTime {
+			minTime = timeToLocation
 			bestCourier = c
 		}
 	}
@@ -81,3 +137,45 @@ func (p *orderDispatcher) findBestCourier(order *order.Order, couriers []*courie
 
 	return bestCourier, nil
 }
+
+type candidate struct {
+	courier  *courier.Courier
+	estimate float64
+}
+
+// estimateCandidates отбирает курьеров, которые могут взять заказ, по возрастанию оценки времени по прямой
+func estimateCandidates(order *order.Order, couriers []*courier.Courier) ([]candidate, error) {
+	candidates := make([]candidate, 0, len(couriers))
+	for _, c := range couriers {
+		canTake, err := c.CanTakeOrder(order)
+		if err != nil {
+			return nil, err
+		}
+		if !canTake {
+			continue
+		}
+
+		estimate, err := c.EstimateTimeToLocation(order.Location())
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate{courier: c, estimate: estimate})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].estimate < candidates[j].estimate
+	})
+	return candidates, nil
+}
+
+// canArriveInTime проверяет, успеет ли курьер доехать до закрытия окна доставки.
+// Если окно уже закрыто, заказ всё равно опаздывает — отдаём его самому быстрому курьеру.
+func canArriveInTime(order *order.Order, ticks float64, now time.Time, tickDuration time.Duration) bool {
+	deliveryPeriod := order.DeliveryPeriod()
+	if deliveryPeriod.IsEmpty() || deliveryPeriod.IsClosedAt(now) {
+		return true
+	}
+
+	arrival := now.Add(time.Duration(math.Ceil(ticks)) * tickDuration)
+	return !deliveryPeriod.IsClosedAt(arrival)
+}
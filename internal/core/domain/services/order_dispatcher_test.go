@@ -8,6 +8,7 @@ import (
 	"delivery/internal/pkg/errs"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,16 @@ func Test_DispatchErrors(t *testing.T) {
 		assert.EqualError(t, err, errs.NewValueIsRequiredError("couriers").Error())
 	})
 
+	t.Run("cancelled order", func(t *testing.T) {
+		c := createCourier(t, 5, createLoc(t, 5, 5), 10)
+		o := createOrder(t, 3, createLoc(t, 6, 6))
+		_ = o.Cancel("reason")
+
+		_, err := svc.Dispatch(o, []*courier.Courier{c})
+		assert.ErrorIs(t, err, order.ErrOrderHasAlreadyBeenAssigned)
+		assert.Nil(t, o.CourierID())
+	})
+
 	t.Run("No suitable courier", func(t *testing.T) {
 		c := createCourier(t, 5, createLoc(t, 5, 5), 2)
 		o := createOrder(t, 3, createLoc(t, 6, 6))
@@ -97,6 +108,47 @@ func TestDispatch_Success(t *testing.T) {
 	})
 }
 
+func TestDispatch_DeliveryPeriod(t *testing.T) {
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	svc, err := services.NewOrderDispatcherWithClock(time.Minute, func() time.Time { return now })
+	assert.NoError(t, err)
+
+	t.Run("Skip courier that cannot arrive before window closes", func(t *testing.T) {
+		// Ближний медленный курьер: 4 тика = 4 минуты; дальний быстрый: 2 тика = 2 минуты
+		period, _ := order.NewDeliveryPeriod(now.Add(-time.Hour), now.Add(3*time.Minute))
+		o := createOrderWithPeriod(t, 5, createLoc(t, 5, 5), period)
+		slow := createCourier(t, 1, createLoc(t, 5, 1), 5)
+		fast := createCourier(t, 5, createLoc(t, 1, 1), 5)
+
+		c, err := svc.Dispatch(o, []*courier.Courier{slow, fast})
+
+		assert.NoError(t, err)
+		assert.Equal(t, fast, c)
+	})
+
+	t.Run("Return error if nobody can arrive in time", func(t *testing.T) {
+		period, _ := order.NewDeliveryPeriod(now.Add(-time.Hour), now.Add(time.Minute))
+		o := createOrderWithPeriod(t, 5, createLoc(t, 10, 10), period)
+		slow := createCourier(t, 1, createLoc(t, 1, 1), 5)
+
+		_, err := svc.Dispatch(o, []*courier.Courier{slow})
+
+		assert.ErrorIs(t, err, services.SuitableCourierNotFound)
+		assert.Equal(t, order.StatusCreated, o.Status())
+	})
+
+	t.Run("Dispatch already late order to fastest courier", func(t *testing.T) {
+		period, _ := order.NewDeliveryPeriod(now.Add(-2*time.Hour), now.Add(-time.Hour))
+		o := createOrderWithPeriod(t, 5, createLoc(t, 10, 10), period)
+		slow := createCourier(t, 1, createLoc(t, 1, 1), 5)
+
+		c, err := svc.Dispatch(o, []*courier.Courier{slow})
+
+		assert.NoError(t, err)
+		assert.Equal(t, slow, c)
+	})
+}
+
 func Test_Error(t *testing.T) {
 	var err = errs.ErrObjectNotFound
 	var target = errs.NewObjectNotFoundError("str", nil)
@@ -105,24 +157,48 @@ func Test_Error(t *testing.T) {
 }
 
 // HELPERS
-func createLoc(t *testing.T, x, y uint8) kernel.Location {
-	loc, err := kernel.NewLocation(x, y)
+func createLoc(t *testing.T, x, y int) kernel.Location {
+	loc, err := kernel.NewLocation(kernel.DefaultGrid(), x, y)
 	if err != nil {
 		t.Fatalf("failed to create location: %v", err)
 	}
 	return loc
 }
 
+func createAddress(t *testing.T) kernel.Address {
+	address, err := kernel.NewAddress("", "", "Тестовая", "", "")
+	if err != nil {
+		t.Fatalf("failed to create address: %v", err)
+	}
+	return address
+}
+
 func createOrder(t *testing.T, volume int, loc kernel.Location) *order.Order {
-	o, err := order.NewOrder(uuid.New(), loc, volume)
+	o, err := order.NewOrder(uuid.New(), createAddress(t), loc, volume)
+	if err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+	return o
+}
+
+func createOrderWithPeriod(t *testing.T, volume int, loc kernel.Location, period order.DeliveryPeriod) *order.Order {
+	o, err := order.NewOrderWithDeliveryPeriod(uuid.New(), createAddress(t), loc, volume, period)
 	if err != nil {
 		t.Fatalf("failed to create order: %v", err)
 	}
 	return o
 }
 
+func createTransport(t *testing.T, speed int) courier.Transport {
+	transport, err := courier.NewTransport(uuid.New(), "Test", speed, nil)
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	return transport
+}
+
 func createCourier(t *testing.T, speed int, loc kernel.Location, storageVolume int) *courier.Courier {
-	c, err := courier.NewCourier("Test", speed, loc)
+	c, err := courier.NewCourier("Test", createTransport(t, speed), loc)
 	if err != nil {
 		t.Fatalf("failed to create courier: %v", err)
 	}
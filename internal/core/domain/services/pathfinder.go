@@ -0,0 +1,182 @@
+package services
+
+import (
+	"container/heap"
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrPathNotFound = errors.New("path not found")
+)
+
+// Pathfinder ищет кратчайший путь по карте города в объезд непроходимых клеток
+type Pathfinder interface {
+	courier.Navigator
+}
+
+var _ Pathfinder = &aStarPathfinder{}
+
+type aStarPathfinder struct {
+	cityMap kernel.CityMap
+}
+
+func NewPathfinder(cityMap kernel.CityMap) (Pathfinder, error) {
+	if cityMap.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("cityMap")
+	}
+
+	return &aStarPathfinder{cityMap: cityMap}, nil
+}
+
+// newOpenPathfinder - карта без препятствий: путь всегда равен манхэттенскому расстоянию.
+// Границы берутся предельные, ведь прямой путь между двумя точками карты из неё не выходит
+func newOpenPathfinder() Pathfinder {
+	grid, err := kernel.NewGrid(kernel.MaxGridSide, kernel.MaxGridSide)
+	if err != nil {
+		panic(err)
+	}
+	cityMap, err := kernel.NewCityMap(grid, nil)
+	if err != nil {
+		panic(err)
+	}
+	return &aStarPathfinder{cityMap: cityMap}
+}
+
+func (p *aStarPathfinder) FindPath(from kernel.Location, to kernel.Location) ([]kernel.Location, error) {
+	if from.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("from")
+	}
+	if to.IsEmpty() {
+		return nil, errs.NewValueIsRequiredError("to")
+	}
+	if from.Equals(to) {
+		return []kernel.Location{}, nil
+	}
+	if !p.cityMap.IsPassable(to) {
+		return nil, ErrPathNotFound
+	}
+
+	// Если угол "сначала по X, потом по Y" свободен, он уже кратчайший
+	if path, ok := p.straightPath(from, to); ok {
+		return path, nil
+	}
+	return p.aStar(from, to)
+}
+
+// straightPath идёт сначала по горизонтали, затем по вертикали, как курьер без карты
+func (p *aStarPathfinder) straightPath(from kernel.Location, to kernel.Location) ([]kernel.Location, bool) {
+	distance, _ := from.CountDistanceTo(to)
+	path := make([]kernel.Location, 0, distance)
+
+	current := from
+	for !current.Equals(to) {
+		next, ok := p.stepTowards(current, to)
+		if !ok {
+			return nil, false
+		}
+		path = append(path, next)
+		current = next
+	}
+	return path, true
+}
+
+func (p *aStarPathfinder) stepTowards(current kernel.Location, to kernel.Location) (kernel.Location, bool) {
+	wantX, wantY := current.X(), current.Y()
+	switch {
+	case current.X() < to.X():
+		wantX++
+	case current.X() > to.X():
+		wantX--
+	case current.Y() < to.Y():
+		wantY++
+	default:
+		wantY--
+	}
+
+	for _, neighbour := range p.cityMap.Neighbours(current) {
+		if neighbour.X() == wantX && neighbour.Y() == wantY {
+			return neighbour, true
+		}
+	}
+	return kernel.Location{}, false
+}
+
+// aStar - поиск A* по четырём направлениям, эвристика - манхэттенское расстояние
+func (p *aStarPathfinder) aStar(from kernel.Location, to kernel.Location) ([]kernel.Location, error) {
+	cameFrom := make(map[kernel.Location]kernel.Location)
+	cost := map[kernel.Location]int{from: 0}
+
+	open := &openSet{}
+	heap.Push(open, p.newNode(from, 0, to))
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(node)
+		if current.location.Equals(to) {
+			return reconstructPath(cameFrom, from, to), nil
+		}
+		// В очереди могла остаться устаревшая запись с большей стоимостью
+		if current.cost > cost[current.location] {
+			continue
+		}
+
+		for _, neighbour := range p.cityMap.Neighbours(current.location) {
+			neighbourCost := current.cost + 1
+			if known, ok := cost[neighbour]; ok && known <= neighbourCost {
+				continue
+			}
+			cost[neighbour] = neighbourCost
+			cameFrom[neighbour] = current.location
+			heap.Push(open, p.newNode(neighbour, neighbourCost, to))
+		}
+	}
+
+	return nil, ErrPathNotFound
+}
+
+func (p *aStarPathfinder) newNode(location kernel.Location, cost int, to kernel.Location) node {
+	heuristic, _ := location.CountDistanceTo(to)
+	return node{location: location, cost: cost, heuristic: heuristic}
+}
+
+func reconstructPath(cameFrom map[kernel.Location]kernel.Location, from kernel.Location, to kernel.Location) []kernel.Location {
+	var path []kernel.Location
+	for current := to; !current.Equals(from); current = cameFrom[current] {
+		path = append(path, current)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+type node struct {
+	location  kernel.Location
+	cost      int
+	heuristic int
+}
+
+// openSet - очередь с приоритетом по cost+heuristic, при равенстве ближе к цели тот, у кого меньше эвристика
+type openSet []node
+
+func (s openSet) Len() int { return len(s) }
+
+func (s openSet) Less(i, j int) bool {
+	fi, fj := s[i].cost+s[i].heuristic, s[j].cost+s[j].heuristic
+	if fi != fj {
+		return fi < fj
+	}
+	return s[i].heuristic < s[j].heuristic
+}
+
+func (s openSet) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *openSet) Push(x any) { *s = append(*s, x.(node)) }
+
+func (s *openSet) Pop() any {
+	old := *s
+	n := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return n
+}
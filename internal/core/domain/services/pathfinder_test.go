@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/domain/services"
+	"delivery/internal/pkg/errs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathfinder_FindPath(t *testing.T) {
+	t.Run("open map gives manhattan path", func(t *testing.T) {
+		pathfinder := createPathfinder(t)
+
+		path, err := pathfinder.FindPath(createLoc(t, 1, 1), createLoc(t, 4, 5))
+
+		assert.NoError(t, err)
+		assert.Len(t, path, 7)
+		assert.Equal(t, createLoc(t, 4, 5), path[len(path)-1])
+		assertAdjacent(t, createLoc(t, 1, 1), path)
+	})
+
+	t.Run("same location gives empty path", func(t *testing.T) {
+		pathfinder := createPathfinder(t)
+
+		path, err := pathfinder.FindPath(createLoc(t, 3, 3), createLoc(t, 3, 3))
+
+		assert.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("go around a wall through the gap", func(t *testing.T) {
+		// Стена по x=5 с проходом только в (5,10)
+		var wall []kernel.Location
+		for y := 1; y <= 9; y++ {
+			wall = append(wall, createLoc(t, 5, y))
+		}
+		pathfinder := createPathfinder(t, wall...)
+
+		path, err := pathfinder.FindPath(createLoc(t, 4, 1), createLoc(t, 6, 1))
+
+		assert.NoError(t, err)
+		// 9 клеток вверх, 2 в сторону и 9 вниз вместо 2 шагов напрямую
+		assert.Len(t, path, 20)
+		assert.Contains(t, path, createLoc(t, 5, 10))
+		assertAdjacent(t, createLoc(t, 4, 1), path)
+		for _, cell := range path {
+			assert.NotContains(t, wall, cell)
+		}
+	})
+
+	t.Run("unreachable target", func(t *testing.T) {
+		pathfinder := createPathfinder(t, createLoc(t, 2, 1), createLoc(t, 1, 2))
+
+		_, err := pathfinder.FindPath(createLoc(t, 5, 5), createLoc(t, 1, 1))
+
+		assert.ErrorIs(t, err, services.ErrPathNotFound)
+	})
+
+	t.Run("blocked target", func(t *testing.T) {
+		pathfinder := createPathfinder(t, createLoc(t, 3, 3))
+
+		_, err := pathfinder.FindPath(createLoc(t, 1, 1), createLoc(t, 3, 3))
+
+		assert.ErrorIs(t, err, services.ErrPathNotFound)
+	})
+
+	t.Run("empty city map", func(t *testing.T) {
+		_, err := services.NewPathfinder(kernel.CityMap{})
+
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func TestDispatch_WithObstacles(t *testing.T) {
+	t.Run("choose courier with shorter real path", func(t *testing.T) {
+		// Река по y=5 без мостов: курьер с другого берега до заказа не доберётся
+		var river []kernel.Location
+		for x := 1; x <= 10; x++ {
+			river = append(river, createLoc(t, x, 5))
+		}
+		svc, err := services.NewOrderDispatcherWithNavigator(createPathfinder(t, river...))
+		assert.NoError(t, err)
+
+		acrossRiver := createCourier(t, 1, createLoc(t, 3, 6), 10)
+		sameBank := createCourier(t, 1, createLoc(t, 9, 1), 10)
+		o := createOrder(t, 1, createLoc(t, 3, 4))
+
+		winner, err := svc.Dispatch(o, []*courier.Courier{acrossRiver, sameBank})
+
+		assert.NoError(t, err)
+		assert.Equal(t, sameBank.ID(), winner.ID())
+	})
+
+	t.Run("batch dispatcher re-plans when the real path is longer than the estimate", func(t *testing.T) {
+		// Река по y=5 с единственным мостом в x=10: напрямую курьер за рекой ближе, но в объезд дальше
+		var river []kernel.Location
+		for x := 1; x <= 9; x++ {
+			river = append(river, createLoc(t, x, 5))
+		}
+		svc, err := services.NewBatchDispatcherWithNavigator(createPathfinder(t, river...))
+		assert.NoError(t, err)
+
+		acrossRiver := createCourier(t, 1, createLoc(t, 3, 6), 10)
+		sameBank := createCourier(t, 1, createLoc(t, 9, 1), 10)
+		o := createOrder(t, 1, createLoc(t, 3, 4))
+
+		assignments, err := svc.Dispatch([]*order.Order{o}, []*courier.Courier{acrossRiver, sameBank})
+
+		assert.NoError(t, err)
+		assert.Len(t, assignments, 1)
+		assert.Equal(t, sameBank.ID(), *o.CourierID())
+	})
+
+	t.Run("nil navigator", func(t *testing.T) {
+		_, err := services.NewOrderDispatcherWithNavigator(nil)
+
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+}
+
+func createPathfinder(t *testing.T, blocked ...kernel.Location) services.Pathfinder {
+	cityMap, err := kernel.NewCityMap(kernel.DefaultGrid(), blocked)
+	assert.NoError(t, err)
+	pathfinder, err := services.NewPathfinder(cityMap)
+	assert.NoError(t, err)
+	return pathfinder
+}
+
+func assertAdjacent(t *testing.T, from kernel.Location, path []kernel.Location) {
+	previous := from
+	for _, cell := range path {
+		distance, err := previous.CountDistanceTo(cell)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, distance)
+		previous = cell
+	}
+}
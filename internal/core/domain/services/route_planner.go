@@ -0,0 +1,130 @@
+package services
+
+import (
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/pkg/errs"
+)
+
+// RoutePlanner выбирает порядок объезда точек маршрута курьера
+type RoutePlanner interface {
+	Plan(courier *courier.Courier) error
+}
+
+var _ RoutePlanner = &routePlanner{}
+
+// routePlanner строит маршрут методом ближайшего соседа и улучшает его 2-opt.
+// Маршрут незамкнутый: начинается в текущей точке курьера и заканчивается на последней доставке
+type routePlanner struct{}
+
+func NewRoutePlanner() RoutePlanner {
+	return &routePlanner{}
+}
+
+func (p *routePlanner) Plan(c *courier.Courier) error {
+	if c == nil {
+		return errs.NewValueIsRequiredError("courier")
+	}
+
+	stops := c.Route()
+	if len(stops) < 2 {
+		return nil
+	}
+
+	route, err := p.nearestNeighbour(c.Location(), stops)
+	if err != nil {
+		return err
+	}
+	route, err = p.twoOpt(c.Location(), route)
+	if err != nil {
+		return err
+	}
+
+	return c.ReplanRoute(route)
+}
+
+// nearestNeighbour каждый раз едет к ближайшей ещё не посещённой точке
+func (p *routePlanner) nearestNeighbour(start kernel.Location, stops []courier.RouteStop) ([]courier.RouteStop, error) {
+	remaining := append([]courier.RouteStop(nil), stops...)
+	route := make([]courier.RouteStop, 0, len(stops))
+
+	current := start
+	for len(remaining) > 0 {
+		nearest := 0
+		nearestDistance := -1
+		for i, stop := range remaining {
+			d, err := current.CountDistanceTo(stop.Location())
+			if err != nil {
+				return nil, err
+			}
+			if nearestDistance < 0 || d < nearestDistance {
+				nearest, nearestDistance = i, d
+			}
+		}
+
+		route = append(route, remaining[nearest])
+		current = remaining[nearest].Location()
+		remaining = append(remaining[:nearest], remaining[nearest+1:]...)
+	}
+	return route, nil
+}
+
+// twoOpt разворачивает участки маршрута, пока это сокращает путь
+func (p *routePlanner) twoOpt(start kernel.Location, route []courier.RouteStop) ([]courier.RouteStop, error) {
+	improved := true
+	for improved {
+		improved = false
+		for i := 0; i < len(route)-1; i++ {
+			for k := i + 1; k < len(route); k++ {
+				delta, err := p.reverseDelta(start, route, i, k)
+				if err != nil {
+					return nil, err
+				}
+				if delta < 0 {
+					reverse(route[i : k+1])
+					improved = true
+				}
+			}
+		}
+	}
+	return route, nil
+}
+
+// reverseDelta - изменение длины пути, если развернуть участок route[i..k]
+func (p *routePlanner) reverseDelta(start kernel.Location, route []courier.RouteStop, i, k int) (int, error) {
+	before := start
+	if i > 0 {
+		before = route[i-1].Location()
+	}
+
+	oldIn, err := before.CountDistanceTo(route[i].Location())
+	if err != nil {
+		return 0, err
+	}
+	newIn, err := before.CountDistanceTo(route[k].Location())
+	if err != nil {
+		return 0, err
+	}
+	delta := newIn - oldIn
+
+	// У последней точки незамкнутого маршрута нет исходящего ребра
+	if k < len(route)-1 {
+		after := route[k+1].Location()
+		oldOut, err := route[k].Location().CountDistanceTo(after)
+		if err != nil {
+			return 0, err
+		}
+		newOut, err := route[i].Location().CountDistanceTo(after)
+		if err != nil {
+			return 0, err
+		}
+		delta += newOut - oldOut
+	}
+	return delta, nil
+}
+
+func reverse(stops []courier.RouteStop) {
+	for i, j := 0, len(stops)-1; i < j; i, j = i+1, j-1 {
+		stops[i], stops[j] = stops[j], stops[i]
+	}
+}
@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"delivery/internal/core/domain/model/courier"
+	"delivery/internal/core/domain/model/kernel"
+	"delivery/internal/core/domain/model/order"
+	"delivery/internal/core/domain/services"
+	"delivery/internal/pkg/errs"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RoutePlanner(t *testing.T) {
+	planner := services.NewRoutePlanner()
+
+	t.Run("nil courier", func(t *testing.T) {
+		err := planner.Plan(nil)
+		assert.ErrorIs(t, err, errs.ErrValueIsRequired)
+	})
+
+	t.Run("Visit nearest stops first", func(t *testing.T) {
+		far := createOrder(t, 1, createLoc(t, 10, 10))
+		near := createOrder(t, 1, createLoc(t, 2, 1))
+		middle := createOrder(t, 1, createLoc(t, 5, 5))
+		c := createCourierWithOrders(t, createLoc(t, 1, 1), far, near, middle)
+
+		err := planner.Plan(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{near.ID(), middle.ID(), far.ID()}, routeOrderIDs(c))
+	})
+
+	t.Run("Improve nearest neighbour route with 2-opt", func(t *testing.T) {
+		// Ближайший сосед: (9,1) -> (6,1) -> (7,4) -> (1,1), длина 18.
+		// Разворот участка (6,1)-(7,4) даёт оптимальный маршрут длиной 16
+		start := createLoc(t, 10, 2)
+		a := createOrder(t, 1, createLoc(t, 6, 1))
+		b := createOrder(t, 1, createLoc(t, 1, 1))
+		c := createOrder(t, 1, createLoc(t, 9, 1))
+		d := createOrder(t, 1, createLoc(t, 7, 4))
+		testCourier := createCourierWithOrders(t, start, a, b, c, d)
+
+		err := planner.Plan(testCourier)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{c.ID(), d.ID(), a.ID(), b.ID()}, routeOrderIDs(testCourier))
+		assert.Equal(t, 16, routeLength(t, start, testCourier.Route()))
+	})
+
+	t.Run("Keep all stops", func(t *testing.T) {
+		c := createCourierWithOrders(t, createLoc(t, 1, 1),
+			createOrder(t, 1, createLoc(t, 3, 7)),
+			createOrder(t, 1, createLoc(t, 8, 2)),
+			createOrder(t, 1, createLoc(t, 4, 4)),
+		)
+		before := c.Route()
+
+		err := planner.Plan(c)
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, before, c.Route())
+	})
+}
+
+func createCourierWithOrders(t *testing.T, loc kernel.Location, orders ...*order.Order) *courier.Courier {
+	c, err := courier.NewCourier("Test", createTransport(t, 1), loc)
+	if err != nil {
+		t.Fatalf("failed to create courier: %v", err)
+	}
+	for _, o := range orders {
+		if err := c.AddStoragePlace("TestBag", 10); err != nil {
+			t.Fatalf("failed to add storage: %v", err)
+		}
+		if err := c.TakeOrder(o); err != nil {
+			t.Fatalf("failed to take order: %v", err)
+		}
+	}
+	return c
+}
+
+func routeOrderIDs(c *courier.Courier) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, stop := range c.Route() {
+		ids = append(ids, stop.OrderID())
+	}
+	return ids
+}
+
+func routeLength(t *testing.T, start kernel.Location, route []courier.RouteStop) int {
+	length := 0
+	current := start
+	for _, stop := range route {
+		d, err := current.CountDistanceTo(stop.Location())
+		assert.NoError(t, err)
+		length += int(d)
+		current = stop.Location()
+	}
+	return length
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"delivery/internal/core/domain/model/courier"
 	"github.com/google/uuid"
+	"time"
 )
 
 type CourierRepository interface {
@@ -11,4 +12,15 @@ type CourierRepository interface {
 	Update(ctx context.Context, aggregate *courier.Courier) error
 	Get(ctx context.Context, ID uuid.UUID) (*courier.Courier, error)
 	GetAllFree(ctx context.Context) ([]*courier.Courier, error)
+	GetAllOnRoute(ctx context.Context) ([]*courier.Courier, error)
+
+	// ClaimFree арендует на lease до limit свободных курьеров, которых не арендовал другой обработчик.
+	// Аренда фиксируется сразу, и блокировки на время распределения не держатся
+	ClaimFree(ctx context.Context, limit int, lease time.Duration) ([]*courier.Courier, error)
+	// ReleaseClaim снимает аренду с курьеров ids, чтобы их сразу могли забрать другие обработчики
+	ReleaseClaim(ctx context.Context, ids []uuid.UUID) error
+
+	// LockFree блокирует до конца транзакции unit of work тех из курьеров ids, что всё ещё свободны.
+	// Курьеры, заблокированные другими транзакциями, пропускаются
+	LockFree(ctx context.Context, ids []uuid.UUID) ([]*courier.Courier, error)
 }
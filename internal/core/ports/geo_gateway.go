@@ -6,5 +6,6 @@ import (
 )
 
 type GeoLocationGateway interface {
-	DefineLocation(ctx context.Context, street string) (kernel.Location, error)
+	// DefineLocation определяет координаты адреса на карте города
+	DefineLocation(ctx context.Context, address kernel.Address) (kernel.Location, error)
 }
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/order"
+)
+
+type OrderProducer interface {
+	Publish(ctx context.Context, domainEvent order.StatusChangedDomainEvent) error
+	Close() error
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"delivery/internal/core/domain/model/order"
 	"github.com/google/uuid"
+	"time"
 )
 
 type OrderRepository interface {
@@ -12,4 +13,14 @@ type OrderRepository interface {
 	Get(ctx context.Context, ID uuid.UUID) (*order.Order, error)
 	GetFirstInCreatedStatus(ctx context.Context) (*order.Order, error)
 	GetAllInAssignedStatus(ctx context.Context) ([]*order.Order, error)
+
+	// ClaimInCreatedStatus арендует на lease до limit самых старых новых заказов, которые не арендовал
+	// другой обработчик. Аренда фиксируется сразу, и блокировки на время распределения не держатся
+	ClaimInCreatedStatus(ctx context.Context, limit int, lease time.Duration) ([]*order.Order, error)
+	// ReleaseClaim снимает аренду с заказов ids, чтобы их сразу могли забрать другие обработчики
+	ReleaseClaim(ctx context.Context, ids []uuid.UUID) error
+
+	// LockInCreatedStatus блокирует до конца транзакции unit of work те из заказов ids, что всё ещё новые.
+	// Заказы, заблокированные другими транзакциями, пропускаются
+	LockInCreatedStatus(ctx context.Context, ids []uuid.UUID) ([]*order.Order, error)
 }
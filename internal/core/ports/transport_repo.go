@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"delivery/internal/core/domain/model/courier"
+	"github.com/google/uuid"
+)
+
+type TransportRepository interface {
+	Get(ctx context.Context, ID uuid.UUID) (courier.Transport, error)
+}
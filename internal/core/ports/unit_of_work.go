@@ -7,4 +7,5 @@ import (
 type UnitOfWork interface {
 	Begin(ctx context.Context)
 	Commit(ctx context.Context) error
+	Rollback(ctx context.Context)
 }
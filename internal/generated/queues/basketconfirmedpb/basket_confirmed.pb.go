@@ -249,6 +249,8 @@ func (x *Item) GetQuantity() int32 {
 	return 0
 }
 
+// Окно доставки - часы суток [from, to) по местному времени магазина.
+// Часовой пояс магазина задаётся в сервисе доставки (DELIVERY_TIMEZONE), по умолчанию UTC
 type DeliveryPeriod struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	From          int32                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
@@ -4,10 +4,12 @@ type AggregateRoot interface {
 	GetDomainEvents() []DomainEvent
 	ClearDomainEvents()
 	RaiseDomainEvent(DomainEvent)
+	Version() int64
 }
 
 type BaseAggregate struct {
 	domainEvents []DomainEvent
+	version      int64
 }
 
 func NewBaseAggregate() *BaseAggregate {
@@ -16,6 +18,14 @@ func NewBaseAggregate() *BaseAggregate {
 	}
 }
 
+// NewBaseAggregateWithVersion восстанавливает базу агрегата с версией из хранилища
+func NewBaseAggregateWithVersion(version int64) *BaseAggregate {
+	return &BaseAggregate{
+		domainEvents: make([]DomainEvent, 0),
+		version:      version,
+	}
+}
+
 func (a *BaseAggregate) ClearDomainEvents() {
 	a.domainEvents = nil
 }
@@ -27,3 +37,13 @@ func (a *BaseAggregate) GetDomainEvents() []DomainEvent {
 func (a *BaseAggregate) RaiseDomainEvent(event DomainEvent) {
 	a.domainEvents = append(a.domainEvents, event)
 }
+
+// Version - версия агрегата для оптимистичной блокировки
+func (a *BaseAggregate) Version() int64 {
+	return a.version
+}
+
+// SetVersion фиксирует версию, с которой агрегат сохранён в хранилище. DO NOT USE IN DOMAIN!
+func (a *BaseAggregate) SetVersion(version int64) {
+	a.version = version
+}
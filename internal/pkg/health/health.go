@@ -0,0 +1,188 @@
+package health
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Состояния сервиса и отдельных зависимостей
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// DefaultCheckTimeout - сколько ждать одну проверку, пока kubelet ждёт ответа пробы
+const DefaultCheckTimeout = 2 * time.Second
+
+// Check проверяет одну зависимость, nil - зависимость в порядке
+type Check func(ctx context.Context) error
+
+// Info возвращает сведения о реплике, которые попадают в отчёт, но не влияют на готовность
+type Info func() string
+
+// Report - ответ пробы готовности
+type Report struct {
+	Status string                 `json:"status"`
+	Reason string                 `json:"reason,omitempty"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+	Info   map[string]string      `json:"info,omitempty"`
+}
+
+// CheckResult - состояние одной зависимости. Необязательная зависимость попадает в отчёт,
+// но не делает сервис неготовым
+type CheckResult struct {
+	Status   string `json:"status"`
+	Optional bool   `json:"optional,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Health собирает проверки зависимостей для проб Kubernetes
+type Health struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]*dependency
+	info   map[string]Info
+
+	shuttingDown atomic.Bool
+}
+
+type dependency struct {
+	optional bool
+	checks   []Check
+}
+
+func NewHealth(timeout time.Duration) (*Health, error) {
+	if timeout <= 0 {
+		return nil, errs.NewValueIsOutOfRangeError("timeout", timeout, "1ns", nil)
+	}
+	return &Health{
+		timeout: timeout,
+		checks:  make(map[string]*dependency),
+		info:    make(map[string]Info),
+	}, nil
+}
+
+// Register добавляет обязательную зависимость. Несколько проверок под одним именем
+// (например, два клиента одного сервиса) должны пройти все
+func (h *Health) Register(name string, check Check) {
+	h.register(name, check, false)
+}
+
+// RegisterOptional добавляет зависимость, без которой сервис может работать
+func (h *Health) RegisterOptional(name string, check Check) {
+	h.register(name, check, true)
+}
+
+// RegisterInfo добавляет в отчёт сведения о реплике, например, является ли она лидером
+func (h *Health) RegisterInfo(name string, info Info) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.info[name] = info
+}
+
+func (h *Health) register(name string, check Check, optional bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	dep, ok := h.checks[name]
+	if !ok {
+		dep = &dependency{optional: optional}
+		h.checks[name] = dep
+	}
+	dep.optional = dep.optional && optional
+	dep.checks = append(dep.checks, check)
+}
+
+// ShutDown переводит сервис в неготовые, чтобы балансировщик перестал присылать запросы
+func (h *Health) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Ready проверяет все зависимости параллельно
+func (h *Health) Ready(ctx context.Context) Report {
+	if h.shuttingDown.Load() {
+		return Report{Status: StatusDown, Reason: "shutting down"}
+	}
+
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	deps := make(map[string]dependency, len(h.checks))
+	for name, dep := range h.checks {
+		names = append(names, name)
+		deps[name] = dependency{optional: dep.optional, checks: append([]Check(nil), dep.checks...)}
+	}
+	var info map[string]string
+	if len(h.info) > 0 {
+		info = make(map[string]string, len(h.info))
+		for name, get := range h.info {
+			info[name] = get()
+		}
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, dep dependency) {
+			defer wg.Done()
+			results[i] = runChecks(ctx, dep)
+		}(i, deps[name])
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(names)), Info: info}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status == StatusDown && !results[i].Optional {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func runChecks(ctx context.Context, dep dependency) CheckResult {
+	for _, check := range dep.checks {
+		if err := check(ctx); err != nil {
+			return CheckResult{Status: StatusDown, Optional: dep.optional, Error: err.Error()}
+		}
+	}
+	return CheckResult{Status: StatusUp, Optional: dep.optional}
+}
+
+// LiveHandler отвечает, пока процесс способен обслуживать HTTP. Зависимости не проверяются,
+// иначе Kubernetes перезапускал бы сервис при каждом сбое БД
+func (h *Health) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeReport(w, Report{Status: StatusUp})
+	})
+}
+
+// ReadyHandler отвечает 200, если все обязательные зависимости в порядке, иначе 503
+func (h *Health) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Ready(r.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusUp {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
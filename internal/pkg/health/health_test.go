@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	t.Run("ready when all dependencies are up", func(t *testing.T) {
+		h := newTestHealth(t)
+		h.Register("postgres", up)
+		h.Register("kafka", up)
+
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusUp, report.Status)
+		assert.Equal(t, CheckResult{Status: StatusUp}, report.Checks["postgres"])
+		assert.Equal(t, CheckResult{Status: StatusUp}, report.Checks["kafka"])
+	})
+
+	t.Run("not ready when required dependency is down", func(t *testing.T) {
+		h := newTestHealth(t)
+		h.Register("postgres", up)
+		h.Register("kafka", down)
+
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, CheckResult{Status: StatusDown, Error: "connection refused"}, report.Checks["kafka"])
+	})
+
+	t.Run("optional dependency is reported but does not fail readiness", func(t *testing.T) {
+		h := newTestHealth(t)
+		h.Register("postgres", up)
+		h.RegisterOptional("geo", down)
+
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusUp, report.Status)
+		assert.Equal(t, CheckResult{Status: StatusDown, Optional: true, Error: "connection refused"}, report.Checks["geo"])
+	})
+
+	t.Run("all checks with the same name must pass", func(t *testing.T) {
+		h := newTestHealth(t)
+		h.Register("geo", up)
+		h.Register("geo", down)
+
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Len(t, report.Checks, 1)
+	})
+
+	t.Run("slow check is cut by timeout", func(t *testing.T) {
+		h, err := NewHealth(20 * time.Millisecond)
+		assert.NoError(t, err)
+		h.Register("geo", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["geo"].Error)
+	})
+
+	t.Run("info is reported but does not affect readiness", func(t *testing.T) {
+		h := newTestHealth(t)
+		h.Register("postgres", up)
+		h.RegisterInfo("role", func() string { return "follower" })
+
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusUp, report.Status)
+		assert.Equal(t, map[string]string{"role": "follower"}, report.Info)
+	})
+
+	t.Run("not ready during shutdown", func(t *testing.T) {
+		h := newTestHealth(t)
+		h.Register("postgres", up)
+
+		h.ShutDown()
+		report := h.Ready(context.Background())
+
+		assert.Equal(t, StatusDown, report.Status)
+		assert.NotEmpty(t, report.Reason)
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		_, err := NewHealth(0)
+		assert.Error(t, err)
+	})
+}
+
+func TestHealth_Handlers(t *testing.T) {
+	h := newTestHealth(t)
+	h.Register("postgres", func(context.Context) error { return errors.New("connection refused") })
+
+	ready := httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(ready, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	live := httptest.NewRecorder()
+	h.LiveHandler().ServeHTTP(live, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, ready.Code)
+	var report Report
+	assert.NoError(t, json.Unmarshal(ready.Body.Bytes(), &report))
+	assert.Equal(t, StatusDown, report.Checks["postgres"].Status)
+	// Проба живости не зависит от БД
+	assert.Equal(t, http.StatusOK, live.Code)
+}
+
+func newTestHealth(t *testing.T) *Health {
+	h, err := NewHealth(time.Second)
+	assert.NoError(t, err)
+	return h
+}
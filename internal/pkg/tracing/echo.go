@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware открывает серверный спан на каждый HTTP запрос и продолжает трассу вызывающего,
+// если он передал заголовок traceparent. skipper необязателен
+func Middleware(skipper middleware.Skipper) echo.MiddlewareFunc {
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper(c) {
+				return next(c)
+			}
+
+			request := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+			route := c.Path()
+			if route == "" {
+				route = request.URL.Path
+			}
+			ctx, span := Tracer().Start(ctx, request.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(request.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(request.URL.Path),
+				))
+			defer span.End()
+			c.SetRequest(request.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				// Ошибку превращает в ответ обработчик echo, статус узнаём так же, как он
+				c.Error(err)
+				span.RecordError(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "delivery:tracing_span"
+
+var _ gorm.Plugin = &gormPlugin{}
+
+// gormPlugin открывает клиентский спан на каждый запрос GORM. В спан попадает текст запроса
+// с плейсхолдерами, значения параметров не записываются
+type gormPlugin struct{}
+
+func NewGormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "delivery:tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", startGormSpan("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", endGormSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", startGormSpan("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", endGormSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", startGormSpan("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", endGormSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startGormSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endGormSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", startGormSpan("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", endGormSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startGormSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endGormSpan),
+	)
+}
+
+func startGormSpan(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := Tracer().Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+			))
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endGormSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartConsumerSpan открывает спан обработки сообщения. Если отправитель передал контекст
+// трассы в заголовках, спан её продолжает
+func StartConsumerSpan(ctx context.Context, message *sarama.ConsumerMessage) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, &ConsumerHeaders{Message: message})
+	return Tracer().Start(ctx, message.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(message.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(message.Partition))),
+			semconv.MessagingKafkaMessageOffset(int(message.Offset)),
+		))
+}
+
+// StartProducerSpan открывает спан отправки сообщения и кладёт контекст трассы в его заголовки
+func StartProducerSpan(ctx context.Context, message *sarama.ProducerMessage) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, message.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(message.Topic),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, &ProducerHeaders{Message: message})
+	return ctx, span
+}
+
+var _ propagation.TextMapCarrier = &ConsumerHeaders{}
+var _ propagation.TextMapCarrier = &ProducerHeaders{}
+
+// ConsumerHeaders - заголовки прочитанного сообщения Kafka как носитель контекста трассы
+type ConsumerHeaders struct {
+	Message *sarama.ConsumerMessage
+}
+
+func (h *ConsumerHeaders) Get(key string) string {
+	for _, header := range h.Message.Headers {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h *ConsumerHeaders) Set(key string, value string) {
+	for _, header := range h.Message.Headers {
+		if header != nil && string(header.Key) == key {
+			header.Value = []byte(value)
+			return
+		}
+	}
+	h.Message.Headers = append(h.Message.Headers, &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (h *ConsumerHeaders) Keys() []string {
+	keys := make([]string, 0, len(h.Message.Headers))
+	for _, header := range h.Message.Headers {
+		if header != nil {
+			keys = append(keys, string(header.Key))
+		}
+	}
+	return keys
+}
+
+// ProducerHeaders - заголовки отправляемого сообщения Kafka как носитель контекста трассы
+type ProducerHeaders struct {
+	Message *sarama.ProducerMessage
+}
+
+func (h *ProducerHeaders) Get(key string) string {
+	for _, header := range h.Message.Headers {
+		if string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h *ProducerHeaders) Set(key string, value string) {
+	for i := range h.Message.Headers {
+		if string(h.Message.Headers[i].Key) == key {
+			h.Message.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	h.Message.Headers = append(h.Message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (h *ProducerHeaders) Keys() []string {
+	keys := make([]string, 0, len(h.Message.Headers))
+	for _, header := range h.Message.Headers {
+		keys = append(keys, string(header.Key))
+	}
+	return keys
+}
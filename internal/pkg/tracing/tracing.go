@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"delivery/internal/pkg/errs"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName - имя сервиса в трассах
+const ServiceName = "delivery"
+
+const otlpTracesPath = "/v1/traces"
+
+// OrderIDKey - атрибут спана с идентификатором заказа: по нему заказ находится во всех трассах
+const OrderIDKey = attribute.Key("order.id")
+
+// OrderID - атрибут спана для заказа
+func OrderID(id uuid.UUID) attribute.KeyValue {
+	return OrderIDKey.String(id.String())
+}
+
+// Tracer - спаны сервиса создаются через глобальный провайдер. Пока трассировка не включена,
+// спаны не записываются, но контекст входящих запросов и сообщений всё равно передаётся дальше
+func Tracer() trace.Tracer {
+	return otel.Tracer(ServiceName)
+}
+
+// NewOtlpExporter отправляет спаны по OTLP/HTTP. endpoint - адрес коллектора, например http://localhost:4318,
+// путь /v1/traces добавляется, как того требует спецификация OTEL_EXPORTER_OTLP_ENDPOINT
+func NewOtlpExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	if endpoint == "" {
+		return nil, errs.NewValueIsRequiredError("endpoint")
+	}
+	if !strings.HasSuffix(endpoint, otlpTracesPath) {
+		endpoint = strings.TrimSuffix(endpoint, "/") + otlpTracesPath
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+	}
+	return exporter, nil
+}
+
+// NewTracerProvider отправляет спаны в exporter пачками. Перед выходом провайдер нужно
+// остановить через Shutdown, иначе последние спаны потеряются
+func NewTracerProvider(exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	if exporter == nil {
+		return nil, errs.NewValueIsRequiredError("exporter")
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	), nil
+}
+
+// Use делает provider глобальным и включает передачу контекста трассы
+func Use(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	UsePropagator()
+}
+
+// UsePropagator включает передачу контекста в формате W3C Trace Context без записи спанов
+func UsePropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Inject сохраняет контекст трассы из ctx, например, чтобы положить его рядом с событием в outbox
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// Extract восстанавливает контекст трассы, сохранённый через Inject
+func Extract(ctx context.Context, values map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(values))
+}
+
+// EndSpan завершает спан и отмечает его ошибкой, если err не nil
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,117 @@
+package tracing_test
+
+import (
+	"context"
+	"delivery/internal/pkg/tracing"
+	"delivery/internal/pkg/tracing/tracingtest"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestInjectExtract(t *testing.T) {
+	exporter := tracingtest.Use(t)
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "origin")
+	carrier := tracing.Inject(ctx)
+	span.End()
+
+	_, child := tracing.Tracer().Start(tracing.Extract(context.Background(), carrier), "continued")
+	child.End()
+
+	origin, _ := tracingtest.SpanByName(exporter, "origin")
+	continued, _ := tracingtest.SpanByName(exporter, "continued")
+	assert.Contains(t, carrier, "traceparent")
+	assert.Equal(t, origin.SpanContext.TraceID(), continued.SpanContext.TraceID())
+	assert.Equal(t, origin.SpanContext.SpanID(), continued.Parent.SpanID())
+}
+
+func TestKafkaSpans(t *testing.T) {
+	exporter := tracingtest.Use(t)
+	orderID := uuid.New()
+
+	ctx, origin := tracing.Tracer().Start(context.Background(), "origin")
+	produced := &sarama.ProducerMessage{
+		Topic:   "order.status.changed",
+		Headers: []sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("stale")}},
+	}
+	_, producerSpan := tracing.StartProducerSpan(ctx, produced)
+	producerSpan.SetAttributes(tracing.OrderID(orderID))
+	producerSpan.End()
+	origin.End()
+
+	// Консьюмер получает те же заголовки, что были отправлены
+	consumed := &sarama.ConsumerMessage{Topic: produced.Topic, Partition: 2, Offset: 7}
+	for i := range produced.Headers {
+		consumed.Headers = append(consumed.Headers, &produced.Headers[i])
+	}
+	_, consumerSpan := tracing.StartConsumerSpan(context.Background(), consumed)
+	consumerSpan.End()
+
+	publish, ok := tracingtest.SpanByName(exporter, "order.status.changed publish")
+	assert.True(t, ok)
+	process, ok := tracingtest.SpanByName(exporter, "order.status.changed process")
+	assert.True(t, ok)
+	assert.Len(t, produced.Headers, 1, "traceparent must be replaced, not duplicated")
+	assert.Equal(t, trace.SpanKindProducer, publish.SpanKind)
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind)
+	assert.Equal(t, publish.SpanContext.TraceID(), process.SpanContext.TraceID())
+	assert.Equal(t, publish.SpanContext.SpanID(), process.Parent.SpanID())
+	assert.Equal(t, orderID.String(), tracingtest.Attribute(publish, string(tracing.OrderIDKey)))
+	assert.Equal(t, "2", tracingtest.Attribute(process, "messaging.destination.partition.id"))
+}
+
+func TestMiddleware(t *testing.T) {
+	serve := func(t *testing.T, handler echo.HandlerFunc, header http.Header) {
+		e := echo.New()
+		e.Use(tracing.Middleware(nil))
+		e.GET("/api/v1/orders/:orderId", handler)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/orders/42", nil)
+		for key, values := range header {
+			request.Header[key] = values
+		}
+		e.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	t.Run("continue caller trace", func(t *testing.T) {
+		exporter := tracingtest.Use(t)
+		orderID := uuid.New()
+
+		serve(t, func(c echo.Context) error {
+			trace.SpanFromContext(c.Request().Context()).SetAttributes(tracing.OrderID(orderID))
+			return c.NoContent(http.StatusOK)
+		}, http.Header{"Traceparent": {testTraceParent}})
+
+		span, ok := tracingtest.SpanByName(exporter, "GET /api/v1/orders/:orderId")
+		assert.True(t, ok)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		assert.Equal(t, "200", tracingtest.Attribute(span, "http.response.status_code"))
+		assert.Equal(t, orderID.String(), tracingtest.Attribute(span, string(tracing.OrderIDKey)))
+	})
+
+	t.Run("mark server errors", func(t *testing.T) {
+		exporter := tracingtest.Use(t)
+
+		serve(t, func(echo.Context) error {
+			return errors.New("db is down")
+		}, nil)
+
+		span, ok := tracingtest.SpanByName(exporter, "GET /api/v1/orders/:orderId")
+		assert.True(t, ok)
+		assert.Equal(t, "500", tracingtest.Attribute(span, "http.response.status_code"))
+		assert.Equal(t, codes.Error, span.Status.Code)
+	})
+}
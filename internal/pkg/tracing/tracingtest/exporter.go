@@ -0,0 +1,52 @@
+package tracingtest
+
+import (
+	"context"
+	"delivery/internal/pkg/tracing"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Use включает трассировку в памяти на время теста. Спаны попадают в экспортер сразу после End.
+// Провайдер глобальный, поэтому такие тесты нельзя запускать параллельно
+func Use(t testing.TB) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.Use(provider)
+
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+// SpanByName возвращает последний завершённый спан с именем name
+func SpanByName(exporter *tracetest.InMemoryExporter, name string) (tracetest.SpanStub, bool) {
+	spans := exporter.GetSpans()
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].Name == name {
+			return spans[i], true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+// Attribute возвращает значение атрибута спана строкой
+func Attribute(span tracetest.SpanStub, key string) string {
+	for _, attr := range span.Attributes {
+		if string(attr.Key) == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}